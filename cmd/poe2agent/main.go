@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"poe2-chaos-crafter/internal/backend"
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/server"
+)
+
+// poe2agent lends this machine's screen, mouse and keyboard to a poe2crafter web server
+// elsewhere, which drives it through a remote backend
+func main() {
+	fs := flag.NewFlagSet("poe2agent", flag.ExitOnError)
+	addr := fs.String("addr", ":7777", "address to listen on")
+	certFile := fs.String("cert", "", "PEM certificate to serve with (default: a self-signed one kept next to the config)")
+	keyFile := fs.String("key", "", "PEM private key of -cert")
+	fake := fs.Bool("fake", false, "serve a blank 1920x1080 fake display instead of this desktop")
+	fs.Parse(os.Args[1:])
+
+	if (*certFile == "") != (*keyFile == "") {
+		fmt.Println("❌ ERROR: -cert and -key must be given together")
+		os.Exit(1)
+	}
+
+	// The token is never a flag, so it does not show up in the process list
+	token, created, err := config.LoadAgentToken()
+	if err != nil {
+		fmt.Printf("❌ ERROR: Could not load the token: %v\n", err)
+		os.Exit(1)
+	}
+
+	var b backend.Backend
+	switch {
+	case *fake:
+		b = backend.NewFake(1920, 1080)
+	case !backend.HasLocal:
+		fmt.Println("❌ ERROR: This build has no local screen (built with -tags nolocal); run it with -fake")
+		os.Exit(1)
+	default:
+		b = backend.NewLocal()
+	}
+
+	// A fixed host list keeps the self-signed certificate, and with it the fingerprint
+	// servers pin, the same across restarts
+	hosts := []string{"localhost", "127.0.0.1"}
+	if host, _, err := net.SplitHostPort(*addr); err == nil && host != "" && host != "localhost" && host != "127.0.0.1" {
+		hosts = append(hosts, host)
+	}
+	certPath, keyPath := config.GetAgentCertPaths()
+	cert, err := server.LoadCertificate(*certFile, *keyFile, certPath, keyPath, hosts)
+	if err != nil {
+		fmt.Printf("❌ ERROR: Could not load the certificate: %v\n", err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(backend.AgentPath, backend.NewAgent(b, token))
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 15 * time.Second,
+		TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+	}
+
+	fmt.Println("╔═══════════════════════════════════════════════╗")
+	fmt.Println("║      POE2 Chaos Crafter - Remote Agent       ║")
+	fmt.Println("╚═══════════════════════════════════════════════╝")
+	fmt.Printf("\n🖥  Serving this machine's screen and input on wss://%s%s\n", *addr, backend.AgentPath)
+	fmt.Printf("🔐 Cert: SHA-256 %s\n", server.CertFingerprint(cert))
+	switch {
+	case os.Getenv(config.AgentTokenEnv) != "":
+		fmt.Printf("🔑 Token: from $%s\n", config.AgentTokenEnv)
+	case created:
+		fmt.Printf("🔑 Token: %s (new, saved in %s)\n", token, config.GetAgentTokenPath())
+	default:
+		fmt.Printf("🔑 Token: saved in %s\n", config.GetAgentTokenPath())
+	}
+	fmt.Println("   Add an engine with \"Backend\": \"remote\", this token and the cert fingerprint to the server's engines file.")
+	fmt.Println()
+
+	if err := srv.ListenAndServeTLS("", ""); err != nil {
+		fmt.Printf("❌ Agent error: %v\n", err)
+		os.Exit(1)
+	}
+}
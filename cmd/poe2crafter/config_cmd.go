@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// runConfig reads, changes or checks the saved config or a profile
+func runConfig(args []string) int {
+	fs := newFlagSet("config", "[flags] get [key] | set <key> <value> | validate",
+		"get prints the whole config, or one value. set changes one value; the value is JSON,\n"+
+			"or a plain string. validate checks the config and exits with status 1 if it is\n"+
+			"invalid. Keys are field names as in the config file, with dots for nested fields\n"+
+			"and list items, e.g. ChaosPerRound, Preprocess.Scale or TargetMods.0.MinValue.")
+	profile := fs.String("profile", "", "work on this saved profile instead of the saved config")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *profile != "" {
+		if err := config.ValidateProfileName(*profile); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitUsage
+		}
+	}
+
+	rest := fs.Args()
+	switch {
+	case len(rest) == 1 && rest[0] == "validate":
+		return configValidate(*profile)
+	case len(rest) >= 1 && len(rest) <= 2 && rest[0] == "get":
+		return configGet(*profile, rest[1:])
+	case len(rest) == 3 && rest[0] == "set":
+		return configSet(*profile, rest[1], rest[2])
+	}
+	fs.Usage()
+	return exitUsage
+}
+
+// configPath returns the file the config or profile is saved in
+func configPath(profile string) string {
+	if profile == "" {
+		return config.GetConfigPath()
+	}
+	return filepath.Join(config.GetProfilesDir(), profile+".json")
+}
+
+func configGet(profile string, key []string) int {
+	data, err := os.ReadFile(configPath(profile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if len(key) == 0 {
+		os.Stdout.Write(data)
+		fmt.Println()
+		return exitOK
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", configPath(profile), err)
+		return exitError
+	}
+	value, err := lookupKey(root, strings.Split(key[0], "."))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if s, ok := value.(string); ok {
+		fmt.Println(s)
+		return exitOK
+	}
+	out, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(out))
+	return exitOK
+}
+
+func configSet(profile, key, raw string) int {
+	path := configPath(profile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = []byte("{}"), nil // Start a new config
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+		return exitError
+	}
+
+	var value interface{}
+	if json.Unmarshal([]byte(raw), &value) != nil {
+		value = raw // Not JSON: a plain string
+	}
+	if root, err = assignKey(root, strings.Split(key, "."), value); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	// Decode strictly so that misspelt keys and wrong types are caught before saving
+	data, _ = json.Marshal(root)
+	var cfg config.Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Cannot set %s: %v\n", key, err)
+		return exitError
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	if profile != "" {
+		err = config.SaveProfile(profile, cfg)
+	} else {
+		err = config.SaveConfig(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	fmt.Printf("✓ %s updated\n", key)
+	return exitOK
+}
+
+func configValidate(profile string) int {
+	cfg, err := config.LoadCraftConfig(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load the config: %v\n", err)
+		return exitError
+	}
+	if err := checkReady(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	fmt.Println("✓ Config is valid")
+	return exitOK
+}
+
+// lookupKey follows a dotted key through decoded JSON. Object keys match case-insensitively,
+// like encoding/json does when decoding into Config.
+func lookupKey(node interface{}, path []string) (interface{}, error) {
+	for i, part := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			name, ok := findKey(n, part)
+			if !ok {
+				return nil, fmt.Errorf("no key %s", strings.Join(path[:i+1], "."))
+			}
+			node = n[name]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(n) {
+				return nil, fmt.Errorf("no item %s (the list has %d)", strings.Join(path[:i+1], "."), len(n))
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("%s is not an object or list", strings.Join(path[:i], "."))
+		}
+	}
+	return node, nil
+}
+
+// assignKey sets a dotted key in decoded JSON, creating missing objects on the way, and
+// returns the updated node. Lists can be changed item by item or replaced as a whole.
+func assignKey(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	switch n := node.(type) {
+	case nil:
+		child, err := assignKey(nil, path[1:], value)
+		return map[string]interface{}{path[0]: child}, err
+	case map[string]interface{}:
+		name, ok := findKey(n, path[0])
+		if !ok {
+			name = path[0]
+		}
+		child, err := assignKey(n[name], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[name] = child
+		return n, nil
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(n) {
+			return nil, fmt.Errorf("no item %s (the list has %d)", path[0], len(n))
+		}
+		if n[index], err = assignKey(n[index], path[1:], value); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("cannot set %s inside a plain value", strings.Join(path, "."))
+}
+
+// findKey returns the key of m that matches name, preferring an exact match
+func findKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
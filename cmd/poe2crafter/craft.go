@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"os/signal"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+)
+
+// runCraft crafts without prompts, for scripts and scheduled tasks.
+// Returns 0 when the batch finished, 1 on errors and 130 when stopped with Ctrl+C.
+func runCraft(args []string) int {
+	fs := newFlagSet("craft", "[flags]",
+		"Crafts with the saved config, or a profile, without asking anything. Switch to the\n"+
+			"game during the countdown. The hotkeys work as in the web GUI.")
+	profile := fs.String("profile", "", "saved profile to craft with (default: the saved config)")
+	items := fs.Int("items", 0, "stop after this many items (default: the config's MaxItems)")
+	budget := fs.Int("budget", 0, "stop after this many counted rolls (default: the config's OrbBudget)")
+	dryRun := fs.Bool("dry-run", false, "check the config and print the plan without touching the game")
+	resume := fs.Bool("resume", false, "continue the interrupted session instead of starting a new one")
+	countdown := fs.Int("countdown", 5, "seconds to wait before the first click")
+	debug := fs.Bool("debug", false, "save debug snapshots")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "❌ Unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	cfg, err := config.LoadCraftConfig(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load the config: %v\n", err)
+		return exitError
+	}
+	if *items > 0 {
+		cfg.MaxItems = *items
+	}
+	if *budget > 0 {
+		cfg.OrbBudget = *budget
+	}
+	if err := checkReady(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	eng := engine.NewEngine(*debug)
+	var cp *engine.Checkpoint
+	if *resume {
+		if cp, err = eng.LoadCheckpoint(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Could not load the interrupted session: %v\n", err)
+			return exitError
+		}
+		if cp == nil {
+			fmt.Fprintln(os.Stderr, "❌ No interrupted session to resume")
+			return exitError
+		}
+	}
+
+	printPlan(cfg, *profile, cp)
+	if *dryRun {
+		fmt.Println("\n✓ Dry run: the config is valid, nothing was crafted")
+		return exitOK
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("\nStarting in %d seconds... Switch to POE2 now!\n", *countdown)
+	if engine.Sleep(ctx, time.Duration(*countdown)*time.Second) != nil {
+		return exitStopped
+	}
+	if cp != nil {
+		eng.Resume(ctx, cfg, cp)
+	} else {
+		eng.Craft(ctx, cfg)
+	}
+
+	if ctx.Err() != nil {
+		return exitStopped
+	}
+	return craftExitCode(eng)
+}
+
+// runWizard runs the console setup wizard and saves the result as the config
+func runWizard(args []string) int {
+	fs := newFlagSet("wizard", "",
+		"Captures the screen positions and asks for the target mods in this console, then\n"+
+			"saves the config. A saved config can be kept or changed section by section.")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	engine.NewEngine(false).SetupWizard()
+	return exitOK
+}
+
+// craftExitCode reports a session that ended in the error state
+func craftExitCode(eng *engine.Engine) int {
+	if eng.State() == engine.StateError {
+		fmt.Fprintf(os.Stderr, "❌ Crafting failed: %s\n", eng.LastError())
+		return exitError
+	}
+	return exitOK
+}
+
+// checkReady checks that cfg was set up far enough to craft with
+func checkReady(cfg config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.ChaosPos == (image.Point{}) {
+		return fmt.Errorf("the chaos orb position is not set (run \"poe2crafter wizard\")")
+	}
+	if cfg.TooltipSize.X <= 0 || cfg.TooltipSize.Y <= 0 {
+		return fmt.Errorf("the tooltip area is not set (run \"poe2crafter wizard\")")
+	}
+	if len(cfg.TargetMods) == 0 && cfg.ScoreFormula == "" {
+		return fmt.Errorf("no target mods or score formula (run \"poe2crafter wizard\")")
+	}
+	return nil
+}
+
+// printPlan describes what a craft run will do
+func printPlan(cfg config.Config, profile string, cp *engine.Checkpoint) {
+	if profile == "" {
+		profile = "(saved config)"
+	}
+	fmt.Printf("📋 Profile:   %s\n", profile)
+	if cp != nil {
+		fmt.Printf("💾 Resuming:  %s\n", cp.Summary())
+	}
+	for i, mod := range cfg.TargetMods {
+		fmt.Printf("🎯 Target %d:  %s\n", i+1, mod.Description)
+	}
+	if cfg.ScoreFormula != "" {
+		fmt.Printf("🎯 Score:     %s (success at %v)\n", cfg.ScoreFormula, cfg.ScoreThreshold)
+	}
+	for _, cond := range cfg.AffixConditions {
+		fmt.Printf("   Condition: %s\n", cond)
+	}
+
+	items := fmt.Sprintf("whole pending area (%dx%d cells)", cfg.PendingAreaWidth, cfg.PendingAreaHeight)
+	if cfg.MaxItems > 0 {
+		items = fmt.Sprint(cfg.MaxItems)
+	}
+	budget := "unlimited"
+	if cfg.OrbBudget > 0 {
+		budget = fmt.Sprintf("%d rolls", cfg.OrbBudget)
+	}
+	fmt.Printf("📦 Items:     %s, %d chaos per item\n", items, cfg.ChaosPerRound)
+	fmt.Printf("💰 Budget:    %s\n", budget)
+}
@@ -1,43 +1,152 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"poe2-chaos-crafter/internal/engine"
-	"poe2-chaos-crafter/internal/server"
 )
 
+// Exit codes of the subcommands. ocr-eval keeps its own: 1 for errors, 2 for low recall.
+const (
+	exitOK      = 0   // Success
+	exitError   = 1   // The command failed, e.g. an invalid config or an unreadable file
+	exitUsage   = 2   // Unknown command or invalid flags
+	exitNoMatch = 3   // ocr -targets: no target mod on the image
+	exitStopped = 130 // Interrupted with Ctrl+C
+)
+
+// command is one subcommand; run gets the arguments after its name and returns the exit code
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"craft", "Craft in this console with the saved config or a profile", runCraft},
+		{"serve", "Run the web GUI", runServe},
+		{"wizard", "Set up or change the saved config in this console", runWizard},
+		{"report", "List, print or export the reports of past sessions", runReport},
+		{"ocr", "Read the item and mods on a tooltip image", runOCR},
+		{"ocr-eval", "Measure OCR accuracy on the regression corpus", runOCREval},
+		{"config", "Get, set or validate values of the saved config or a profile", runConfig},
+		{"help", "Show help for a command", runHelp},
+	}
+}
+
 func main() {
-	// Parse command-line flags
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		printUsage()
+		os.Exit(exitOK)
+	}
+	// No command, or only the flags of older versions: set up and craft interactively
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		os.Exit(runLegacy(args))
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			os.Exit(cmd.run(args[1:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n", args[0])
+	printUsage()
+	os.Exit(exitUsage)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: poe2crafter <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nWithout a command, runs the setup wizard and then crafts, asking before each step.")
+	fmt.Fprintln(os.Stderr, "Run \"poe2crafter help <command>\" for the flags of a command.")
+}
+
+// runHelp prints the usage of a command, or the list of commands
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage()
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] && cmd.name != "help" {
+			return cmd.run([]string{"-h"})
+		}
+	}
+	fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n", args[0])
+	return exitUsage
+}
+
+// newFlagSet returns the flag set of a subcommand, whose usage starts with the synopsis
+// and description
+func newFlagSet(name, synopsis, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: poe2crafter %s %s\n\n%s\n", name, synopsis, description)
+		var hasFlags bool
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(fs.Output(), "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseFlags parses a subcommand's flags. ok is false when the command has to exit with
+// code right away: after -h (exitOK) or invalid flags (exitUsage).
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK, false
+	}
+	if err != nil {
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// runLegacy keeps the flags of older versions working: --web [port] runs the web GUI,
+// anything else the interactive wizard followed by crafting
+func runLegacy(args []string) int {
 	webMode := false
-	webPort := 8080
+	var serveArgs []string
 	debugMode := false
-	for i, arg := range os.Args[1:] {
-		if arg == "--web" {
+	for i, arg := range args {
+		switch arg {
+		case "--web":
 			webMode = true
 			// Check if next arg is a port number
-			if i+2 < len(os.Args) {
-				if port, err := strconv.Atoi(os.Args[i+2]); err == nil && port > 0 && port < 65536 {
-					webPort = port
+			if i+1 < len(args) {
+				if port, err := strconv.Atoi(args[i+1]); err == nil {
+					serveArgs = append(serveArgs, "--port", strconv.Itoa(port))
 				}
 			}
-		}
-		if arg == "--debug" {
+		case "--debug":
 			debugMode = true
+			serveArgs = append(serveArgs, "--debug")
 		}
 	}
-
-	eng := engine.NewEngine(debugMode)
-
 	if webMode {
-		server.StartWebServer(webPort, eng)
-		return
+		return runServe(serveArgs)
 	}
 
-	// CLI mode
+	eng := engine.NewEngine(debugMode)
+
 	fmt.Println("╔═══════════════════════════════════════════════╗")
 	fmt.Println("║      POE2 Chaos Crafter - Multi Mod          ║")
 	fmt.Println("╚═══════════════════════════════════════════════╝")
@@ -50,9 +159,32 @@ func main() {
 	for i, mod := range cfg.TargetMods {
 		fmt.Printf("   %d. %s\n", i+1, mod.Description)
 	}
+	if cfg.ScoreFormula != "" {
+		fmt.Printf("\n✓ Scoring each roll with: %s (success at %v)\n", cfg.ScoreFormula, cfg.ScoreThreshold)
+	}
+	// Offer to continue a batch that was interrupted
+	cp, err := eng.LoadCheckpoint()
+	if err != nil {
+		fmt.Printf("⚠ WARNING: Ignoring checkpoint: %v\n", err)
+	}
+	if cp != nil {
+		fmt.Printf("\n💾 Interrupted session found: %s\n", cp.Summary())
+		fmt.Print("Resume it? (y/n): ")
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		if strings.ToLower(strings.TrimSpace(scanner.Text())) != "y" {
+			cp = nil
+		}
+	}
+
 	fmt.Println("\nStarting in 5 seconds... Switch to POE2 now!")
 	time.Sleep(5 * time.Second)
 
 	// Run the crafter
-	eng.Craft(cfg)
+	if cp != nil {
+		eng.Resume(context.Background(), cfg, cp)
+	} else {
+		eng.Craft(context.Background(), cfg)
+	}
+	return craftExitCode(eng)
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// runOCR reads a tooltip screenshot the way a roll is read while crafting
+func runOCR(args []string) int {
+	fs := newFlagSet("ocr", "[flags] <image>",
+		"Runs OCR on a tooltip screenshot (PNG or JPEG) with the saved preprocessing settings\n"+
+			"and prints the text, the item header and the recognized mods.")
+	lang := fs.String("lang", "", "game language of the tooltip (default: the config's GameLanguage)")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	targets := fs.Bool("targets", false, "check the config's target mods; exit with status 3 if none matches")
+	debug := fs.Bool("debug", false, "save the preprocessing stages to the snapshots folder")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not read %s: %v\n", fs.Arg(0), err)
+		return exitError
+	}
+
+	cfg, _ := config.LoadConfig()
+	if *lang == "" {
+		*lang = cfg.GameLanguage
+	}
+	if *lang == "" {
+		*lang = "en"
+	}
+
+	eng := engine.NewEngine(*debug)
+	if *debug {
+		os.MkdirAll(eng.SnapshotsDir, 0755)
+	}
+	tempDir := filepath.Join(os.TempDir(), "poe2_crafter_ocr")
+	os.MkdirAll(tempDir, 0755)
+
+	text, err := eng.RunTesseractOCR(context.Background(), img, tempDir, *lang, cfg.Preprocess)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ OCR failed: %v\n", err)
+		return exitError
+	}
+	header := engine.ParseItemHeader(text, *lang)
+	item := engine.ParseItem(text, *lang)
+	hit, target, value := engine.CheckAnyMod(text, cfg.TargetMods)
+
+	if *asJSON {
+		result := map[string]interface{}{"text": text, "header": header, "mods": item.Mods}
+		if *targets {
+			result["targetHit"] = hit
+			if hit {
+				result["target"] = target.Description
+				result["value"] = value
+			}
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("── Text ──\n%s\n\n", text)
+		fmt.Printf("Item: %s\n", header)
+		fmt.Printf("Mods (%d):\n", len(item.Mods))
+		for _, mod := range item.Mods {
+			fmt.Printf("  %-8s %-24s %s\n", mod.Group, mod.Name, modcatalog.FormatValue(mod.Value))
+		}
+		if *targets && hit {
+			fmt.Printf("\n✓ Target hit: %s (value %s)\n", target.Description, modcatalog.FormatValue(value))
+		} else if *targets {
+			fmt.Println("\n✗ No target mod matches")
+		}
+	}
+
+	if *targets && !hit {
+		return exitNoMatch
+	}
+	return exitOK
+}
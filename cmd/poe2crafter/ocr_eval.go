@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/ocreval"
+)
+
+// runOCREval runs the OCR regression corpus and prints per-mod precision/recall.
+// Returns 0 on success, 1 on usage/load errors and 2 when recall is below -min-recall.
+func runOCREval(args []string) int {
+	fs := flag.NewFlagSet("ocr-eval", flag.ContinueOnError)
+	dir := fs.String("dir", config.CorpusDir, "corpus root (contains <lang>/<name>.png + .json)")
+	langs := fs.String("lang", "", "comma-separated languages to evaluate (default: all)")
+	minRecall := fs.Float64("min-recall", 0, "exit with status 2 if total recall is below this fraction")
+	debug := fs.Bool("debug", false, "save preprocessing snapshots for every sample")
+	textOnly := fs.Bool("text", false, "parse the samples' texts instead of running OCR")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: poe2crafter ocr-eval [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	var langList []string
+	if *langs != "" {
+		langList = strings.Split(*langs, ",")
+	}
+
+	samples, err := ocreval.LoadCorpus(*dir, langList...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if len(samples) == 0 {
+		fmt.Fprintf(os.Stderr, "❌ No samples found in %s\n", *dir)
+		return 1
+	}
+
+	var ocr ocreval.OCRFunc
+	if !*textOnly {
+		// Use the saved preprocessing profile so results match live crafting
+		var pre config.PreprocessConfig
+		if cfg, err := config.LoadConfig(); err == nil {
+			pre = cfg.Preprocess
+		}
+
+		eng := engine.NewEngine(*debug)
+		if *debug {
+			os.MkdirAll(config.SnapshotsDir, 0755)
+		}
+		tempDir := filepath.Join(os.TempDir(), "poe2_crafter_eval")
+		os.MkdirAll(tempDir, 0755)
+
+		ocr = func(img image.Image, lang string) (string, error) {
+			return eng.RunTesseractOCR(context.Background(), img, tempDir, lang, pre)
+		}
+	}
+
+	result := ocreval.Evaluate(samples, ocr)
+	result.Print(os.Stdout)
+
+	if _, recall := result.Totals(); recall < *minRecall {
+		return 2
+	}
+	return 0
+}
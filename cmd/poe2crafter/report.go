@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// runReport lists the saved session reports, or prints or exports one of them
+func runReport(args []string) int {
+	fs := newFlagSet("report", "[flags] [session]",
+		"Without a session, lists the saved reports. With one (its start time as listed, or\n"+
+			"\"latest\"), prints that report as text, JSON or CSV (one row per mod).")
+	dir := fs.String("dir", ".", "folder the reports were saved in")
+	format := fs.String("format", "text", "output format: text, json or csv")
+	out := fs.String("o", "", "write to this file instead of the console")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "❌ Unknown format %q (use text, json or csv)\n", *format)
+		return exitUsage
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "❌ Unexpected argument %q\n", fs.Arg(1))
+		return exitUsage
+	}
+
+	sessions, err := listReports(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		printReportList(*dir, sessions)
+		return exitOK
+	}
+
+	session := fs.Arg(0)
+	if session == "latest" && len(sessions) > 0 {
+		session = sessions[len(sessions)-1]
+	}
+	base := filepath.Join(*dir, engine.ReportPrefix+session)
+
+	var output []byte
+	if *format == "text" {
+		output, err = os.ReadFile(base + ".txt")
+	} else {
+		output, err = exportReport(base+".json", *format)
+	}
+	if os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "❌ No %s report for session %q in %s\n", *format, session, *dir)
+		return exitError
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	if *out == "" {
+		os.Stdout.Write(output)
+		return exitOK
+	}
+	if err := os.WriteFile(*out, output, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	fmt.Printf("📊 Report saved: %s\n", *out)
+	return exitOK
+}
+
+// listReports returns the start times of the sessions with a report in dir, oldest first
+func listReports(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var sessions []string
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), engine.ReportPrefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		session := strings.TrimSuffix(name, filepath.Ext(name))
+		if !seen[session] {
+			seen[session] = true
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Strings(sessions) // ReportTimeFormat sorts by time
+	return sessions, nil
+}
+
+// printReportList prints one line per session, with its summary when JSON was saved for it
+func printReportList(dir string, sessions []string) {
+	if len(sessions) == 0 {
+		fmt.Printf("No reports in %s\n", dir)
+		return
+	}
+	fmt.Printf("%-21s %8s %10s  %s\n", "Session", "Rolls", "Duration", "Result")
+	for _, session := range sessions {
+		report, err := loadReport(filepath.Join(dir, engine.ReportPrefix+session+".json"))
+		if err != nil {
+			fmt.Printf("%-21s %8s %10s  %s\n", session, "-", "-", "(text only)")
+			continue
+		}
+		result := "✗ Not found"
+		if report.TargetModHit {
+			result = fmt.Sprintf("✓ %s = %s", report.TargetModName, modcatalog.FormatValue(report.TargetValue))
+		}
+		fmt.Printf("%-21s %8d %10s  %s\n", session, report.TotalRolls, report.Duration, result)
+	}
+}
+
+func loadReport(path string) (*engine.ReportData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report engine.ReportData
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// exportReport renders the report saved at path as JSON or CSV
+func exportReport(path, format string) ([]byte, error) {
+	report, err := loadReport(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		return append(data, '\n'), err
+	}
+
+	var buf strings.Builder
+	if err := writeReportCSV(&buf, report); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+// writeReportCSV writes one row per mod with its statistics over the session
+func writeReportCSV(w io.Writer, report *engine.ReportData) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"session", "mod", "count", "min", "max", "avg", "probability"})
+	for _, stat := range report.ModStats {
+		cw.Write([]string{
+			report.StartTime,
+			stat.ModName,
+			fmt.Sprint(stat.Count),
+			modcatalog.FormatValue(stat.MinValue),
+			modcatalog.FormatValue(stat.MaxValue),
+			fmt.Sprintf("%.2f", stat.AvgValue),
+			fmt.Sprintf("%.2f", stat.Probability),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
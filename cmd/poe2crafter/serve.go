@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/server"
+)
+
+// runServe runs the web GUI until Ctrl+C. Flags override the server settings file for this run.
+func runServe(args []string) int {
+	fs := newFlagSet("serve", "[flags]",
+		"Runs the web GUI. Unset flags fall back to "+config.GetServerSettingsPath()+".")
+	var opts server.Options
+	fs.IntVar(&opts.Port, "port", 0, "port to listen on (default: settings, else 8080)")
+	fs.StringVar(&opts.Bind, "bind", "", "IP address of this machine to listen on (default: all)")
+	fs.BoolVar(&opts.LocalOnly, "local-only", false, "listen on 127.0.0.1 only; no other machine can connect")
+	fs.StringVar(&opts.Password, "password", "", "password for signing in from other devices")
+	fs.BoolVar(&opts.TLS, "tls", false, "serve HTTPS (self-signed unless -cert is given)")
+	fs.StringVar(&opts.CertFile, "cert", "", "PEM certificate for HTTPS")
+	fs.StringVar(&opts.KeyFile, "key", "", "PEM private key of -cert")
+	debug := fs.Bool("debug", false, "save debug snapshots")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "❌ Unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	if opts.Port < 0 || opts.Port > 65535 {
+		fmt.Fprintf(os.Stderr, "❌ Invalid port %d\n", opts.Port)
+		return exitUsage
+	}
+
+	server.StartWebServer(opts, engine.NewEngine(*debug))
+	return exitOK
+}
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// agentEncoder favours speed, since every roll captures the tooltip through it
+var agentEncoder = png.Encoder{CompressionLevel: png.BestSpeed}
+
+// Agent serves a backend to Remote backends on other machines
+type Agent struct {
+	backend  Backend
+	token    string
+	upgrader websocket.Upgrader
+	mu       sync.Mutex // Input and capture are not safe to use from several clients at once
+}
+
+// NewAgent serves b to clients that authenticate with "Authorization: Bearer <token>"
+func NewAgent(b Backend, token string) *Agent {
+	return &Agent{backend: b, token: token}
+}
+
+// ServeHTTP upgrades an authenticated request to a WebSocket and answers its requests
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		fmt.Printf("[Agent] Rejected %s: bad token\n", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fmt.Printf("[Agent] Upgrade error: %v\n", err)
+		return
+	}
+	defer conn.Close()
+	fmt.Printf("[Agent] %s connected\n", r.RemoteAddr)
+
+	for {
+		var req Request
+		if err := conn.ReadJSON(&req); err != nil {
+			fmt.Printf("[Agent] %s disconnected\n", r.RemoteAddr)
+			return
+		}
+		if err := conn.WriteJSON(a.do(req)); err != nil {
+			fmt.Printf("[Agent] %s write error: %v\n", r.RemoteAddr, err)
+			return
+		}
+	}
+}
+
+// do runs one request on the backend
+func (a *Agent) do(req Request) Response {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	resp := Response{ID: req.ID}
+	var err error
+	switch req.Op {
+	case OpCapture:
+		var img image.Image
+		if img, err = a.backend.Capture(req.X, req.Y, req.W, req.H); err == nil {
+			var buf bytes.Buffer
+			err = agentEncoder.Encode(&buf, img)
+			resp.Image = buf.Bytes()
+		}
+	case OpScreenSize:
+		resp.X, resp.Y, err = a.backend.ScreenSize()
+	case OpMousePos:
+		resp.X, resp.Y, err = a.backend.MousePos()
+	case OpMove:
+		err = a.backend.Move(req.X, req.Y)
+	case OpMoveSmooth:
+		err = a.backend.MoveSmooth(req.X, req.Y, req.Low, req.High)
+	case OpClick:
+		err = a.backend.Click(req.Button)
+	case OpToggle:
+		err = a.backend.Toggle(req.Button, req.Direction)
+	case OpKeyToggle:
+		err = a.backend.KeyToggle(req.Button, req.Direction)
+	default:
+		err = fmt.Errorf("unknown operation %q", req.Op)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
@@ -0,0 +1,53 @@
+// Package backend is the screen capture and input an engine plays the game through, so one
+// server can drive games on this machine and on others.
+package backend
+
+import (
+	"fmt"
+	"image"
+)
+
+// Backend kinds for Target.Kind
+const (
+	KindLocal  = "local"  // This machine's screen, mouse and keyboard (default)
+	KindRemote = "remote" // Another machine running poe2agent, see Remote
+)
+
+// Backend captures the game's screen and sends it mouse and keyboard input.
+// Coordinates are screen pixels of the machine the game runs on.
+type Backend interface {
+	// Capture grabs a region of the screen; w or h of 0 grabs the whole screen
+	Capture(x, y, w, h int) (image.Image, error)
+	ScreenSize() (int, int, error)
+	MousePos() (int, int, error)
+	Move(x, y int) error
+	// MoveSmooth moves like a human would; low and high bound the speed, see robotgo.MoveSmooth
+	MoveSmooth(x, y int, low, high float64) error
+	Click(button string) error             // "left" or "right"
+	Toggle(button, direction string) error // Mouse button "down" or "up"
+	KeyToggle(key, direction string) error // Keyboard key "down" or "up"
+	Close() error
+}
+
+// Target says which backend an engine uses
+type Target struct {
+	Kind    string // KindLocal (default)
+	Address string // Where a remote backend is reached
+	Token   string // Secret a remote backend authenticates with
+	// SHA-256 fingerprint of the remote agent's certificate (empty = verify it as usual)
+	Fingerprint string
+}
+
+// Open connects to the backend of target
+func Open(target Target) (Backend, error) {
+	switch target.Kind {
+	case "", KindLocal:
+		if !HasLocal {
+			return nil, fmt.Errorf("this build has no local screen (built with -tags nolocal)")
+		}
+		return NewLocal(), nil
+	case KindRemote:
+		return NewRemote(target.Address, target.Token, target.Fingerprint)
+	}
+	return nil, fmt.Errorf("unknown backend %q", target.Kind)
+}
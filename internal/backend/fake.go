@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+)
+
+// Fake is a display that only exists in memory. Input moves a virtual mouse and is
+// recorded, so the agent and the remote backend can run end to end without a desktop.
+type Fake struct {
+	mu     sync.Mutex
+	screen *image.RGBA
+	x, y   int
+	events []string
+}
+
+// NewFake returns a w×h fake display filled with the dark grey of an empty inventory
+func NewFake(w, h int) *Fake {
+	screen := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(screen, screen.Bounds(), image.NewUniform(color.RGBA{24, 22, 20, 255}), image.Point{}, draw.Src)
+	return &Fake{screen: screen}
+}
+
+// Paint draws img onto the fake screen at (x, y), e.g. a tooltip for OCR
+func (f *Fake) Paint(img image.Image, x, y int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := img.Bounds().Sub(img.Bounds().Min).Add(image.Point{X: x, Y: y})
+	draw.Draw(f.screen, r, img, img.Bounds().Min, draw.Src)
+}
+
+// Events returns the input received so far, e.g. "move 10,20" or "click left"
+func (f *Fake) Events() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.events...)
+}
+
+func (f *Fake) record(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, fmt.Sprintf(format, args...))
+}
+
+func (f *Fake) Capture(x, y, w, h int) (image.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.screen.Bounds()
+	if w != 0 && h != 0 {
+		r = image.Rect(x, y, x+w, y+h)
+	}
+	if !r.In(f.screen.Bounds()) {
+		return nil, fmt.Errorf("region %v is outside the %v screen", r, f.screen.Bounds().Size())
+	}
+	img := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(img, img.Bounds(), f.screen, r.Min, draw.Src)
+	return img, nil
+}
+
+func (f *Fake) ScreenSize() (int, int, error) {
+	size := f.screen.Bounds().Size()
+	return size.X, size.Y, nil
+}
+
+func (f *Fake) MousePos() (int, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.x, f.y, nil
+}
+
+func (f *Fake) Move(x, y int) error {
+	f.mu.Lock()
+	f.x, f.y = x, y
+	f.mu.Unlock()
+	f.record("move %d,%d", x, y)
+	return nil
+}
+
+func (f *Fake) MoveSmooth(x, y int, low, high float64) error {
+	return f.Move(x, y)
+}
+
+func (f *Fake) Click(button string) error {
+	f.record("click %s", button)
+	return nil
+}
+
+func (f *Fake) Toggle(button, direction string) error {
+	f.record("toggle %s %s", button, direction)
+	return nil
+}
+
+func (f *Fake) KeyToggle(key, direction string) error {
+	f.record("key %s %s", key, direction)
+	return nil
+}
+
+func (f *Fake) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build !nolocal
+
+package backend
+
+import (
+	"image"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// HasLocal reports whether NewLocal drives this machine; see local_none.go for builds without
+const HasLocal = true
+
+// Local is the screen, mouse and keyboard of this machine
+type Local struct{}
+
+// NewLocal returns the backend of this machine
+func NewLocal() Backend {
+	return Local{}
+}
+
+func (Local) Capture(x, y, w, h int) (image.Image, error) {
+	if w == 0 || h == 0 {
+		return robotgo.CaptureImg()
+	}
+	return robotgo.CaptureImg(x, y, w, h)
+}
+
+func (Local) ScreenSize() (int, int, error) {
+	w, h := robotgo.GetScreenSize()
+	return w, h, nil
+}
+
+func (Local) MousePos() (int, int, error) {
+	x, y := robotgo.Location()
+	return x, y, nil
+}
+
+func (Local) Move(x, y int) error {
+	robotgo.Move(x, y)
+	return nil
+}
+
+func (Local) MoveSmooth(x, y int, low, high float64) error {
+	robotgo.MoveSmooth(x, y, low, high)
+	return nil
+}
+
+func (Local) Click(button string) error {
+	robotgo.Click(button, false)
+	return nil
+}
+
+func (Local) Toggle(button, direction string) error {
+	return robotgo.Toggle(button, direction)
+}
+
+func (Local) KeyToggle(key, direction string) error {
+	return robotgo.KeyToggle(key, direction)
+}
+
+func (Local) Close() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+//go:build nolocal
+
+package backend
+
+import (
+	"errors"
+	"image"
+)
+
+// HasLocal is false in builds with the nolocal tag. They leave out robotgo, for tests and
+// for a poe2agent -fake on a machine without a desktop.
+const HasLocal = false
+
+var errNoLocal = errors.New("this build has no local screen (built with -tags nolocal)")
+
+// NewLocal returns a backend whose every call fails, so nothing plays on a blank screen
+func NewLocal() Backend {
+	return noLocal{}
+}
+
+type noLocal struct{}
+
+func (noLocal) Capture(x, y, w, h int) (image.Image, error)  { return nil, errNoLocal }
+func (noLocal) ScreenSize() (int, int, error)                { return 0, 0, errNoLocal }
+func (noLocal) MousePos() (int, int, error)                  { return 0, 0, errNoLocal }
+func (noLocal) Move(x, y int) error                          { return errNoLocal }
+func (noLocal) MoveSmooth(x, y int, low, high float64) error { return errNoLocal }
+func (noLocal) Click(button string) error                    { return errNoLocal }
+func (noLocal) Toggle(button, direction string) error        { return errNoLocal }
+func (noLocal) KeyToggle(key, direction string) error        { return errNoLocal }
+func (noLocal) Close() error                                 { return nil }
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AgentPath is where poe2agent serves the remote backend protocol
+const AgentPath = "/agent"
+
+// Operations of the remote backend protocol, one per Backend method
+const (
+	OpCapture    = "capture"
+	OpScreenSize = "screen_size"
+	OpMousePos   = "mouse_pos"
+	OpMove       = "move"
+	OpMoveSmooth = "move_smooth"
+	OpClick      = "click"
+	OpToggle     = "toggle"
+	OpKeyToggle  = "key_toggle"
+)
+
+// Request is one Backend call sent to an agent as a JSON WebSocket message.
+// Only the fields of the operation are set.
+type Request struct {
+	ID        int     `json:"id"`
+	Op        string  `json:"op"`
+	X         int     `json:"x,omitempty"`
+	Y         int     `json:"y,omitempty"`
+	W         int     `json:"w,omitempty"`
+	H         int     `json:"h,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	High      float64 `json:"high,omitempty"`
+	Button    string  `json:"button,omitempty"` // Mouse button, or the key of OpKeyToggle
+	Direction string  `json:"direction,omitempty"`
+}
+
+// Response answers the Request with the same ID
+type Response struct {
+	ID    int    `json:"id"`
+	Error string `json:"error,omitempty"`
+	X     int    `json:"x,omitempty"`     // Mouse position or screen width
+	Y     int    `json:"y,omitempty"`     // Mouse position or screen height
+	Image []byte `json:"image,omitempty"` // PNG of an OpCapture
+}
+
+// agentURL turns "host:port" into the agent's secure WebSocket URL. Full ws:// and wss://
+// URLs are kept as they are.
+func agentURL(address string) (string, error) {
+	if !strings.Contains(address, "://") {
+		address = "wss://" + address + AgentPath
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid agent address: %w", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return "", fmt.Errorf("invalid agent address %q (use host:port or a ws:// or wss:// URL)", address)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid agent address %q (no host)", address)
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// remoteTimeout bounds one call to the agent, including a full screen capture
+const remoteTimeout = 10 * time.Second
+
+// Remote is the screen and input of another machine, reached through the poe2agent
+// running there. It connects on first use and reconnects after a broken connection.
+type Remote struct {
+	url   string
+	token string
+	tls   *tls.Config // nil = verify the agent's certificate as usual
+
+	mu   sync.Mutex // One call at a time
+	conn *websocket.Conn
+	seq  int
+}
+
+// NewRemote returns the backend of the agent at address ("host:port" or a ws:// URL). With a
+// fingerprint only the agent certificate with that SHA-256 fingerprint is trusted, as for the
+// agent's self-signed one.
+func NewRemote(address, token, fingerprint string) (*Remote, error) {
+	u, err := agentURL(address)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("remote backend %s needs the agent's token", address)
+	}
+	r := &Remote{url: u, token: token}
+	if fingerprint != "" {
+		if r.tls, err = pinnedTLS(fingerprint); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// pinnedTLS trusts exactly the certificate with the given fingerprint, e.g. "3A:F0:..."
+func pinnedTLS(fingerprint string) (*tls.Config, error) {
+	want, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+	if err != nil || len(want) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate fingerprint %q (use the SHA-256 the agent printed)", fingerprint)
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // The fingerprint check below replaces the CA check
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("agent sent no certificate")
+			}
+			if sum := sha256.Sum256(cs.PeerCertificates[0].Raw); !bytes.Equal(sum[:], want) {
+				return fmt.Errorf("agent certificate does not match the fingerprint")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// call sends req and waits for its response
+func (r *Remote) call(req Request) (Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		header := http.Header{"Authorization": {"Bearer " + r.token}}
+		dialer := websocket.Dialer{HandshakeTimeout: remoteTimeout, TLSClientConfig: r.tls}
+		conn, resp, err := dialer.Dial(r.url, header)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				return Response{}, fmt.Errorf("agent %s rejected the token", r.url)
+			}
+			return Response{}, fmt.Errorf("connect to agent %s: %w", r.url, err)
+		}
+		r.conn = conn
+	}
+
+	r.seq++
+	req.ID = r.seq
+	r.conn.SetWriteDeadline(time.Now().Add(remoteTimeout))
+	if err := r.conn.WriteJSON(req); err != nil {
+		r.disconnect()
+		return Response{}, fmt.Errorf("send to agent: %w", err)
+	}
+
+	var resp Response
+	r.conn.SetReadDeadline(time.Now().Add(remoteTimeout))
+	if err := r.conn.ReadJSON(&resp); err != nil {
+		r.disconnect()
+		return Response{}, fmt.Errorf("read from agent: %w", err)
+	}
+	if resp.ID != req.ID {
+		r.disconnect()
+		return Response{}, fmt.Errorf("agent answered request %d instead of %d", resp.ID, req.ID)
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("agent: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// disconnect drops a broken connection so the next call dials again. Called with r.mu held.
+func (r *Remote) disconnect() {
+	r.conn.Close()
+	r.conn = nil
+}
+
+func (r *Remote) Capture(x, y, w, h int) (image.Image, error) {
+	resp, err := r.call(Request{Op: OpCapture, X: x, Y: y, W: w, H: h})
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(resp.Image))
+	if err != nil {
+		return nil, fmt.Errorf("decode capture: %w", err)
+	}
+	return img, nil
+}
+
+func (r *Remote) ScreenSize() (int, int, error) {
+	resp, err := r.call(Request{Op: OpScreenSize})
+	return resp.X, resp.Y, err
+}
+
+func (r *Remote) MousePos() (int, int, error) {
+	resp, err := r.call(Request{Op: OpMousePos})
+	return resp.X, resp.Y, err
+}
+
+func (r *Remote) Move(x, y int) error {
+	_, err := r.call(Request{Op: OpMove, X: x, Y: y})
+	return err
+}
+
+func (r *Remote) MoveSmooth(x, y int, low, high float64) error {
+	_, err := r.call(Request{Op: OpMoveSmooth, X: x, Y: y, Low: low, High: high})
+	return err
+}
+
+func (r *Remote) Click(button string) error {
+	_, err := r.call(Request{Op: OpClick, Button: button})
+	return err
+}
+
+func (r *Remote) Toggle(button, direction string) error {
+	_, err := r.call(Request{Op: OpToggle, Button: button, Direction: direction})
+	return err
+}
+
+func (r *Remote) KeyToggle(key, direction string) error {
+	_, err := r.call(Request{Op: OpKeyToggle, Button: key, Direction: direction})
+	return err
+}
+
+func (r *Remote) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil {
+		r.disconnect()
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// trackingListener remembers accepted connections so a test can cut them like a network drop
+type trackingListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, conn)
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *trackingListener) dropAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+// startAgent serves an agent over fake on a local test server
+func startAgent(t *testing.T, fake *Fake, token string) (*httptest.Server, *trackingListener) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(NewAgent(fake, token))
+	listener := &trackingListener{Listener: srv.Listener}
+	srv.Listener = listener
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv, listener
+}
+
+func agentAddress(srv *httptest.Server) string {
+	return "wss://" + strings.TrimPrefix(srv.URL, "https://") + AgentPath
+}
+
+// agentFingerprint returns the SHA-256 fingerprint of the test server's certificate
+func agentFingerprint(srv *httptest.Server) string {
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRemoteCaptureAndInput(t *testing.T) {
+	fake := NewFake(200, 100)
+	tooltip := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := range tooltip.Pix {
+		tooltip.Pix[i] = 0xff
+	}
+	fake.Paint(tooltip, 50, 20)
+	srv, _ := startAgent(t, fake, "secret")
+
+	remote, err := NewRemote(agentAddress(srv), "secret", agentFingerprint(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	w, h, err := remote.ScreenSize()
+	if err != nil || w != 200 || h != 100 {
+		t.Fatalf("ScreenSize() = %d, %d, %v; want 200, 100", w, h, err)
+	}
+
+	img, err := remote.Capture(45, 15, 20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != (image.Point{X: 20, Y: 20}) {
+		t.Fatalf("capture size = %v, want 20x20", size)
+	}
+	white := color.RGBAModel.Convert(color.White)
+	if got := color.RGBAModel.Convert(img.At(10, 10)); got != white {
+		t.Errorf("pixel inside the tooltip = %v, want white", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got == white {
+		t.Errorf("pixel outside the tooltip is white")
+	}
+	if _, err := remote.Capture(190, 90, 20, 20); err == nil {
+		t.Error("capture outside the screen did not fail")
+	}
+
+	if err := remote.Move(30, 40); err != nil {
+		t.Fatal(err)
+	}
+	if x, y, err := remote.MousePos(); err != nil || x != 30 || y != 40 {
+		t.Fatalf("MousePos() = %d, %d, %v; want 30, 40", x, y, err)
+	}
+	if err := remote.Click("left"); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Toggle("right", "down"); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.KeyToggle("shift", "up"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"move 30,40", "click left", "toggle right down", "key shift up"}
+	if got := fake.Events(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("agent received %q, want %q", got, want)
+	}
+}
+
+func TestRemoteRejectsBadToken(t *testing.T) {
+	fake := NewFake(100, 100)
+	srv, _ := startAgent(t, fake, "secret")
+
+	remote, err := NewRemote(agentAddress(srv), "wrong", agentFingerprint(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	err = remote.Click("left")
+	if err == nil || !strings.Contains(err.Error(), "rejected the token") {
+		t.Fatalf("Click() with a bad token = %v, want a rejected token error", err)
+	}
+	if events := fake.Events(); len(events) != 0 {
+		t.Errorf("agent ran %q for a client with a bad token", events)
+	}
+
+	if _, err := NewRemote(agentAddress(srv), "", agentFingerprint(srv)); err == nil {
+		t.Error("NewRemote() without a token did not fail")
+	}
+}
+
+func TestRemoteChecksCertificate(t *testing.T) {
+	fake := NewFake(100, 100)
+	srv, _ := startAgent(t, fake, "secret")
+
+	// Without the fingerprint the test server's self-signed certificate is not trusted
+	for _, fingerprint := range []string{"", strings.Repeat("ab", 32)} {
+		remote, err := NewRemote(agentAddress(srv), "secret", fingerprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Click("left"); err == nil {
+			t.Errorf("Click() with fingerprint %q succeeded", fingerprint)
+		}
+		remote.Close()
+	}
+	if events := fake.Events(); len(events) != 0 {
+		t.Errorf("agent ran %q for a client that did not trust it", events)
+	}
+
+	if _, err := NewRemote(agentAddress(srv), "secret", "3A:F0"); err == nil {
+		t.Error("NewRemote() with a short fingerprint did not fail")
+	}
+	// The agent prints it like browsers do, "3A:F0:..."
+	var parts []string
+	for rest := strings.ToUpper(agentFingerprint(srv)); rest != ""; rest = rest[2:] {
+		parts = append(parts, rest[:2])
+	}
+	remote, err := NewRemote(agentAddress(srv), "secret", strings.Join(parts, ":"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	if err := remote.Click("left"); err != nil {
+		t.Errorf("Click() with the fingerprint in browser form: %v", err)
+	}
+}
+
+func TestRemoteReconnects(t *testing.T) {
+	fake := NewFake(100, 100)
+	srv, listener := startAgent(t, fake, "secret")
+
+	remote, err := NewRemote(agentAddress(srv), "secret", agentFingerprint(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	if err := remote.Move(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	listener.dropAll()
+
+	// The call that finds the connection broken may fail; the next one dials again
+	remote.Move(2, 2)
+	if err := remote.Move(3, 3); err != nil {
+		t.Fatalf("Move() after reconnecting: %v", err)
+	}
+	if x, y, err := remote.MousePos(); err != nil || x != 3 || y != 3 {
+		t.Fatalf("MousePos() = %d, %d, %v; want 3, 3", x, y, err)
+	}
+}
+
+func TestAgentURL(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{"192.168.1.20:7777", "wss://192.168.1.20:7777/agent", false},
+		{"ws://vm.lan:7777/agent", "ws://vm.lan:7777/agent", false},
+		{"wss://vm.lan:7777/agent", "wss://vm.lan:7777/agent", false},
+		{"http://vm.lan:7777", "", true},
+		{"ws://", "", true},
+	}
+	for _, tt := range tests {
+		got, err := agentURL(tt.address)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("agentURL(%q) = %q, %v; want %q, error %v", tt.address, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
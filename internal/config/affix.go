@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// AffixCondition limits the prefix/suffix layout of a successful item, e.g. "prefixes<=2"
+type AffixCondition struct {
+	Count string // "prefixes", "suffixes", "open-prefixes" or "open-suffixes"
+	Op    string // OpAtLeast, OpAtMost or OpEqual
+	Value int
+}
+
+// affixCounts are the quantities a condition can test
+var affixCounts = map[string]bool{
+	"prefixes": true, "suffixes": true, "open-prefixes": true, "open-suffixes": true,
+}
+
+// ParseAffixCondition reads "<count><op><n>" where count is prefixes, suffixes,
+// open-prefixes or open-suffixes and op is <=, >= or ==. The shorthands "open-prefix"
+// and "open-suffix" mean at least one open slot.
+func ParseAffixCondition(input string) (AffixCondition, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), ""))
+	s = strings.NewReplacer("≤", "<=", "≥", ">=", "_", "-").Replace(s)
+
+	switch s {
+	case "open-prefix", "has-open-prefix":
+		return AffixCondition{Count: "open-prefixes", Op: OpAtLeast, Value: 1}, nil
+	case "open-suffix", "has-open-suffix":
+		return AffixCondition{Count: "open-suffixes", Op: OpAtLeast, Value: 1}, nil
+	}
+
+	for _, op := range []string{OpAtMost, OpAtLeast, OpEqual} {
+		count, num, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+		if !affixCounts[count] {
+			return AffixCondition{}, fmt.Errorf("unknown affix count %q (use prefixes, suffixes, open-prefixes or open-suffixes)", count)
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return AffixCondition{}, fmt.Errorf("invalid number in %q", input)
+		}
+		return AffixCondition{Count: count, Op: op, Value: n}, nil
+	}
+	return AffixCondition{}, fmt.Errorf("invalid affix condition %q, try \"open-suffix\" or \"prefixes<=2\"", input)
+}
+
+// ParseAffixConditions parses every condition of a config
+func ParseAffixConditions(inputs []string) ([]AffixCondition, error) {
+	var conds []AffixCondition
+	for _, input := range inputs {
+		cond, err := ParseAffixCondition(input)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// Accepts reports whether an item with the given prefix and suffix counts satisfies the condition
+func (c AffixCondition) Accepts(prefixes, suffixes int) bool {
+	n := 0
+	switch c.Count {
+	case "prefixes":
+		n = prefixes
+	case "suffixes":
+		n = suffixes
+	case "open-prefixes":
+		n = modcatalog.MaxPrefixes - prefixes
+	case "open-suffixes":
+		n = modcatalog.MaxSuffixes - suffixes
+	}
+
+	switch c.Op {
+	case OpAtMost:
+		return n <= c.Value
+	case OpEqual:
+		return n == c.Value
+	default:
+		return n >= c.Value
+	}
+}
+
+// String renders the condition in input form
+func (c AffixCondition) String() string {
+	return fmt.Sprintf("%s%s%d", c.Count, c.Op, c.Value)
+}
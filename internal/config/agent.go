@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// AgentTokenEnv names the environment variable that overrides poe2agent's saved token
+const AgentTokenEnv = "POE2_AGENT_TOKEN"
+
+// GetAgentTokenPath returns the file poe2agent keeps its token in
+func GetAgentTokenPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_agent_token")
+}
+
+// GetAgentCertPaths returns where poe2agent keeps its self-signed certificate and key
+func GetAgentCertPaths() (cert, key string) {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_agent_cert.pem"), filepath.Join(homeDir, ".poe2_agent_key.pem")
+}
+
+// LoadAgentToken returns the token servers authenticate with: $POE2_AGENT_TOKEN, or the one
+// in GetAgentTokenPath. A missing file is created with a new token, readable by the current
+// user only, so the token stays the same across restarts. created is true for a new token.
+func LoadAgentToken() (token string, created bool, err error) {
+	if token := strings.TrimSpace(os.Getenv(AgentTokenEnv)); token != "" {
+		return token, false, nil
+	}
+
+	path := GetAgentTokenPath()
+	info, err := os.Stat(path)
+	if err == nil {
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+			return "", false, fmt.Errorf("%s can be read by other users (chmod 600 it)", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, err
+		}
+		if token = strings.TrimSpace(string(data)); token == "" {
+			return "", false, fmt.Errorf("%s is empty", path)
+		}
+		return token, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", false, err
+	}
+
+	if token, err = NewSecret(); err != nil {
+		return "", false, err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestLoadAgentToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(AgentTokenEnv, "")
+
+	token, created, err := LoadAgentToken()
+	if err != nil || !created || token == "" {
+		t.Fatalf("first LoadAgentToken() = %q, %v, %v; want a new token", token, created, err)
+	}
+	if again, created, err := LoadAgentToken(); err != nil || created || again != token {
+		t.Errorf("after a restart LoadAgentToken() = %q, %v, %v; want %q again", again, created, err, token)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(GetAgentTokenPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	t.Setenv(AgentTokenEnv, "from-env")
+	if got, _, err := LoadAgentToken(); err != nil || got != "from-env" {
+		t.Errorf("LoadAgentToken() with %s set = %q, %v; want from-env", AgentTokenEnv, got, err)
+	}
+	t.Setenv(AgentTokenEnv, "")
+
+	if runtime.GOOS != "windows" {
+		os.Chmod(GetAgentTokenPath(), 0644)
+		if _, _, err := LoadAgentToken(); err == nil {
+			t.Error("a token file other users can read was accepted")
+		}
+	}
+}
@@ -4,22 +4,73 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"math"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
+
+	"poe2-chaos-crafter/internal/hotkey"
+	"poe2-chaos-crafter/internal/modcatalog"
+	"poe2-chaos-crafter/internal/scoring"
 )
 
 const SnapshotsDir = "snapshots"
 const ResourceDir = "resource"
+const CorpusDir = "testdata/tooltips"
+
+// Comparison operators for ModRequirement.Op
+const (
+	OpAtLeast = ">="      // value >= MinValue (default)
+	OpAtMost  = "<="      // value <= MaxValue
+	OpEqual   = "=="      // value == MinValue
+	OpBetween = "between" // MinValue <= value <= MaxValue
+)
+
+// Session modes for Config.Mode
+const (
+	ModeTarget   = "target"   // Stop at the first roll that matches a target mod (default)
+	ModeMaximise = "maximise" // Keep rolling for the best value until improvement is unlikely
+)
+
+// What to do with an item that does not match the profile's item constraints
+const (
+	MismatchSkip  = "skip"  // Move it to the result area untouched (default)
+	MismatchAbort = "abort" // Stop the run
+)
+
+// Answers to a needs_attention request
+const (
+	AnswerRetry = "retry" // The problem was fixed by hand, try again
+	AnswerSkip  = "skip"  // Give up on the current item
+	AnswerAbort = "abort" // Stop the run
+)
 
 // ModRequirement defines what mod to look for
 type ModRequirement struct {
-	Pattern     string // Regex pattern for the mod name
-	MinValue    int    // Minimum acceptable value (legacy, 0 = tier mode)
-	TierLevel   string // Tier to match (e.g., "T1", "T2"), empty = value mode
-	Description string // What this is
+	Pattern     string             // Regex pattern for the mod name
+	MinValue    float64            // Minimum acceptable value (legacy, 0 = tier mode)
+	MaxValue    float64            `json:",omitempty"` // Upper bound for "<=" and "between"
+	Op          string             `json:",omitempty"` // Comparison operator, see OpAtLeast etc. (default ">=")
+	TierLevel   string             // Tier to match (e.g., "T1", "T2"), empty = value mode
+	Description string             // What this is
+	ValueMins   map[string]float64 `json:",omitempty"` // Per-value minimums for multi-value mods, e.g. {"max": 20}
+	Derive      string             `json:",omitempty"` // How multiple values combine for MinValue: "avg", "sum", "min", "max" (default first value)
+}
+
+// PreprocessConfig controls the OCR preprocessing pipeline stages.
+// Zero values select the defaults noted on each field.
+type PreprocessConfig struct {
+	ColorMasks     []string // Text colour classes to keep: "white", "magic", "rare", "grey", "unique" (empty = all text)
+	MaskTolerance  int      // Max RGB distance from a class colour (default 70)
+	Scale          int      // Upscale factor (default 3)
+	Resample       string   // "nearest", "bilinear" or "catmullrom" (default "catmullrom")
+	Threshold      string   // "fixed", "otsu", "sauvola" or "none" (default "otsu")
+	FixedLevel     int      // Cut-off for the "fixed" threshold (default 80)
+	SauvolaWindow  int      // Window size in pixels for "sauvola" (default 25)
+	SauvolaK       float64  // Sensitivity for "sauvola" (default 0.2)
+	Deskew         bool     // Straighten slightly rotated text before OCR
+	MaxDeskewAngle float64  // Max correction in degrees (default 3)
 }
 
 // Config for the crafter
@@ -44,12 +95,46 @@ type Config struct {
 	ResultAreaHeight   int         // Height of result area in cells
 	UseBatchMode       bool        // Enable batch crafting workflow
 
+	// Item constraints, checked from the tooltip header before crafting an item
+	ExpectedBase   string // Base type must contain this text, e.g. "Sapphire Ring" (empty = any)
+	ExpectedClass  string // Item class such as "ring" or "body_armour" (empty = any)
+	MinItemLevel   int    // Minimum item level (0 = any)
+	MaxItemLevel   int    // Maximum item level (0 = any)
+	OnItemMismatch string // MismatchSkip (default) or MismatchAbort
+
+	// Rarity handling for normal and magic items, which chaos orbs cannot be used on
+	NonRareAction string                 // NonRareSkip (default) or NonRareUpgrade
+	UpgradePaths  map[string][]string    `json:",omitempty"` // Orbs per rarity, e.g. {"magic": ["regal"]} (empty = DefaultUpgradePaths)
+	CurrencyPos   map[string]image.Point `json:",omitempty"` // Backpack position of each orb used in an upgrade path
+
 	TargetMods       []ModRequirement // Support multiple target mods
 	ChaosPerRound    int              // Number of chaos orbs to use per item/round
+	MaxItems         int              `json:",omitempty"` // Stop the batch after this many items (0 = whole pending area)
+	OrbBudget        int              `json:",omitempty"` // Stop the batch after this many counted rolls (0 = unlimited)
 	Delay            time.Duration
 	Debug            bool
 	SaveAllSnapshots bool   // Save every attempt's screenshot
-	GameLanguage     string // Game client language for OCR ("en" or "zh-CN")
+	GameLanguage     string // Game client language code, see internal/modcatalog/data/lang (default "en")
+	Preprocess       PreprocessConfig
+
+	ScoreFormula       string   // Optional stat-weight formula, e.g. "life + total_res*1.5"; replaces the target mod check
+	ScoreThreshold     float64  // Minimum formula score for a success
+	AffixConditions    []string `json:",omitempty"` // Layout conditions that must also hold, e.g. "open-suffix", "prefixes<=2"
+	Mode               string   // ModeTarget (default) or ModeMaximise
+	MaximiseStopChance float64  // Maximise: stop on a new best once the chance to beat it drops below this (0 = default 0.2)
+
+	UnchangedHashDistance int // Max tooltip hash bit difference treated as "no change" (0 = default 6)
+	VerifyTimeoutMs       int // How long to wait for the tooltip to change after a click (0 = default 1500)
+	MaxUnappliedClicks    int // Consecutive unapplied clicks before auto-pausing (0 = default 5)
+	// Click again right away when a roll did not change the item. Without it the bot waits
+	// one more verify timeout first, in case the game is slow. Either way only applied rolls
+	// use up ChaosPerRound.
+	ReclickOnNoChange bool
+
+	Hotkeys map[string]string `json:",omitempty"` // Action → key, e.g. {"pause": "F12", "stop": "Ctrl+F12"} (unset = defaults)
+
+	AttentionTimeoutSec int    // How long to wait for an answer when the bot needs attention (0 = default 300)
+	OnAttentionTimeout  string // AnswerRetry, AnswerSkip or AnswerAbort (default) when nobody answers
 }
 
 // GetConfigPath returns the config file path
@@ -58,6 +143,12 @@ func GetConfigPath() string {
 	return filepath.Join(homeDir, ".poe2_crafter_config.json")
 }
 
+// GetCheckpointPath returns the file an unfinished batch session is saved to
+func GetCheckpointPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_checkpoint.json")
+}
+
 // SaveConfig saves the configuration to a JSON file
 func SaveConfig(cfg Config) error {
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -79,8 +170,104 @@ func LoadConfig() (Config, error) {
 	return cfg, err
 }
 
+// Validate checks the parts of the config that are parsed when a session starts: score
+// formula, affix conditions, upgrade paths and hotkeys
+func (c Config) Validate() error {
+	if c.ScoreFormula != "" {
+		if _, err := scoring.Compile(c.ScoreFormula); err != nil {
+			return fmt.Errorf("invalid score formula: %w", err)
+		}
+	}
+	if _, err := ParseAffixConditions(c.AffixConditions); err != nil {
+		return err
+	}
+	if err := c.ValidateUpgradePaths(); err != nil {
+		return err
+	}
+	_, err := hotkey.ParseBindings(c.Hotkeys)
+	return err
+}
+
+// ApplyCraftDefaults fills in the runtime fields and defaults of a saved config
+func (c *Config) ApplyCraftDefaults() {
+	c.TooltipRect.Min.X = c.ItemPos.X + c.TooltipOffset.X
+	c.TooltipRect.Min.Y = c.ItemPos.Y + c.TooltipOffset.Y
+	c.TooltipRect.Max.X = c.TooltipRect.Min.X + c.TooltipSize.X
+	c.TooltipRect.Max.Y = c.TooltipRect.Min.Y + c.TooltipSize.Y
+
+	c.UseBatchMode = true
+	if c.ItemWidth == 0 {
+		c.ItemWidth = 1
+	}
+	if c.ItemHeight == 0 {
+		c.ItemHeight = 1
+	}
+	if c.ChaosPerRound == 0 {
+		c.ChaosPerRound = 10
+	}
+}
+
+// Accepts reports whether a value satisfies the requirement's comparison
+func (m ModRequirement) Accepts(value float64) bool {
+	switch m.Op {
+	case OpAtMost:
+		return value <= m.MaxValue
+	case OpEqual:
+		return math.Abs(value-m.MinValue) < 1e-6
+	case OpBetween:
+		return value >= m.MinValue && value <= m.MaxValue
+	default:
+		return value >= m.MinValue
+	}
+}
+
+// parseThreshold reads "80", ">=80", "<=40", "==80" or "60-80" into an operator and bounds
+func parseThreshold(s string) (op string, lo, hi float64, err error) {
+	switch {
+	case strings.HasPrefix(s, ">="):
+		lo, err = modcatalog.ParseValue(s[2:])
+		return OpAtLeast, lo, 0, err
+	case strings.HasPrefix(s, "<="):
+		hi, err = modcatalog.ParseValue(s[2:])
+		return OpAtMost, 0, hi, err
+	case strings.HasPrefix(s, "=="):
+		lo, err = modcatalog.ParseValue(s[2:])
+		return OpEqual, lo, 0, err
+	}
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		if lo, err = modcatalog.ParseValue(from); err != nil {
+			return "", 0, 0, err
+		}
+		if hi, err = modcatalog.ParseValue(to); err != nil {
+			return "", 0, 0, err
+		}
+		if lo > hi {
+			return "", 0, 0, fmt.Errorf("empty range %s", s)
+		}
+		return OpBetween, lo, hi, nil
+	}
+	lo, err = modcatalog.ParseValue(s)
+	return OpAtLeast, lo, 0, err
+}
+
+// describeThreshold renders a non-default comparison for descriptions, e.g. "≤ 40"
+func describeThreshold(op string, lo, hi float64) string {
+	switch op {
+	case OpAtMost:
+		return "≤ " + modcatalog.FormatValue(hi)
+	case OpEqual:
+		return "= " + modcatalog.FormatValue(lo)
+	case OpBetween:
+		return modcatalog.FormatValue(lo) + "-" + modcatalog.FormatValue(hi)
+	}
+	return modcatalog.FormatValue(lo) + "+"
+}
+
 // ParseModInput parses user input and creates a ModRequirement
-// gameLang selects which regex patterns to generate ("zh-CN" for Chinese, anything else for English)
+// gameLang selects which language of the mod catalog generates the regex pattern.
+// Input is "<mod> <threshold>" and/or per-value minimums, e.g. "fire-dmg 20 max=30".
+// The threshold is a minimum ("80", ">=80"), a ceiling ("<=40"), an exact value ("==80")
+// or a range ("60-80").
 func ParseModInput(input string, gameLang string) ModRequirement {
 	parts := strings.Fields(input)
 	if len(parts) < 2 {
@@ -89,77 +276,55 @@ func ParseModInput(input string, gameLang string) ModRequirement {
 
 	modType := strings.ToLower(parts[0])
 
-	// Parse minimum value
-	value, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return ModRequirement{}
-	}
-
-	type modTemplate struct {
-		pattern string
-		desc    string
-	}
-
-	var templates map[string]modTemplate
-
-	if gameLang == "zh-CN" {
-		templates = map[string]modTemplate{
-			"life":        {`\+?(\d+)(?:\(\d+-\d+\))?\s*最大生命`, "生命 %d+"},
-			"mana":        {`\+?(\d+)(?:\(\d+-\d+\))?\s*最大魔力`, "魔力 %d+"},
-			"str":         {`\+?(\d+)(?:\(\d+-\d+\))?\s*力量`, "力量 %d+"},
-			"dex":         {`\+?(\d+)(?:\(\d+-\d+\))?\s*敏捷`, "敏捷 %d+"},
-			"int":         {`\+?(\d+)(?:\(\d+-\d+\))?\s*智慧`, "智慧 %d+"},
-			"spirit":      {`\+?(\d+)(?:\(\d+-\d+\))?\s*精魂`, "精魂 %d+"},
-			"spell-level": {`\+(\d+)\s*(?:所有)?法术技能等级`, "+%d 法术技能等级"},
-			"proj-level":  {`\+(\d+)\s*(?:所有)?投射物技能等级`, "+%d 投射物技能等级"},
-			"crit-dmg":    {`(\d+)(?:\(\d+-\d+\))?%?\s*暴击伤害加成`, "%d%% 暴击伤害加成"},
-			"fire-res":    {`(\d+)(?:\(\d+-\d+\))?%?\s*火焰抗性`, "火焰抗性 %d+%%"},
-			"cold-res":    {`(\d+)(?:\(\d+-\d+\))?%?\s*冰冷抗性`, "冰冷抗性 %d+%%"},
-			"light-res":   {`(\d+)(?:\(\d+-\d+\))?%?\s*闪电抗性`, "闪电抗性 %d+%%"},
-			"chaos-res":   {`(\d+)(?:\(\d+-\d+\))?%?\s*混沌抗性`, "混沌抗性 %d+%%"},
-			"armor":       {`(\d+)(?:\(\d+-\d+\))?\s*护甲`, "护甲 %d+"},
-			"evasion":     {`(\d+)(?:\(\d+-\d+\))?\s*闪避`, "闪避 %d+"},
-			"es":          {`\+?(\d+)(?:\(\d+-\d+\))?\s*最大能量护盾`, "能量护盾 %d+"},
-			"movespeed":   {`(\d+)(?:\(\d+-\d+\))?%?\s*移动速度`, "移动速度 %d+%%"},
-			"attackspeed": {`(\d+)(?:\(\d+-\d+\))?%?\s*攻击速度`, "攻击速度 %d+%%"},
-			"castspeed":   {`(\d+)(?:\(\d+-\d+\))?%?\s*施放速度`, "施放速度 %d+%%"},
+	// Parse the threshold and name=value minimums (decimals allowed)
+	op, value, maxValue := OpAtLeast, 0.0, 0.0
+	var valueMins map[string]float64
+	for _, part := range parts[1:] {
+		name, num, named := strings.Cut(part, "=")
+		if !named || name == "" || strings.ContainsAny(name, "<>") {
+			var err error
+			if op, value, maxValue, err = parseThreshold(part); err != nil {
+				return ModRequirement{}
+			}
+			continue
+		}
+		n, err := modcatalog.ParseValue(num)
+		if err != nil {
+			return ModRequirement{}
 		}
-	} else {
-		templates = map[string]modTemplate{
-			// Pattern explanation: (?:\(\d+-\d+\))? = optional range display like (165-179)
-			"life":        {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+LIFE`, "Life %d+"},
-			"mana":        {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+MANA`, "Mana %d+"},
-			"str":         {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+STRENGTH`, "Strength %d+"},
-			"dex":         {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+DEXTERITY`, "Dexterity %d+"},
-			"int":         {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+INTELLIGENCE`, "Intelligence %d+"},
-			"spirit":      {`(?i)[+#]?(\d+)(?:\(\d+-\d+\))?\s+TO\s+SPIRIT`, "Spirit %d+"},
-			"spell-level": {`\+(\d+)\s+TO\s+LEVEL\s+OF\s+ALL\s+SPELL\s+SKILLS`, "+%d to Level of all Spell Skills (or higher)"},
-			"proj-level":  {`\+(\d+)\s+TO\s+LEVEL\s+OF\s+ALL\s+PROJECTILE\s+SKILLS`, "+%d to Level of all Projectile Skills (or higher)"},
-			"crit-dmg":    {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*INCREASED\s+CRITICAL\s+DAMAGE\s+BONUS`, "%d%%+ increased Critical Damage Bonus"},
-			"fire-res":    {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?FIRE\s+RESISTANCE`, "Fire Res %d+%%"},
-			"cold-res":    {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?COLD\s+RESISTANCE`, "Cold Res %d+%%"},
-			"light-res":   {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?LIGHTNING\s+RESISTANCE`, "Lightning Res %d+%%"},
-			"chaos-res":   {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?CHAOS\s+RESISTANCE`, "Chaos Res %d+%%"},
-			"armor":       {`(?i)(\d+)(?:\(\d+-\d+\))?\s+(?:INCREASED\s+)?ARMOUR`, "Armour %d+"},
-			"evasion":     {`(?i)(\d+)(?:\(\d+-\d+\))?\s+(?:INCREASED\s+)?EVASION`, "Evasion %d+"},
-			"es":          {`(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+ENERGY\s+SHIELD`, "Energy Shield %d+"},
-			"movespeed":   {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?MOVEMENT\s+SPEED`, "Movement Speed %d+%%"},
-			"attackspeed": {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?ATTACK\s+SPEED`, "Attack Speed %d+%%"},
-			"castspeed":   {`(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?CAST\s+SPEED`, "Cast Speed %d+%%"},
+		if valueMins == nil {
+			valueMins = make(map[string]float64)
 		}
+		valueMins[strings.ToLower(name)] = n
 	}
 
-	if tmpl, exists := templates[modType]; exists {
-		return ModRequirement{
-			Pattern:     tmpl.pattern,
-			MinValue:    value,
-			TierLevel:   "",
-			Description: fmt.Sprintf(tmpl.desc, value),
+	if mod := modcatalog.Default().Mod(modType); mod != nil {
+		if loc := mod.Localized(gameLang); loc != nil {
+			desc := fmt.Sprintf(loc.Description, value)
+			if op != OpAtLeast {
+				desc = loc.Name + " " + describeThreshold(op, value, maxValue)
+			}
+			for _, name := range loc.ValueNames() {
+				if want, ok := valueMins[name]; ok {
+					desc += fmt.Sprintf(", %s %s+", name, modcatalog.FormatValue(want))
+				}
+			}
+			return ModRequirement{
+				Pattern:     loc.Regexp().String(),
+				MinValue:    value,
+				MaxValue:    maxValue,
+				Op:          op,
+				TierLevel:   "",
+				Description: desc,
+				ValueMins:   valueMins,
+				Derive:      mod.Derive,
+			}
 		}
 	}
 
 	// Custom regex
-	if strings.Contains(input, "(\\d+)") || strings.Contains(input, `(\d+)`) {
+	if strings.Contains(input, "(\\d+)") || strings.Contains(input, `(\d+)`) ||
+		strings.Contains(input, "{int") || strings.Contains(input, "{dec") {
 		return ModRequirement{
 			Pattern:     input,
 			MinValue:    0,
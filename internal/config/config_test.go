@@ -0,0 +1,75 @@
+package config
+
+import "testing"
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in     string
+		op     string
+		lo, hi float64
+	}{
+		{"80", OpAtLeast, 80, 0},
+		{">=80", OpAtLeast, 80, 0},
+		{"<=40", OpAtMost, 0, 40},
+		{"==80", OpEqual, 80, 0},
+		{"60-80", OpBetween, 60, 80},
+		{"70-70", OpBetween, 70, 70},
+		{"1.5", OpAtLeast, 1.5, 0},
+		{"<=0,5", OpAtMost, 0, 0.5},
+		{"0,5-1,5", OpBetween, 0.5, 1.5},
+	}
+	for _, tt := range tests {
+		op, lo, hi, err := parseThreshold(tt.in)
+		if err != nil || op != tt.op || lo != tt.lo || hi != tt.hi {
+			t.Errorf("parseThreshold(%q) = %s, %v, %v, %v; want %s, %v, %v", tt.in, op, lo, hi, err, tt.op, tt.lo, tt.hi)
+		}
+	}
+
+	for _, in := range []string{"80-60", "1,5-0,5", "abc", ">=", "60-", "-80", "=>80"} {
+		if op, lo, hi, err := parseThreshold(in); err == nil {
+			t.Errorf("parseThreshold(%q) = %s, %v, %v; want an error", in, op, lo, hi)
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		threshold string
+		value     float64
+		want      bool
+	}{
+		{"80", 80, true},
+		{"80", 79, false},
+		{">=80", 95, true},
+		{"<=40", 40, true},
+		{"<=40", 41, false},
+		{"==80", 80, true},
+		{"==80", 81, false},
+		{"==0,5", 0.5, true},
+		{"60-80", 60, true},
+		{"60-80", 80, true},
+		{"60-80", 81, false},
+		{"60-80", 59, false},
+		{"0,5-1,5", 1, true},
+		{"0,5-1,5", 1.6, false},
+	}
+	for _, tt := range tests {
+		op, lo, hi, err := parseThreshold(tt.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := ModRequirement{Op: op, MinValue: lo, MaxValue: hi}
+		if got := m.Accepts(tt.value); got != tt.want {
+			t.Errorf("%s accepts %v = %v, want %v", tt.threshold, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseModInputThresholds(t *testing.T) {
+	if m := ParseModInput("life 60-80", "en"); m.Op != OpBetween || m.MinValue != 60 || m.MaxValue != 80 {
+		t.Errorf("life 60-80 = %+v, want a 60-80 range", m)
+	}
+	if m := ParseModInput("life 80-60", "en"); m.Pattern != "" {
+		t.Errorf("life 80-60 = %+v, want it rejected as an empty range", m)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import "fmt"
+
+// What to do with an item that is not rare
+const (
+	NonRareSkip    = "skip"    // Move it to the result area untouched (default)
+	NonRareUpgrade = "upgrade" // Apply the upgrade path for its rarity, then craft it
+)
+
+// Currency is an orb that can be part of an upgrade path
+type Currency struct {
+	ID   string
+	Name string
+	From string // Rarity the orb is used on
+	To   string // Rarity the item has afterwards
+}
+
+// CurrencyCatalog lists the orbs that change an item's rarity
+var CurrencyCatalog = []Currency{
+	{ID: "transmutation", Name: "Orb of Transmutation", From: "normal", To: "magic"},
+	{ID: "augmentation", Name: "Orb of Augmentation", From: "magic", To: "magic"},
+	{ID: "regal", Name: "Regal Orb", From: "magic", To: "rare"},
+	{ID: "alchemy", Name: "Orb of Alchemy", From: "normal", To: "rare"},
+}
+
+// DefaultUpgradePaths turn normal items rare with an alchemy orb and magic items with a regal orb
+var DefaultUpgradePaths = map[string][]string{
+	"normal": {"alchemy"},
+	"magic":  {"regal"},
+}
+
+// LookupCurrency returns the catalog entry of a currency id
+func LookupCurrency(id string) (Currency, bool) {
+	for _, c := range CurrencyCatalog {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// UpgradePath returns the configured orbs that make an item of the given rarity rare
+func (c *Config) UpgradePath(rarity string) []string {
+	if path, ok := c.UpgradePaths[rarity]; ok {
+		return path
+	}
+	return DefaultUpgradePaths[rarity]
+}
+
+// ValidateUpgradePath checks that the orbs of a path can be applied in order and end on a rare item
+func ValidateUpgradePath(rarity string, path []string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("no upgrade path for %s items", rarity)
+	}
+	current := rarity
+	for _, id := range path {
+		currency, ok := LookupCurrency(id)
+		if !ok {
+			return fmt.Errorf("unknown currency %q", id)
+		}
+		if currency.From != current {
+			return fmt.Errorf("%s cannot be used on a %s item", currency.Name, current)
+		}
+		current = currency.To
+	}
+	if current != "rare" {
+		return fmt.Errorf("upgrade path for %s items ends on a %s item", rarity, current)
+	}
+	return nil
+}
+
+// ValidateUpgradePaths checks the normal and magic upgrade paths when upgrading is enabled
+func (c *Config) ValidateUpgradePaths() error {
+	if c.NonRareAction != NonRareUpgrade {
+		return nil
+	}
+	for _, rarity := range []string{"normal", "magic"} {
+		if err := ValidateUpgradePath(rarity, c.UpgradePath(rarity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
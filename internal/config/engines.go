@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultEngine is the name of the engine that plays on this machine
+const DefaultEngine = "local"
+
+// EngineTarget is one more engine the web server runs, e.g. for a game in a VM
+type EngineTarget struct {
+	Name    string // Shown in the dashboard's engine selector and used as ?engine=
+	Profile string // Profile Start and Resume use (empty = the current config)
+	Backend string // Backend kind, see backend.Target (empty = local)
+	Address string // Where a remote backend is reached
+	Token   string // Secret a remote backend authenticates with
+	// SHA-256 fingerprint the remote agent printed for its self-signed certificate
+	Fingerprint string `json:",omitempty"`
+}
+
+// GetEnginesPath returns the file listing the web server's extra engines
+func GetEnginesPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_engines.json")
+}
+
+// LoadEngineTargets reads the extra engines. No file means no extra engines.
+func LoadEngineTargets() ([]EngineTarget, error) {
+	data, err := os.ReadFile(GetEnginesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []EngineTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{DefaultEngine: true}
+	for _, t := range targets {
+		if err := validateName("engine", t.Name); err != nil {
+			return nil, err
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("engine %q is listed twice", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return targets, nil
+}
+
+// EnginePath returns the per-engine variant of a data file: the default engine uses path
+// itself, others get their name appended, e.g. ~/.poe2_crafter_queue_vm1.json
+func EnginePath(path, engine string) string {
+	if engine == "" || engine == DefaultEngine {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + engine + ext
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// safeName limits profile and engine names to something that is safe as a file name
+var safeName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// GetProfilesDir returns the directory saved profiles live in
+func GetProfilesDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_profiles")
+}
+
+// GetQueuePath returns the file the web server's job queue is saved to
+func GetQueuePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_queue.json")
+}
+
+// ValidateProfileName checks that a profile name can be used as a file name
+func ValidateProfileName(name string) error {
+	return validateName("profile", name)
+}
+
+func validateName(kind, name string) error {
+	if !safeName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid %s name %q (use letters, digits, spaces, '.', '_' or '-')", kind, name)
+	}
+	return nil
+}
+
+// SaveProfile saves cfg as a named profile, replacing any profile of that name
+func SaveProfile(name string, cfg Config) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(GetProfilesDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(GetProfilesDir(), name+".json"), data, 0644)
+}
+
+// LoadProfile loads a named profile
+func LoadProfile(name string) (Config, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return Config{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(GetProfilesDir(), name+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, fmt.Errorf("profile %q not found", name)
+		}
+		return Config{}, err
+	}
+
+	var cfg Config
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// LoadCraftConfig loads a profile, or the saved config if profile is empty, ready to craft with
+func LoadCraftConfig(profile string) (Config, error) {
+	cfg, err := LoadConfig()
+	if profile != "" {
+		cfg, err = LoadProfile(profile)
+	}
+	if err != nil {
+		return cfg, err
+	}
+	cfg.ApplyCraftDefaults()
+	return cfg, nil
+}
+
+// ListProfiles returns the names of the saved profiles in alphabetical order
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(GetProfilesDir())
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if ok && !entry.IsDir() && ValidateProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
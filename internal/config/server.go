@@ -0,0 +1,111 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// ServerSettings controls how the web server listens and who may use it. It lives apart
+// from Config so that secrets never end up in profiles or in GET /api/config.
+type ServerSettings struct {
+	Token          string   // Secret API clients send as "Authorization: Bearer <token>" (generated on first start)
+	Password       string   `json:",omitempty"` // Password for signing in from a browser (empty = sign in with the token)
+	LocalOnly      bool     // Listen on 127.0.0.1 only, so no other machine can connect
+	AllowedOrigins []string `json:",omitempty"` // Extra browser origins allowed to call the API, e.g. "http://crafter.lan:8080"
+	AllowedCIDRs   []string `json:",omitempty"` // Networks clients may connect from, e.g. "192.168.1.0/24" (empty = any; this machine always may)
+
+	Bind     string `json:",omitempty"` // Address to listen on, e.g. "192.168.1.5" (empty = all interfaces; LocalOnly forces 127.0.0.1)
+	Port     int    `json:",omitempty"` // Port to listen on (0 = 8080)
+	TLS      bool   // Serve HTTPS instead of HTTP
+	CertFile string `json:",omitempty"` // PEM certificate for HTTPS (empty = a self-signed one kept next to the config)
+	KeyFile  string `json:",omitempty"` // PEM private key of CertFile
+}
+
+// GetServerSettingsPath returns the file the web server's access settings are saved to
+func GetServerSettingsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_server.json")
+}
+
+// GetSelfSignedCertPaths returns where the generated HTTPS certificate and key are kept
+func GetSelfSignedCertPaths() (cert, key string) {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_cert.pem"), filepath.Join(homeDir, ".poe2_crafter_key.pem")
+}
+
+// LoadServerSettings reads the access settings. A missing file or token is created with
+// a new random token, so a fresh install never runs without authentication.
+func LoadServerSettings() (ServerSettings, error) {
+	var s ServerSettings
+	data, err := os.ReadFile(GetServerSettingsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return s, err
+		}
+	}
+	if err := s.Validate(); err != nil {
+		return s, err
+	}
+
+	if s.Token == "" {
+		if s.Token, err = NewSecret(); err != nil {
+			return s, err
+		}
+		if err := SaveServerSettings(s); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// SaveServerSettings writes the access settings, readable by the current user only
+func SaveServerSettings(s ServerSettings) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetServerSettingsPath(), data, 0600)
+}
+
+// Validate checks the allow-lists, the listen address and the certificate files
+func (s ServerSettings) Validate() error {
+	if s.Bind != "" && s.Bind != "localhost" && net.ParseIP(s.Bind) == nil {
+		return fmt.Errorf("invalid bind address %q (use an IP address of this machine)", s.Bind)
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d", s.Port)
+	}
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return fmt.Errorf("CertFile and KeyFile must be set together")
+	}
+	for _, origin := range s.AllowedOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("invalid allowed origin %q (use scheme://host[:port])", origin)
+		}
+	}
+	for _, cidr := range s.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid allowed network %q (use e.g. 192.168.1.0/24)", cidr)
+		}
+	}
+	return nil
+}
+
+// NewSecret returns a random 128-bit hex string for tokens and pairing codes
+func NewSecret() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// AttentionFile is the snapshot shown with a needs_attention request
+const AttentionFile = "attention.png"
+
+// attentionAnswers are the valid answers to a needs_attention request
+var attentionAnswers = map[string]bool{
+	config.AnswerRetry: true,
+	config.AnswerSkip:  true,
+	config.AnswerAbort: true,
+}
+
+// AttentionRequest is a problem the engine cannot solve by itself
+type AttentionRequest struct {
+	ID       int    `json:"id"`
+	Reason   string `json:"reason"`
+	Snapshot bool   `json:"snapshot"` // Whether snapshots/attention.png shows the problem
+	Deadline int64  `json:"deadline"` // Unix ms when the timeout answer is used
+	Default  string `json:"default"`  // Answer used on timeout
+
+	answer chan string
+}
+
+// RequestAttention reports a problem with a snapshot and blocks until it is answered with
+// config.AnswerRetry, AnswerSkip or AnswerAbort from the web UI or console, or times out.
+// Cancelling ctx counts as abort.
+func (e *Engine) RequestAttention(ctx context.Context, cfg *config.Config, reason string, img image.Image) string {
+	timeout := time.Duration(cfg.AttentionTimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	fallback := cfg.OnAttentionTimeout
+	if !attentionAnswers[fallback] {
+		fallback = config.AnswerAbort
+	}
+
+	req := &AttentionRequest{
+		Reason:   reason,
+		Deadline: time.Now().Add(timeout).UnixMilli(),
+		Default:  fallback,
+		answer:   make(chan string, 1),
+	}
+	if img != nil {
+		if err := SaveImage(img, filepath.Join(e.SnapshotsDir, AttentionFile)); err != nil {
+			fmt.Printf("⚠ Warning: Could not save attention snapshot: %v\n", err)
+		} else {
+			req.Snapshot = true
+		}
+	}
+
+	e.attentionMu.Lock()
+	e.attentionSeq++
+	req.ID = e.attentionSeq
+	e.attention = req
+	e.attentionMu.Unlock()
+	defer func() {
+		e.attentionMu.Lock()
+		e.attention = nil
+		e.attentionMu.Unlock()
+	}()
+
+	fmt.Printf("\n⚠  NEEDS ATTENTION: %s\n", reason)
+	PlayVictorySound()
+	e.Emit("needs_attention", req)
+	if e.Broadcaster == nil {
+		e.consoleOnce.Do(func() { go e.readConsoleAnswers() })
+		fmt.Printf("   Enter = retry, s = skip item, a = abort (%s in %s)... ", fallback, timeout)
+	} else {
+		fmt.Printf("   Answer in the web GUI (%s in %s)\n", fallback, timeout)
+	}
+
+	answer, timedOut := "", false
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	select {
+	case answer = <-req.answer:
+	case <-deadline.C:
+		answer, timedOut = fallback, true
+		fmt.Printf("\n⏱  No answer after %s, using %q\n", timeout, fallback)
+	case <-ctx.Done():
+		answer = config.AnswerAbort
+	}
+
+	fmt.Printf("   → %s\n", answer)
+	e.Emit("attention_resolved", AttentionResolvedData{ID: req.ID, Answer: answer, TimedOut: timedOut})
+	return answer
+}
+
+// AnswerAttention answers the pending request. id 0 answers whichever request is pending.
+func (e *Engine) AnswerAttention(id int, answer string) error {
+	if !attentionAnswers[answer] {
+		return fmt.Errorf("invalid answer %q (use retry, skip or abort)", answer)
+	}
+	e.attentionMu.Lock()
+	defer e.attentionMu.Unlock()
+	if e.attention == nil {
+		return fmt.Errorf("nothing needs attention")
+	}
+	if id != 0 && id != e.attention.ID {
+		return fmt.Errorf("request %d is no longer pending", id)
+	}
+	select {
+	case e.attention.answer <- answer:
+		return nil
+	default:
+		return fmt.Errorf("request %d was already answered", e.attention.ID)
+	}
+}
+
+// PendingAttention returns the open request, or nil
+func (e *Engine) PendingAttention() *AttentionRequest {
+	e.attentionMu.Lock()
+	defer e.attentionMu.Unlock()
+	return e.attention
+}
+
+// readConsoleAnswers turns console lines into answers for CLI users
+func (e *Engine) readConsoleAnswers() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		answer := config.AnswerRetry
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "s", "skip":
+			answer = config.AnswerSkip
+		case "a", "abort":
+			answer = config.AnswerAbort
+		}
+		e.AnswerAttention(0, answer)
+	}
+}
+
+// CaptureFullScreen grabs the whole screen for attention snapshots, or returns nil
+func (e *Engine) CaptureFullScreen() image.Image {
+	img, err := e.Backend.Capture(0, 0, 0, 0)
+	if err != nil {
+		fmt.Printf("⚠ Warning: Could not capture the screen: %v\n", err)
+		return nil
+	}
+	return img
+}
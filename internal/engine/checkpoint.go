@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// Checkpoint is the progress of a batch session. It is saved after every roll so a
+// session interrupted by a crash, restart or stop can be resumed.
+type Checkpoint struct {
+	SavedAt   time.Time
+	Layout    string           // Inventory areas the session ran with, see checkpointLayout
+	ItemCount int              // Items taken from the pending area so far
+	Processed []string         // Pending positions already taken, as "x,y"
+	Current   *CheckpointItem  // Item on the workbench, nil between items
+	Session   *CraftingSession // Rolls, stats and finished rounds
+
+	path string // File the checkpoint is saved to, see Engine.CheckpointPath
+}
+
+// CheckpointItem is the item that was on the workbench when the checkpoint was saved
+type CheckpointItem struct {
+	Round   RoundResult // Item number, start position, header and, once crafted, the outcome
+	Attempt int         // Last counted roll on the item
+	Crafted bool        // Rolling is over, only the move to the result area is left
+}
+
+// checkpointLayout describes the inventory areas a checkpoint is valid for
+func checkpointLayout(cfg config.Config) string {
+	return fmt.Sprintf("pending %v %dx%d, workbench %v, result %v %dx%d, item %dx%d",
+		cfg.PendingAreaTopLeft, cfg.PendingAreaWidth, cfg.PendingAreaHeight, cfg.WorkbenchTopLeft,
+		cfg.ResultAreaTopLeft, cfg.ResultAreaWidth, cfg.ResultAreaHeight, cfg.ItemWidth, cfg.ItemHeight)
+}
+
+// Summary describes the checkpoint in one line
+func (cp *Checkpoint) Summary() string {
+	summary := fmt.Sprintf("%d items done, %d rolls, saved %s",
+		len(cp.Session.RoundResults), cp.Session.TotalRolls, cp.SavedAt.Format("2006-01-02 15:04:05"))
+	if cp.Current != nil {
+		summary += fmt.Sprintf(", item #%d stopped after roll %d", cp.Current.Round.RoundNumber, cp.Current.Attempt)
+	}
+	return summary
+}
+
+// LoadCheckpoint reads the engine's saved checkpoint. It returns nil without an error when there is none.
+func (e *Engine) LoadCheckpoint() (*Checkpoint, error) {
+	data, err := os.ReadFile(e.CheckpointPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	if cp.Session == nil {
+		return nil, fmt.Errorf("invalid checkpoint: no session")
+	}
+	if cp.Session.ModStats == nil {
+		cp.Session.ModStats = make(map[string]*ModStat)
+	}
+	cp.path = e.CheckpointPath
+	return &cp, nil
+}
+
+// ClearCheckpoint removes the engine's saved checkpoint, if any
+func (e *Engine) ClearCheckpoint() error {
+	if err := os.Remove(e.CheckpointPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// save writes the checkpoint through a temp file so a crash never leaves half a file behind
+func (cp *Checkpoint) save() {
+	cp.SavedAt = time.Now()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		fmt.Printf("\n⚠ Warning: Could not save checkpoint: %v\n", err)
+		return
+	}
+
+	path := cp.path
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		fmt.Printf("\n⚠ Warning: Could not save checkpoint: %v\n", err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		fmt.Printf("\n⚠ Warning: Could not save checkpoint: %v\n", err)
+	}
+}
+
+// VerifyCheckpoint checks that the inventory still matches cp: same layout, the interrupted
+// item still on the workbench (or the workbench empty) and every finished item in its result slot
+func (e *Engine) VerifyCheckpoint(ctx context.Context, cfg config.Config, cp *Checkpoint) error {
+	if cp.Layout != checkpointLayout(cfg) {
+		return fmt.Errorf("the inventory layout of the profile changed")
+	}
+
+	onWorkbench := e.HasItemAtPosition(ctx, cfg, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if cp.Current != nil && !onWorkbench {
+		return fmt.Errorf("item #%d is no longer on the workbench", cp.Current.Round.RoundNumber)
+	}
+	if cp.Current == nil && onWorkbench {
+		return fmt.Errorf("the workbench is not empty")
+	}
+
+	for _, round := range cp.Session.RoundResults {
+		if round.EndPos == (image.Point{}) || round.ErrorMessage == resultMoveFailed {
+			continue
+		}
+		if !e.HasItemAtPosition(ctx, cfg, round.EndPos.X, round.EndPos.Y) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("item #%d is missing from its result slot (%d, %d)", round.RoundNumber, round.EndPos.X, round.EndPos.Y)
+		}
+	}
+	return nil
+}
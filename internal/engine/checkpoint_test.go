@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strings"
+	"testing"
+
+	"poe2-chaos-crafter/internal/backend"
+)
+
+// paintItem draws a white item into the cell centred on (x, y)
+func paintItem(fake *backend.Fake, x, y int) {
+	item := image.NewRGBA(image.Rect(0, 0, testCell, testCell))
+	draw.Draw(item, item.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	fake.Paint(item, x-testCell/2, y-testCell/2)
+}
+
+// interruptedCheckpoint has item #1 finished at (1000, 400) and item #2 on the workbench
+func interruptedCheckpoint(e *Engine) *Checkpoint {
+	session := &CraftingSession{
+		TotalRolls: 7,
+		ModStats:   map[string]*ModStat{"life": {ModName: "Life", Count: 3}},
+		RoundResults: []RoundResult{{
+			RoundNumber: 1,
+			StartPos:    image.Point{X: 25, Y: 25},
+			EndPos:      image.Point{X: 1000, Y: 400},
+			Success:     true,
+		}},
+	}
+	return &Checkpoint{
+		Layout:    checkpointLayout(batchConfig()),
+		ItemCount: 2,
+		Processed: []string{"25,25", "75,25"},
+		Current:   &CheckpointItem{Round: RoundResult{RoundNumber: 2, StartPos: image.Point{X: 75, Y: 25}}, Attempt: 4},
+		Session:   session,
+		path:      e.CheckpointPath,
+	}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	e, _ := newFakeEngine(t)
+	if cp, err := e.LoadCheckpoint(); cp != nil || err != nil {
+		t.Fatalf("LoadCheckpoint() without a file = %v, %v; want nil, nil", cp, err)
+	}
+
+	saved := interruptedCheckpoint(e)
+	saved.save()
+	if _, err := os.Stat(e.CheckpointPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("the temp file was left behind")
+	}
+
+	cp, err := e.LoadCheckpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Layout != saved.Layout || cp.ItemCount != 2 || strings.Join(cp.Processed, " ") != "25,25 75,25" {
+		t.Errorf("loaded %+v, want %+v", cp, saved)
+	}
+	if cp.Current == nil || cp.Current.Round.RoundNumber != 2 || cp.Current.Attempt != 4 || cp.Current.Crafted {
+		t.Errorf("Current = %+v, want item #2 after roll 4", cp.Current)
+	}
+	if cp.Session.TotalRolls != 7 || len(cp.Session.RoundResults) != 1 || cp.Session.RoundResults[0].EndPos != saved.Session.RoundResults[0].EndPos {
+		t.Errorf("Session = %+v, want %+v", cp.Session, saved.Session)
+	}
+	if stat := cp.Session.ModStats["life"]; stat == nil || stat.Count != 3 {
+		t.Errorf("ModStats = %v, want life seen 3 times", cp.Session.ModStats)
+	}
+	if !cp.SavedAt.Equal(saved.SavedAt) {
+		t.Errorf("SavedAt = %v, want %v", cp.SavedAt, saved.SavedAt)
+	}
+
+	if err := e.ClearCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if cp, err := e.LoadCheckpoint(); cp != nil || err != nil {
+		t.Errorf("LoadCheckpoint() after ClearCheckpoint() = %v, %v; want nil, nil", cp, err)
+	}
+	if err := e.ClearCheckpoint(); err != nil {
+		t.Errorf("ClearCheckpoint() without a file: %v", err)
+	}
+}
+
+func TestLoadCheckpointRejectsInvalidFiles(t *testing.T) {
+	e, _ := newFakeEngine(t)
+	for _, content := range []string{"{", `{"ItemCount": 2}`} {
+		if err := os.WriteFile(e.CheckpointPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if cp, err := e.LoadCheckpoint(); err == nil {
+			t.Errorf("LoadCheckpoint(%s) = %+v, want an error", content, cp)
+		}
+	}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(cp *Checkpoint)
+		paint   []image.Point
+		wantErr string
+	}{
+		{
+			name:  "matches",
+			paint: []image.Point{{X: 800, Y: 400}, {X: 1000, Y: 400}},
+		},
+		{
+			name:   "between items",
+			change: func(cp *Checkpoint) { cp.Current = nil },
+			paint:  []image.Point{{X: 1000, Y: 400}},
+		},
+		{
+			name:    "layout changed",
+			change:  func(cp *Checkpoint) { cp.Layout = strings.Replace(cp.Layout, "2x2", "3x2", 1) },
+			paint:   []image.Point{{X: 800, Y: 400}, {X: 1000, Y: 400}},
+			wantErr: "layout",
+		},
+		{
+			name:    "item missing from the workbench",
+			paint:   []image.Point{{X: 1000, Y: 400}},
+			wantErr: "item #2 is no longer on the workbench",
+		},
+		{
+			name:    "workbench not empty",
+			change:  func(cp *Checkpoint) { cp.Current = nil },
+			paint:   []image.Point{{X: 800, Y: 400}, {X: 1000, Y: 400}},
+			wantErr: "workbench is not empty",
+		},
+		{
+			name:    "missing result slot",
+			paint:   []image.Point{{X: 800, Y: 400}},
+			wantErr: "item #1 is missing from its result slot (1000, 400)",
+		},
+		{
+			name:   "result move had failed",
+			change: func(cp *Checkpoint) { cp.Session.RoundResults[0].ErrorMessage = resultMoveFailed },
+			paint:  []image.Point{{X: 800, Y: 400}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, fake := newFakeEngine(t)
+			if err := e.LoadEmptyCellReference("resource/empty_cell_reference.png"); err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range tt.paint {
+				paintItem(fake, p.X, p.Y)
+			}
+			cp := interruptedCheckpoint(e)
+			if tt.change != nil {
+				tt.change(cp)
+			}
+
+			err := e.VerifyCheckpoint(context.Background(), batchConfig(), cp)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("VerifyCheckpoint() = %v, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("VerifyCheckpoint() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResumeFailsOnMismatch(t *testing.T) {
+	e, fake := newFakeEngine(t)
+	paintItem(fake, 1000, 400) // Item #2 is gone from the workbench
+	e.Resume(context.Background(), batchConfig(), interruptedCheckpoint(e))
+
+	if e.State() != StateError || !strings.Contains(e.LastError(), "no longer on the workbench") {
+		t.Errorf("state %s, error %q; want the workbench mismatch", e.State(), e.LastError())
+	}
+	for _, event := range fake.Events() {
+		if strings.HasPrefix(event, "toggle") || strings.HasPrefix(event, "click") {
+			t.Fatalf("Resume clicked before the checkpoint was verified: %v", fake.Events())
+		}
+	}
+}
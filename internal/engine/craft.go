@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"os"
@@ -11,22 +12,70 @@ import (
 	"syscall"
 	"time"
 
+	"poe2-chaos-crafter/internal/backend"
 	"poe2-chaos-crafter/internal/config"
-
-	"github.com/go-vgo/robotgo"
+	"poe2-chaos-crafter/internal/hotkey"
+	"poe2-chaos-crafter/internal/modcatalog"
+	"poe2-chaos-crafter/internal/scoring"
 )
 
-// Craft is the main crafting function that handles batch mode processing
-func (e *Engine) Craft(cfg config.Config) {
-	// Initialize cooldown time and snapshot counter
-	e.PauseToggleCooldown.Store(time.Now())
+// Craft is the main crafting function that handles batch mode processing. It starts a new
+// session, discarding any checkpoint, and returns once the batch is done or ctx is cancelled;
+// RequestStop cancels it too.
+func (e *Engine) Craft(ctx context.Context, cfg config.Config) {
+	e.craft(ctx, cfg, nil)
+}
+
+// Resume continues the batch session saved in cp once the inventory is verified to match it
+func (e *Engine) Resume(ctx context.Context, cfg config.Config, cp *Checkpoint) {
+	e.craft(ctx, cfg, cp)
+}
+
+// craft runs a new batch session, or the one saved in cp
+func (e *Engine) craft(ctx context.Context, cfg config.Config, cp *Checkpoint) {
+	if err := e.states.TransitionFrom(StateRunning, StateIdle, StateCountdown, StateError); err != nil {
+		fmt.Printf("❌ ERROR: Cannot start crafting: %v\n", err)
+		return
+	}
+	defer e.finishRun()
+	ctx, cancel := e.SessionContext(ctx)
+	defer cancel()
+
+	if e.Local && !backend.HasLocal {
+		fmt.Println("❌ ERROR: This build has no local screen to craft on (built with -tags nolocal)")
+		e.states.Fail(fmt.Errorf("no local screen in this build"))
+		return
+	}
+
+	// Initialize snapshot counter and hotkey flags
 	e.SnapshotCounter.Store(0)
+	e.SkipRequested.Store(false)
+	e.MarkRequested.Store(false)
 
 	// Initialize crafting session for tracking
 	session := &CraftingSession{
 		StartTime: time.Now(),
 		ModStats:  make(map[string]*ModStat),
 	}
+	if cp != nil {
+		session = cp.Session
+	}
+	if cfg.ScoreFormula != "" {
+		formula, err := scoring.Compile(cfg.ScoreFormula)
+		if err != nil {
+			fmt.Printf("❌ ERROR: Invalid score formula: %v\n", err)
+			e.states.Fail(fmt.Errorf("invalid score formula: %w", err))
+			return
+		}
+		session.formula = formula
+	}
+	conds, err := config.ParseAffixConditions(cfg.AffixConditions)
+	if err != nil {
+		fmt.Printf("❌ ERROR: Invalid affix condition: %v\n", err)
+		e.states.Fail(fmt.Errorf("invalid affix condition: %w", err))
+		return
+	}
+	session.affixConditions = conds
 
 	// Register session with hub for web GUI status
 	if e.SessionManager != nil {
@@ -38,26 +87,26 @@ func (e *Engine) Craft(cfg config.Config) {
 		}
 	}()
 
-	// Setup signal handler for Ctrl+C
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Println("\n\n[DEBUG] Signal received (Ctrl+C)")
-		fmt.Println("🛑 Stop requested... Exiting safely.")
-		e.StopRequested.Store(true)
-		fmt.Println("[DEBUG] stopRequested flag set to true")
-	}()
-
-	// Ensure report is generated even if interrupted
-	defer func() {
-		session.EndTime = time.Now()
-		e.GenerateReport(session, cfg)
-	}()
-
-	// Clean up old debug snapshots from previous runs (only in debug mode)
-	if e.DebugMode {
-		CleanupDebugSnapshots()
+	// Global hotkeys run in their own goroutine for the whole session
+	stopHotkeys := e.StartHotkeys(&cfg)
+	defer stopHotkeys()
+
+	// Setup signal handler for Ctrl+C; it stops the local engine only
+	if e.Local {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sigChan)
+		go func() {
+			select {
+			case <-sigChan:
+			case <-ctx.Done():
+				return
+			}
+			fmt.Println("\n\n[DEBUG] Signal received (Ctrl+C)")
+			fmt.Println("🛑 Stop requested... Exiting safely.")
+			e.RequestStop()
+			fmt.Printf("[DEBUG] State: %s\n", e.State())
+		}()
 	}
 
 	// Create resource directory if it doesn't exist
@@ -74,10 +123,44 @@ func (e *Engine) Craft(cfg config.Config) {
 		fmt.Println("   Item detection may not work correctly without it!")
 	}
 
+	// A resumed session only continues if the inventory still matches the checkpoint
+	if cp != nil {
+		fmt.Println("\n🔍 Checking the inventory against the checkpoint...")
+		if err := e.VerifyCheckpoint(ctx, cfg, cp); err != nil {
+			if ctx.Err() != nil {
+				fmt.Println("\n✓ Stopped by user")
+				return
+			}
+			fmt.Printf("❌ ERROR: Cannot resume: %v\n", err)
+			e.states.Fail(fmt.Errorf("cannot resume: %w", err))
+			return
+		}
+		fmt.Printf("✓ Resuming session: %s\n", cp.Summary())
+	} else {
+		if err := e.ClearCheckpoint(); err != nil {
+			fmt.Printf("⚠ Warning: Could not remove old checkpoint: %v\n", err)
+		}
+		cp = &Checkpoint{Session: session}
+	}
+	cp.Layout = checkpointLayout(cfg)
+	cp.path = e.CheckpointPath
+	session.checkpoint = cp
+
+	// Ensure report is generated even if interrupted
+	defer func() {
+		session.EndTime = time.Now()
+		e.GenerateReport(session, cfg)
+	}()
+
+	// Clean up old debug snapshots from previous runs (only in debug mode)
+	if e.DebugMode {
+		e.CleanupDebugSnapshots()
+	}
+
 	// Generate grid snapshot at start of crafting (debug mode only)
 	if e.DebugMode && cfg.BackpackTopLeft.X != 0 && cfg.BackpackBottomRight.X != 0 {
 		fmt.Println("\n📸 Generating grid snapshot...")
-		if err := DrawBackpackGrid(cfg); err != nil {
+		if err := e.DrawBackpackGrid(cfg); err != nil {
 			fmt.Printf("⚠ Warning: Could not create grid snapshot: %v\n", err)
 		} else {
 			fmt.Println("✓ Grid snapshot: backpack_grid_debug.png")
@@ -85,7 +168,7 @@ func (e *Engine) Craft(cfg config.Config) {
 	}
 
 	// Ensure snapshots directory exists
-	os.MkdirAll(config.SnapshotsDir, 0755)
+	os.MkdirAll(e.SnapshotsDir, 0755)
 
 	// Create temp directory for OCR
 	tempDir := filepath.Join(os.TempDir(), "poe2_crafter")
@@ -99,15 +182,36 @@ func (e *Engine) Craft(cfg config.Config) {
 		fmt.Printf("✅ Result area: %dx%d cells\n\n", cfg.ResultAreaWidth, cfg.ResultAreaHeight)
 
 		processedPositions := make(map[string]bool)
-		itemCount := 0
+		for _, posKey := range cp.Processed {
+			processedPositions[posKey] = true
+		}
+		itemCount := cp.ItemCount
+
+		// Finish the item that was on the workbench when the session was interrupted
+		if cp.Current != nil && !e.resumeItem(ctx, cfg, session, cp.Current, tempDir) {
+			return
+		}
 
 		for {
-			if e.StopRequested.Load() {
+			if ctx.Err() != nil {
 				fmt.Println("\n✓ Stopped by user")
 				return
 			}
 
-			itemX, itemY, found := e.FindNextItemInArea(cfg, cfg.PendingAreaTopLeft, cfg.PendingAreaWidth, cfg.PendingAreaHeight, processedPositions)
+			if cfg.MaxItems > 0 && itemCount >= cfg.MaxItems {
+				fmt.Printf("\n✓ Item limit of %d reached\n", cfg.MaxItems)
+				break
+			}
+			if orbBudgetSpent(&cfg, session) {
+				fmt.Printf("\n✓ Orb budget of %d spent\n", cfg.OrbBudget)
+				break
+			}
+
+			itemX, itemY, found := e.FindNextItemInArea(ctx, cfg, cfg.PendingAreaTopLeft, cfg.PendingAreaWidth, cfg.PendingAreaHeight, processedPositions)
+			if ctx.Err() != nil {
+				fmt.Println("\n✓ Stopped by user")
+				return
+			}
 			if !found {
 				fmt.Println("\n✓ No more items in pending area")
 				break
@@ -125,159 +229,334 @@ func (e *Engine) Craft(cfg config.Config) {
 
 			posKey := fmt.Sprintf("%d,%d", itemX, itemY)
 			processedPositions[posKey] = true
+			cp.Processed = append(cp.Processed, posKey)
+			cp.ItemCount = itemCount
 
-			resultX, resultY, foundSlot := e.FindEmptySlotInArea(cfg, cfg.ResultAreaTopLeft, cfg.ResultAreaWidth, cfg.ResultAreaHeight)
-			if !foundSlot {
-				fmt.Println("\n❌ ERROR: Result area is full!")
-				if e.DebugMode {
-					DrawFullScreenDebugSnapshot(cfg, itemCount, "error_result_full", itemX, itemY, 0, 0)
-				}
-				fmt.Println("\n⚠ Warning: Please clear result area and restart.")
+			result, ok := e.findResultSlot(ctx, cfg, roundResult)
+			if !ok {
 				return
 			}
+			resultX, resultY := result.X, result.Y
 
 			fmt.Printf("     Pending: (%d, %d), Workbench: (%d, %d), Result: (%d, %d)\n",
 				itemX, itemY, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y, resultX, resultY)
 
 			if e.DebugMode {
-				if err := os.MkdirAll(config.SnapshotsDir, 0755); err != nil {
+				if err := os.MkdirAll(e.SnapshotsDir, 0755); err != nil {
 					fmt.Printf("⚠ Warning: Could not create snapshots directory: %v\n", err)
 				}
 
 				fmt.Println("  📸 [1/2] Saving fullscreen debug before move to workbench...")
-				if err := DrawFullScreenDebugSnapshot(cfg, itemCount, "1_before_move_to_workbench", itemX, itemY, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y); err != nil {
+				if err := e.DrawFullScreenDebugSnapshot(cfg, itemCount, "1_before_move_to_workbench", itemX, itemY, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y); err != nil {
 					fmt.Printf("❌ ERROR: Could not create debug snapshot: %v\n", err)
 				}
-				time.Sleep(500 * time.Millisecond)
+				Sleep(ctx, 500*time.Millisecond)
 			}
 
-			if e.StopRequested.Load() {
+			if ctx.Err() != nil {
 				fmt.Println("\n✓ Stopped by user")
 				return
 			}
 			fmt.Println("  → Moving to workbench...")
-			if !e.MoveItem(itemX, itemY, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y) {
+			if !e.MoveItem(ctx, itemX, itemY, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y) {
 				fmt.Println("\n✓ Stopped by user during move")
 				return
 			}
-			time.Sleep(200 * time.Millisecond)
+			Sleep(ctx, 200*time.Millisecond)
 
-			if !e.StopRequested.Load() && !e.HasItemAtPosition(cfg, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y) {
+			skipItem := false
+			for ctx.Err() == nil && !e.HasItemAtPosition(ctx, cfg, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y) {
 				fmt.Println("\n❌ ERROR: Failed to move item to workbench!")
 				fmt.Println("   Source: pending area")
 				fmt.Printf("   Destination: workbench (%d, %d)\n", cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y)
 
 				if e.DebugMode {
-					DrawFullScreenDebugSnapshot(cfg, itemCount, "error_move_to_workbench_failed", itemX, itemY, resultX, resultY)
+					e.DrawFullScreenDebugSnapshot(cfg, itemCount, "error_move_to_workbench_failed", itemX, itemY, resultX, resultY)
 				}
 
-				fmt.Println("\n⚠  PAUSED - Please manually move the item to workbench")
-				PlayVictorySound()
-				fmt.Print("   Press Enter to continue after fixing...")
-				fmt.Scanln()
+				answer := e.RequestAttention(ctx, &cfg, "Failed to move the item to the workbench. Move it there by hand, then retry.", e.CaptureFullScreen())
+				if answer == config.AnswerAbort {
+					e.RequestStop()
+				}
+				if answer == config.AnswerSkip {
+					skipItem = true
+					break
+				}
 			}
 
-			if e.StopRequested.Load() {
+			if ctx.Err() != nil {
 				fmt.Println("\n✓ Stopped by user")
 				return
 			}
+			if skipItem {
+				roundResult.ErrorMessage = "could not move the item to the workbench"
+				session.addRound(roundResult)
+				e.Emit("item_skipped", ItemSkippedData{ItemNumber: itemCount, Reason: roundResult.ErrorMessage})
+				fmt.Printf("  ✓ Item #%d skipped (%s)\n", itemCount, roundResult.ErrorMessage)
+				cp.save()
+				continue
+			}
+			cp.Current = &CheckpointItem{Round: roundResult}
+			cp.save()
 
 			cfg.ItemPos = cfg.WorkbenchTopLeft
-
-			fmt.Println("  → Starting crafting...")
-			craftSuccess := e.CraftSingleItem(&cfg, session, tempDir)
-
-			if e.StopRequested.Load() {
-				fmt.Println("\n✓ Stopped by user")
-				return
+			e.SkipRequested.Store(false)
+
+			// Read the tooltip header so a wrong item does not cost any orbs
+			header, err := e.InspectItem(ctx, &cfg, tempDir)
+			reason := ""
+			blocked := false
+			if err != nil {
+				fmt.Printf("  ⚠ Could not read item header: %v\n", err)
+				if hasItemConstraints(&cfg) {
+					reason = "could not read the item tooltip"
+				}
+			} else {
+				fmt.Printf("  🔎 %s\n", header)
+				e.Emit("item_inspected", ItemInspectedData{ItemNumber: itemCount, Item: header})
+				reason = BlockedReason(header)
+				blocked = reason != ""
+				if !blocked {
+					reason = CheckItemConstraints(&cfg, header)
+				}
+				if reason == "" && header.Rarity != "" && header.Rarity != RarityRare {
+					reason = e.makeRare(ctx, &cfg, session, &header, tempDir)
+					blocked = reason != ""
+				}
 			}
-
-			if e.DebugMode {
-				fmt.Println("  📸 [2/2] Saving fullscreen debug before move to result area...")
-				if err := DrawFullScreenDebugSnapshot(cfg, itemCount, "2_before_move_to_result", cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y, resultX, resultY); err != nil {
-					fmt.Printf("❌ ERROR: Could not create debug snapshot: %v\n", err)
+			roundResult.Item = header
+			cp.Current.Round.Item = header
+
+			craftSuccess := false
+			if reason != "" {
+				if blocked {
+					fmt.Printf("  ⚠ Item cannot be crafted: %s\n", reason)
+				} else {
+					fmt.Printf("  ⚠ Item does not match the profile: %s\n", reason)
+				}
+				roundResult.ErrorMessage = reason
+				e.Emit("item_skipped", ItemSkippedData{ItemNumber: itemCount, Reason: reason})
+				if !blocked && cfg.OnItemMismatch == config.MismatchAbort {
+					cp.Current.Round, cp.Current.Crafted = roundResult, true
+					cp.save()
+					session.addRound(roundResult)
+					fmt.Println("\n🛑 Aborting run (item mismatch)")
+					return
+				}
+				fmt.Println("  → Skipping item without crafting")
+			} else {
+				fmt.Println("  → Starting crafting...")
+				craftSuccess = e.CraftSingleItem(ctx, &cfg, session, tempDir)
+				e.markRoll(session)
+				if e.SkipRequested.Swap(false) {
+					reason = "skipped by user"
+					roundResult.ErrorMessage = reason
 				}
-				time.Sleep(500 * time.Millisecond)
 			}
 
-			if e.StopRequested.Load() {
+			// A stop mid-item keeps the item unfinished in the checkpoint
+			if ctx.Err() != nil {
 				fmt.Println("\n✓ Stopped by user")
 				return
 			}
-			fmt.Println("  → Moving to result area...")
-			if !e.MoveItem(cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y, resultX, resultY) {
-				fmt.Println("\n✓ Stopped by user during move")
+			roundResult.Success = craftSuccess
+			cp.Current.Round, cp.Current.Crafted = roundResult, true
+			cp.save()
+			if !e.finishItem(ctx, cfg, session, roundResult, reason, result) {
 				return
 			}
-			time.Sleep(200 * time.Millisecond)
+		}
 
-			if !e.StopRequested.Load() && !e.HasItemAtPosition(cfg, resultX, resultY) {
-				fmt.Println("\n❌ ERROR: Failed to move item to result area!")
-				fmt.Println("   Source: workbench")
-				fmt.Printf("   Destination: result area (%d, %d)\n", resultX, resultY)
+		fmt.Printf("\n🎉 Batch crafting complete! Processed %d items.\n", itemCount)
+		if err := e.ClearCheckpoint(); err != nil {
+			fmt.Printf("⚠ Warning: Could not remove checkpoint: %v\n", err)
+		}
+		return
+	}
+}
 
-				if e.DebugMode {
-					DrawFullScreenDebugSnapshot(cfg, itemCount, "error_move_to_result_failed", itemX, itemY, resultX, resultY)
-				}
+// orbBudgetSpent reports whether the session has used up the profile's orb budget
+func orbBudgetSpent(cfg *config.Config, session *CraftingSession) bool {
+	return cfg.OrbBudget > 0 && session.TotalRolls >= cfg.OrbBudget
+}
 
-				fmt.Println("\n⚠  PAUSED - Please manually move the item to result area")
-				PlayVictorySound()
-				fmt.Print("   Press Enter to continue after fixing...")
-				fmt.Scanln()
-			}
+// resultMoveFailed is the round error when an item could not be put into its result slot
+const resultMoveFailed = "could not move the item to the result area"
 
-			roundResult.EndPos = image.Point{X: resultX, Y: resultY}
-			roundResult.Success = craftSuccess
-			if session.TargetModHit {
-				roundResult.TargetHit = true
-				roundResult.TargetModName = session.TargetModName
-				roundResult.TargetValue = session.TargetValue
-			}
+// findResultSlot returns the first empty slot of the result area. It returns false when
+// ctx is cancelled, or fails the run when the result area is full.
+func (e *Engine) findResultSlot(ctx context.Context, cfg config.Config, round RoundResult) (image.Point, bool) {
+	x, y, found := e.FindEmptySlotInArea(ctx, cfg, cfg.ResultAreaTopLeft, cfg.ResultAreaWidth, cfg.ResultAreaHeight)
+	if ctx.Err() != nil {
+		fmt.Println("\n✓ Stopped by user")
+		return image.Point{}, false
+	}
+	if !found {
+		fmt.Println("\n❌ ERROR: Result area is full!")
+		if e.DebugMode {
+			e.DrawFullScreenDebugSnapshot(cfg, round.RoundNumber, "error_result_full", round.StartPos.X, round.StartPos.Y, 0, 0)
+		}
+		fmt.Println("\n⚠ Warning: Please clear result area and restart.")
+		e.states.Fail(fmt.Errorf("result area is full"))
+		return image.Point{}, false
+	}
+	return image.Point{X: x, Y: y}, true
+}
 
-			session.RoundResults = append(session.RoundResults, roundResult)
+// resumeItem finishes the item that was on the workbench when the session was interrupted:
+// it rolls the orbs left, unless rolling was already over, and moves it to the result area
+func (e *Engine) resumeItem(ctx context.Context, cfg config.Config, session *CraftingSession, cur *CheckpointItem, tempDir string) bool {
+	round := cur.Round
+	fmt.Printf("\n📦 Resuming item #%d after roll %d...\n", round.RoundNumber, cur.Attempt)
+	e.Emit("item_started", ItemStartedData{ItemNumber: round.RoundNumber, PendingX: round.StartPos.X, PendingY: round.StartPos.Y})
 
-			e.Emit("item_completed", ItemCompletedData{
-				ItemNumber: itemCount,
-				Success:    craftSuccess,
-				ResultX:    resultX,
-				ResultY:    resultY,
-			})
+	result, ok := e.findResultSlot(ctx, cfg, round)
+	if !ok {
+		return false
+	}
 
-			if craftSuccess {
-				fmt.Printf("  ✓ Item #%d completed!\n", itemCount)
-			} else {
-				fmt.Printf("  ✓ Item #%d processed (no target match)\n", itemCount)
-			}
+	reason := round.ErrorMessage
+	if !cur.Crafted {
+		cfg.ItemPos = cfg.WorkbenchTopLeft
+		e.SkipRequested.Store(false)
+		fmt.Println("  → Continuing crafting...")
+		round.Success = e.CraftSingleItem(ctx, &cfg, session, tempDir)
+		e.markRoll(session)
+		if e.SkipRequested.Swap(false) {
+			reason = "skipped by user"
+			round.ErrorMessage = reason
+		}
+		if ctx.Err() != nil {
+			fmt.Println("\n✓ Stopped by user")
+			return false
+		}
+		cur.Round, cur.Crafted = round, true
+		session.checkpoint.save()
+	}
+	return e.finishItem(ctx, cfg, session, round, reason, result)
+}
+
+// finishItem moves an item from the workbench to its result slot and records the round.
+// reason is why the item was not crafted, if it was not. Returns false if the run was stopped.
+func (e *Engine) finishItem(ctx context.Context, cfg config.Config, session *CraftingSession, round RoundResult, reason string, result image.Point) bool {
+	if ctx.Err() != nil {
+		fmt.Println("\n✓ Stopped by user")
+		return false
+	}
 
-			fmt.Println("  ✓ Ready for next item")
+	if e.DebugMode {
+		fmt.Println("  📸 [2/2] Saving fullscreen debug before move to result area...")
+		if err := e.DrawFullScreenDebugSnapshot(cfg, round.RoundNumber, "2_before_move_to_result", cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y, result.X, result.Y); err != nil {
+			fmt.Printf("❌ ERROR: Could not create debug snapshot: %v\n", err)
 		}
+		Sleep(ctx, 500*time.Millisecond)
+	}
 
-		fmt.Printf("\n🎉 Batch crafting complete! Processed %d items.\n", itemCount)
-		return
+	if ctx.Err() != nil {
+		fmt.Println("\n✓ Stopped by user")
+		return false
+	}
+	fmt.Println("  → Moving to result area...")
+	if !e.MoveItem(ctx, cfg.WorkbenchTopLeft.X, cfg.WorkbenchTopLeft.Y, result.X, result.Y) {
+		fmt.Println("\n✓ Stopped by user during move")
+		return false
+	}
+	Sleep(ctx, 200*time.Millisecond)
+
+	for ctx.Err() == nil && !e.HasItemAtPosition(ctx, cfg, result.X, result.Y) {
+		fmt.Println("\n❌ ERROR: Failed to move item to result area!")
+		fmt.Println("   Source: workbench")
+		fmt.Printf("   Destination: result area (%d, %d)\n", result.X, result.Y)
+
+		if e.DebugMode {
+			e.DrawFullScreenDebugSnapshot(cfg, round.RoundNumber, "error_move_to_result_failed", round.StartPos.X, round.StartPos.Y, result.X, result.Y)
+		}
+
+		answer := e.RequestAttention(ctx, &cfg, "Failed to move the item to the result area. Move it there by hand, then retry.", e.CaptureFullScreen())
+		if answer == config.AnswerAbort {
+			e.RequestStop()
+		}
+		if answer == config.AnswerSkip {
+			// Nothing left to do for this item, carry on with the next one
+			round.ErrorMessage = resultMoveFailed
+			break
+		}
+	}
+
+	round.EndPos = result
+	if reason == "" && session.LastItem != nil {
+		round.Prefixes = session.LastItem.Prefixes
+		round.Suffixes = session.LastItem.Suffixes
+	}
+	if round.Success && session.TargetModHit {
+		round.TargetHit = true
+		round.TargetModName = session.TargetModName
+		round.TargetValue = session.TargetValue
 	}
+
+	session.addRound(round)
+
+	e.Emit("item_completed", ItemCompletedData{
+		ItemNumber: round.RoundNumber,
+		Success:    round.Success,
+		ResultX:    result.X,
+		ResultY:    result.Y,
+		Error:      round.ErrorMessage,
+	})
+
+	if round.Success {
+		fmt.Printf("  ✓ Item #%d completed!\n", round.RoundNumber)
+	} else if reason != "" {
+		fmt.Printf("  ✓ Item #%d skipped (%s)\n", round.RoundNumber, reason)
+	} else {
+		fmt.Printf("  ✓ Item #%d processed (no target match)\n", round.RoundNumber)
+	}
+
+	fmt.Println("  ✓ Ready for next item")
+
+	if cp := session.checkpoint; cp != nil {
+		cp.Current = nil
+		cp.save()
+	}
+	return true
 }
 
 // CraftSingleItem performs the crafting loop for a single item
-func (e *Engine) CraftSingleItem(cfg *config.Config, session *CraftingSession, tempDir string) bool {
+func (e *Engine) CraftSingleItem(ctx context.Context, cfg *config.Config, session *CraftingSession, tempDir string) bool {
 	fmt.Println("\nPicking up chaos orb...")
-	robotgo.MoveSmooth(cfg.ChaosPos.X, cfg.ChaosPos.Y, 0.1, 0.1)
-	HumanDelay(20, 10)
-	robotgo.Click("right", false)
-	HumanDelay(50, 10)
+	e.Backend.MoveSmooth(cfg.ChaosPos.X, cfg.ChaosPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 20, 10)
+	e.Backend.Click("right")
+	HumanDelay(ctx, 50, 10)
 
-	robotgo.KeyToggle("shift", "down")
-	HumanDelay(20, 5)
+	e.Backend.KeyToggle("shift", "down")
+	HumanDelay(ctx, 20, 5)
 
-	robotgo.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
-	HumanDelay(30, 10)
+	e.Backend.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 30, 10)
 
 	defer func() {
-		robotgo.KeyToggle("shift", "up")
+		e.Backend.KeyToggle("shift", "up")
 	}()
 
-	for attempt := 1; attempt <= cfg.ChaosPerRound; attempt++ {
-		session.TotalRolls++
+	maxUnapplied := cfg.MaxUnappliedClicks
+	if maxUnapplied <= 0 {
+		maxUnapplied = 5
+	}
+
+	// Baseline tooltip before the first click, so every roll can be verified
+	prevHash := PerceptualHash(e.CaptureTooltip(cfg))
+	unapplied := 0
+
+	// A resumed item continues after its last counted roll
+	first := 1
+	if cp := session.checkpoint; cp != nil && cp.Current != nil && cp.Current.Attempt > 0 {
+		first = cp.Current.Attempt + 1
+	} else {
+		session.Best = nil
+	}
 
+	for attempt := first; attempt <= cfg.ChaosPerRound; attempt++ {
 		{
 			duration := time.Since(session.StartTime)
 			rollsPerMin := 0.0
@@ -292,74 +571,111 @@ func (e *Engine) CraftSingleItem(cfg *config.Config, session *CraftingSession, t
 			})
 		}
 
-		if e.StopRequested.Load() {
+		if ctx.Err() != nil {
 			fmt.Println("\n[DEBUG] Stop flag detected in main loop")
 			fmt.Println("\n✓ Stopped by user")
 			return false
 		}
 
-		e.CheckMiddleMouseButton()
+		if e.SkipRequested.Load() {
+			fmt.Println("\n⏭  Item skipped by user")
+			return false
+		}
 
-		if e.PauseRequested.Load() {
-			fmt.Print("\n[DEBUG] Pause flag detected in main loop")
-			fmt.Print("\n\n⏸  PAUSED - Press F12 to resume or Ctrl+C to exit... ")
-			robotgo.KeyToggle("shift", "up")
+		if orbBudgetSpent(cfg, session) {
+			fmt.Printf("\n💰 Orb budget of %d spent", cfg.OrbBudget)
+			return false
+		}
 
-			for e.PauseRequested.Load() && !e.StopRequested.Load() {
-				time.Sleep(100 * time.Millisecond)
-				e.CheckMiddleMouseButton()
-			}
+		e.markRoll(session)
 
-			if e.StopRequested.Load() {
-				fmt.Println("\n✓ Stopped by user")
+		if e.PauseRequested() {
+			fmt.Print("\n[DEBUG] Pause flag detected in main loop")
+			fmt.Printf("\n\n⏸  PAUSED - Press %s to resume or Ctrl+C to exit... ", e.hotkeyName(hotkey.ActionPause))
+			if !e.waitForResume(ctx, cfg) {
 				return false
 			}
-
-			fmt.Println("\n▶  RESUMING in 5 seconds... Switch to game now!")
-			for i := 5; i > 0; i-- {
-				fmt.Printf("\r%d... ", i)
-				time.Sleep(1 * time.Second)
-			}
-			fmt.Println("\r▶  RESUMED   ")
-			robotgo.MoveSmooth(cfg.ChaosPos.X, cfg.ChaosPos.Y, 0.1, 0.1)
-			HumanDelay(20, 10)
-			robotgo.Click("right", false)
-			HumanDelay(50, 10)
-			robotgo.KeyToggle("shift", "down")
-			HumanDelay(20, 5)
-			robotgo.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
-			HumanDelay(30, 10)
+			prevHash = PerceptualHash(e.CaptureTooltip(cfg))
 		}
 
 		fmt.Printf("\r[%d/%d] Crafting... ", attempt, cfg.ChaosPerRound)
 
-		robotgo.Click("left", false)
-		HumanDelay(int(cfg.Delay.Milliseconds())/3, 10)
+		e.Backend.Click("left")
+		HumanDelay(ctx, int(cfg.Delay.Milliseconds())/3, 10)
+
+		e.Backend.MoveSmooth(cfg.ItemPos.X+2, cfg.ItemPos.Y+2, 0.05, 0.05)
+		HumanDelay(ctx, 20, 5)
+		e.Backend.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.05, 0.05)
+		HumanDelay(ctx, 60, 20)
+
+		// Only a verified change counts as a roll
+		record, img, hash := e.VerifyRoll(ctx, cfg, prevHash)
+		if record.Outcome != RollApplied && !cfg.ReclickOnNoChange {
+			// Give a slow game another timeout before clicking again; a late change still counts
+			if late, lateImg, lateHash := e.VerifyRoll(ctx, cfg, prevHash); late.Outcome == RollApplied {
+				late.Wait += record.Wait
+				record, img, hash = late, lateImg, lateHash
+			}
+		}
+		record.Attempt = attempt
+		session.RecordAttempt(record)
+		e.Emit("roll_verified", RollVerifiedData{
+			AttemptNum:     attempt,
+			Outcome:        record.Outcome,
+			Distance:       record.Distance,
+			WaitMs:         record.Wait.Milliseconds(),
+			UnchangedRolls: session.UnchangedRolls,
+			TimedOutRolls:  session.TimedOutRolls,
+			Reclick:        record.Outcome != RollApplied,
+		})
 
-		robotgo.MoveSmooth(cfg.ItemPos.X+2, cfg.ItemPos.Y+2, 0.05, 0.05)
-		HumanDelay(20, 5)
-		robotgo.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.05, 0.05)
-		HumanDelay(60, 20)
+		if record.Outcome != RollApplied {
+			unapplied++
+			fmt.Printf("\n○ Roll not applied (%s after %dms) - not counted", record.Outcome, record.Wait.Milliseconds())
+
+			if unapplied >= maxUnapplied {
+				fmt.Printf("\n\n⚠️  %d clicks in a row did not change the item - Auto-pausing", unapplied)
+				fmt.Println("\n   Check that chaos orbs are left and the item is still under the cursor")
+				e.Transition(StatePaused)
+				fmt.Printf("\n⏸  AUTO-PAUSED - Press %s to resume or Ctrl+C to stop\n", e.hotkeyName(hotkey.ActionPause))
+				if !e.waitForResume(ctx, cfg) {
+					return false
+				}
+				prevHash = PerceptualHash(e.CaptureTooltip(cfg))
+				unapplied = 0
+			}
 
-		bitmap := robotgo.CaptureScreen(
-			cfg.TooltipRect.Min.X, cfg.TooltipRect.Min.Y,
-			cfg.TooltipRect.Dx(), cfg.TooltipRect.Dy(),
-		)
-		img := robotgo.ToImage(bitmap)
+			// The click did not use up an attempt
+			attempt--
+			continue
+		}
+		unapplied = 0
+		prevHash = hash
+		session.countRoll()
+		session.lastImage = img
 
-		SaveImage(img, filepath.Join(config.SnapshotsDir, "current_tooltip.png"))
+		SaveImage(img, filepath.Join(e.SnapshotsDir, "current_tooltip.png"))
 		e.Emit("tooltip_captured", TooltipCapturedData{Timestamp: time.Now().UnixMilli()})
 
-		text, err := e.RunTesseractOCR(img, tempDir, cfg.GameLanguage)
+		text, err := e.RunTesseractOCR(ctx, img, tempDir, cfg.GameLanguage, cfg.Preprocess)
 		if err != nil {
 			seqNum := e.SnapshotCounter.Load()
 			fmt.Printf("\n\n❌ OCR ERROR #%d: %v\n", seqNum, err)
 			fmt.Println("   Tooltip snapshot saved: snapshots/current_tooltip.png")
-			fmt.Println("\n⚠  PAUSED - OCR failed to read item tooltip")
-			fmt.Println("   Please check the tooltip snapshot and fix any issues")
-			PlayVictorySound()
-			fmt.Print("   Press Enter to retry this item...")
-			fmt.Scanln()
+
+			e.Backend.KeyToggle("shift", "up")
+			switch e.RequestAttention(ctx, cfg, fmt.Sprintf("OCR failed to read the item tooltip: %v", err), img) {
+			case config.AnswerSkip:
+				e.SkipRequested.Store(true)
+				return false
+			case config.AnswerAbort:
+				e.RequestStop()
+				return false
+			}
+			if !e.resumeCrafting(ctx, cfg) {
+				return false
+			}
+			prevHash = PerceptualHash(e.CaptureTooltip(cfg))
 			continue
 		}
 
@@ -385,58 +701,97 @@ func (e *Engine) CraftSingleItem(cfg *config.Config, session *CraftingSession, t
 			fmt.Printf("\n⚠ Warning: OCR #%d incomplete (%d chars)\n", seqNum, len(text))
 		}
 
-		TrackMods(text, session, session.TotalRolls)
+		TrackMods(text, cfg.GameLanguage, session, session.TotalRolls)
+		item := ParseItem(text, cfg.GameLanguage)
+		session.LastItem = &item
 
 		e.Emit("mods_tracked", ModsTrackedData{
 			OCRText:    text,
 			ModStats:   session.ModStats,
 			TotalRolls: session.TotalRolls,
+			Prefixes:   item.Prefixes,
+			Suffixes:   item.Suffixes,
+			Unknown:    item.Unknown,
 		})
 
+		if cp := session.checkpoint; cp != nil && cp.Current != nil {
+			cp.Current.Attempt = attempt
+			cp.save()
+		}
+
 		matched, matchedMod, value := CheckAnyMod(text, cfg.TargetMods)
 
+		// A score formula replaces the target mod check: success is score >= threshold
+		if session.formula != nil {
+			score := RollScore(session.formula, text, cfg.GameLanguage)
+			session.RollScores = append(session.RollScores, score)
+			e.Emit("roll_scored", RollScoredData{
+				AttemptNum: attempt,
+				Score:      score,
+				Threshold:  cfg.ScoreThreshold,
+				TotalRolls: session.TotalRolls,
+			})
+			if cfg.Debug {
+				fmt.Printf("\n[#%d Score] %.1f", attempt, score)
+			}
+
+			if len(strings.TrimSpace(text)) < 10 {
+				value = -1
+			} else {
+				matched = score >= cfg.ScoreThreshold
+				matchedMod = config.ModRequirement{Description: fmt.Sprintf("Score [%s]", session.formula)}
+				value = score
+			}
+		}
+
 		if value == -1 {
 			seqNum := e.SnapshotCounter.Load()
 			fmt.Printf("\n\n⚠️  OCR FAILED #%d - Auto-pausing", seqNum)
 			fmt.Printf("\n   Text: %s\n", strings.TrimSpace(text))
 
-			e.PauseRequested.Store(true)
-			fmt.Print("\n⏸  AUTO-PAUSED - Press F12 to resume or Ctrl+C to stop\n")
+			e.Transition(StatePaused)
+			fmt.Printf("\n⏸  AUTO-PAUSED - Press %s to resume or Ctrl+C to stop\n", e.hotkeyName(hotkey.ActionPause))
+			if !e.waitForResume(ctx, cfg) {
+				return false
+			}
+			prevHash = PerceptualHash(e.CaptureTooltip(cfg))
 
-			robotgo.KeyToggle("shift", "up")
+			continue
+		}
 
-			for e.PauseRequested.Load() && !e.StopRequested.Load() {
-				time.Sleep(100 * time.Millisecond)
-				e.CheckMiddleMouseButton()
+		// Affix conditions must hold on top of the target match
+		if matched && !item.Satisfies(session.affixConditions) {
+			matched = false
+			if cfg.Debug {
+				fmt.Printf("\n[#%d] %s matched, but layout %dp/%ds/%d unknown fails the affix conditions",
+					attempt, matchedMod.Description, len(item.Prefixes), len(item.Suffixes), len(item.Unknown))
 			}
+		}
 
-			if e.StopRequested.Load() {
-				fmt.Println("\n✓ Stopped by user")
-				return false
+		// Maximise mode: keep the best roll and only stop on a new best once the orbs left
+		// are unlikely to beat it
+		if cfg.Mode == config.ModeMaximise {
+			if !matched || (session.Best != nil && value <= session.Best.Score) {
+				continue
 			}
-
-			fmt.Println("\n▶  RESUMING in 5 seconds... Switch to game now!")
-			for i := 5; i > 0; i-- {
-				fmt.Printf("\r%d... ", i)
-				time.Sleep(1 * time.Second)
+			e.recordBestRoll(session, img, BestRoll{
+				Attempt: attempt,
+				Score:   value,
+				ModName: matchedMod.Description,
+				Text:    text,
+			})
+			remaining := cfg.ChaosPerRound - attempt
+			chance := ImprovementChance(attempt, remaining)
+			if chance >= maximiseStopChance(cfg) {
+				continue
 			}
-			fmt.Println("\r▶  RESUMED   ")
-			robotgo.MoveSmooth(cfg.ChaosPos.X, cfg.ChaosPos.Y, 0.1, 0.1)
-			HumanDelay(20, 10)
-			robotgo.Click("right", false)
-			HumanDelay(50, 10)
-			robotgo.KeyToggle("shift", "down")
-			HumanDelay(20, 5)
-			robotgo.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
-			HumanDelay(30, 10)
-
-			continue
+			fmt.Printf("\n\n🏁 Keeping this roll: %.0f%% chance to beat it with %d orbs left\n", chance*100, remaining)
 		}
 
 		if matched {
 			seqNum := e.SnapshotCounter.Load()
 			fmt.Printf("\n\n🎉 SUCCESS #%d (attempt %d)!\n", seqNum, attempt)
-			fmt.Printf("   Found: %s = %d\n", matchedMod.Description, value)
+			fmt.Printf("   Found: %s = %s\n", matchedMod.Description, modcatalog.FormatValue(value))
 
 			session.TargetModHit = true
 			session.TargetModName = matchedMod.Description
@@ -454,6 +809,60 @@ func (e *Engine) CraftSingleItem(cfg *config.Config, session *CraftingSession, t
 		}
 	}
 
+	if session.Best != nil {
+		fmt.Printf("\n\n○ Used all %d chaos orbs - best roll was attempt %d (%s = %s), see %s\n", cfg.ChaosPerRound,
+			session.Best.Attempt, session.Best.ModName, modcatalog.FormatValue(session.Best.Score), session.Best.Snapshot)
+		return false
+	}
 	fmt.Printf("\n\n○ Used all %d chaos orbs for this round without finding target mod\n", cfg.ChaosPerRound)
 	return false
 }
+
+// finishRun returns the engine to idle when Craft ends, unless the run failed
+func (e *Engine) finishRun() {
+	if e.State() == StatePaused {
+		e.RequestStop()
+	}
+	if e.State() != StateError {
+		e.Transition(StateIdle)
+	}
+}
+
+// waitForResume blocks while the engine is paused, then counts down and picks up the
+// chaos orb again. Returns false if a stop was requested while paused.
+func (e *Engine) waitForResume(ctx context.Context, cfg *config.Config) bool {
+	e.Backend.KeyToggle("shift", "up")
+
+	for e.PauseRequested() && ctx.Err() == nil {
+		Sleep(ctx, 100*time.Millisecond)
+	}
+
+	if ctx.Err() != nil {
+		fmt.Println("\n✓ Stopped by user")
+		return false
+	}
+	return e.resumeCrafting(ctx, cfg)
+}
+
+// resumeCrafting counts down so the user can switch back to the game, then picks up the
+// chaos orb again. Returns false if a stop was requested meanwhile.
+func (e *Engine) resumeCrafting(ctx context.Context, cfg *config.Config) bool {
+	fmt.Println("\n▶  RESUMING in 5 seconds... Switch to game now!")
+	for i := 5; i > 0; i-- {
+		fmt.Printf("\r%d... ", i)
+		if Sleep(ctx, time.Second) != nil {
+			fmt.Println("\n✓ Stopped by user")
+			return false
+		}
+	}
+	fmt.Println("\r▶  RESUMED   ")
+	e.Backend.MoveSmooth(cfg.ChaosPos.X, cfg.ChaosPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 20, 10)
+	e.Backend.Click("right")
+	HumanDelay(ctx, 50, 10)
+	e.Backend.KeyToggle("shift", "down")
+	HumanDelay(ctx, 20, 5)
+	e.Backend.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 30, 10)
+	return true
+}
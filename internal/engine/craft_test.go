@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"poe2-chaos-crafter/internal/backend"
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/hotkey"
+)
+
+// stopBound is how long a session may take to return after a stop
+const stopBound = time.Second
+
+// startSession moves the engine to running and opens a session context, as Craft does
+func startSession(t *testing.T, e *Engine) context.Context {
+	t.Helper()
+	if err := e.Transition(StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := e.SessionContext(context.Background())
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// waitInSession starts a long wait in ctx, like the delays of the craft loop
+func waitInSession(ctx context.Context) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		Sleep(ctx, time.Minute)
+		close(done)
+	}()
+	return done
+}
+
+func waitDone(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(stopBound):
+		t.Fatalf("the session did not return within %s of the stop", stopBound)
+	}
+}
+
+func TestRequestStopCancelsSessionWaits(t *testing.T) {
+	e := NewEngine(false)
+	done := waitInSession(startSession(t, e))
+
+	if err := e.RequestStop(); err != nil {
+		t.Fatal(err)
+	}
+	waitDone(t, done)
+	if e.State() != StateStopping {
+		t.Errorf("state after the stop = %s, want stopping", e.State())
+	}
+}
+
+func TestStopHotkeyCancelsSessionWaits(t *testing.T) {
+	e := NewEngine(false)
+	keys := hotkey.NewFakeBackend()
+	e.HotkeyBackend = keys
+	ctx := startSession(t, e)
+	stopHotkeys := e.StartHotkeys(&config.Config{})
+	defer stopHotkeys()
+	done := waitInSession(ctx)
+
+	keys.Tap(hotkey.DefaultBindings()[hotkey.ActionStop])
+	waitDone(t, done)
+}
+
+func TestStopWhilePausedCancelsSessionWaits(t *testing.T) {
+	e := NewEngine(false)
+	done := waitInSession(startSession(t, e))
+
+	if state, err := e.TogglePause(); err != nil || state != StatePaused {
+		t.Fatalf("TogglePause() = %s, %v; want paused", state, err)
+	}
+	if err := e.RequestStop(); err != nil {
+		t.Fatal(err)
+	}
+	waitDone(t, done)
+}
+
+// Cells of the test layout are 50x50 pixels; detection compares the middle 40x40
+const testCell = 50
+
+// newFakeEngine returns an engine on an empty fake display with its files in a temp dir
+func newFakeEngine(t *testing.T) (*Engine, *backend.Fake) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir) // Reports and the resource dir are relative to the working directory
+
+	if err := os.MkdirAll(config.ResourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	empty := image.NewRGBA(image.Rect(0, 0, testCell*8/10, testCell*8/10))
+	if err := SaveImage(empty, filepath.Join(config.ResourceDir, "empty_cell_reference.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := backend.NewFake(1920, 1080)
+	e := NewEngine(false)
+	e.Backend = fake
+	e.Local = false
+	e.SnapshotsDir = filepath.Join(dir, "snapshots")
+	e.CheckpointPath = filepath.Join(dir, "checkpoint.json")
+	return e, fake
+}
+
+// batchConfig lays out a 12x5 backpack at (0, 0) with the pending area filling it
+func batchConfig() config.Config {
+	return config.Config{
+		UseBatchMode:        true,
+		ChaosPerRound:       10,
+		ItemWidth:           1,
+		ItemHeight:          1,
+		BackpackTopLeft:     image.Point{X: 0, Y: 0},
+		BackpackBottomRight: image.Point{X: 12 * testCell, Y: 5 * testCell},
+		PendingAreaTopLeft:  image.Point{X: testCell / 2, Y: testCell / 2},
+		PendingAreaWidth:    12,
+		PendingAreaHeight:   5,
+		WorkbenchTopLeft:    image.Point{X: 800, Y: 400},
+		ResultAreaTopLeft:   image.Point{X: 1000, Y: 400},
+		ResultAreaWidth:     2,
+		ResultAreaHeight:    2,
+	}
+}
+
+// startCraft runs Craft in the background and waits until it scans the pending area
+func startCraft(t *testing.T, e *Engine, fake *backend.Fake) chan struct{} {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		e.Craft(context.Background(), batchConfig())
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(strings.Join(fake.Events(), "|"), "move 50,50") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Craft did not start scanning; state %s", e.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return done
+}
+
+func waitStopped(t *testing.T, e *Engine, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(stopBound):
+		t.Fatalf("Craft did not return within %s of the stop", stopBound)
+	}
+	if e.State() != StateIdle {
+		t.Errorf("state after the stop = %s, want idle", e.State())
+	}
+}
+
+func TestCraftReturnsAfterRequestStop(t *testing.T) {
+	e, fake := newFakeEngine(t)
+	e.HotkeyBackend = hotkey.NewFakeBackend()
+	done := startCraft(t, e, fake)
+
+	if err := e.RequestStop(); err != nil {
+		t.Fatal(err)
+	}
+	waitStopped(t, e, done)
+}
+
+func TestCraftReturnsAfterStopHotkey(t *testing.T) {
+	e, fake := newFakeEngine(t)
+	keys := hotkey.NewFakeBackend()
+	e.HotkeyBackend = keys
+	done := startCraft(t, e, fake)
+
+	keys.Tap(hotkey.DefaultBindings()[hotkey.ActionStop])
+	waitStopped(t, e, done)
+}
+
+func TestCraftReturnsWhenStoppedWhilePaused(t *testing.T) {
+	e, fake := newFakeEngine(t)
+	keys := hotkey.NewFakeBackend()
+	e.HotkeyBackend = keys
+	done := startCraft(t, e, fake)
+
+	if state, err := e.TogglePause(); err != nil || state != StatePaused {
+		t.Fatalf("TogglePause() = %s, %v; want paused", state, err)
+	}
+	if err := e.RequestStop(); err != nil {
+		t.Fatal(err)
+	}
+	waitStopped(t, e, done)
+}
+
+// The web server reads the counts of a session while the engine writes them; run with -race
+func TestSessionProgressWhileCrafting(t *testing.T) {
+	session := &CraftingSession{}
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			session.countRoll()
+			if i%10 == 9 {
+				session.addRound(RoundResult{RoundNumber: i / 10})
+			}
+		}
+		close(done)
+	}()
+	for {
+		items, rolls := session.Progress()
+		if items > rolls/10 {
+			t.Fatalf("%d items after %d rolls", items, rolls)
+		}
+		select {
+		case <-done:
+			if items, rolls := session.Progress(); items != 10 || rolls != 100 {
+				t.Errorf("Progress() = %d, %d; want 10, 100", items, rolls)
+			}
+			return
+		default:
+		}
+	}
+}
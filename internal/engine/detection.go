@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/png"
@@ -9,8 +10,6 @@ import (
 	"time"
 
 	"poe2-chaos-crafter/internal/config"
-
-	"github.com/go-vgo/robotgo"
 )
 
 // LoadEmptyCellReference loads the reference image of an empty cell
@@ -68,8 +67,9 @@ func CompareImages(img1, img2 image.Image) float64 {
 	return normalizedDiff
 }
 
-// HasItemAtPosition checks if there's an item at the given position
-func (e *Engine) HasItemAtPosition(cfg config.Config, x, y int) bool {
+// HasItemAtPosition checks if there's an item at the given position.
+// It reports false once ctx is cancelled.
+func (e *Engine) HasItemAtPosition(ctx context.Context, cfg config.Config, x, y int) bool {
 	if e.EmptyCellReference == nil {
 		fmt.Println("     [hasItemAtPosition] WARNING: No reference image loaded, using fallback detection")
 		return false
@@ -80,16 +80,21 @@ func (e *Engine) HasItemAtPosition(cfg config.Config, x, y int) bool {
 	cellWidth := totalWidth / 12
 	cellHeight := totalHeight / 5
 
-	robotgo.Move(50, 50)
-	time.Sleep(150 * time.Millisecond)
+	e.Backend.Move(50, 50)
+	if Sleep(ctx, 150*time.Millisecond) != nil {
+		return false
+	}
 
 	captureWidth := int(float64(cellWidth) * 0.8)
 	captureHeight := int(float64(cellHeight) * 0.8)
 	captureX := x - captureWidth/2
 	captureY := y - captureHeight/2
 
-	bitmap := robotgo.CaptureScreen(captureX, captureY, captureWidth, captureHeight)
-	img := robotgo.ToImage(bitmap)
+	img, err := e.Backend.Capture(captureX, captureY, captureWidth, captureHeight)
+	if err != nil {
+		fmt.Printf("     [hasItemAtPosition] ERROR: Could not capture cell: %v\n", err)
+		return false
+	}
 
 	diffScore := CompareImages(img, e.EmptyCellReference)
 
@@ -102,7 +107,7 @@ func (e *Engine) HasItemAtPosition(cfg config.Config, x, y int) bool {
 		if hasItem {
 			resultStr = "HAS_ITEM"
 		}
-		debugFile := filepath.Join(config.SnapshotsDir, fmt.Sprintf("cell_check_%d_pos_%d_%d_%s_diff%.3f.png",
+		debugFile := filepath.Join(e.SnapshotsDir, fmt.Sprintf("cell_check_%d_pos_%d_%d_%s_diff%.3f.png",
 			seqNum, x, y, resultStr, diffScore))
 		SaveImage(img, debugFile)
 		fmt.Printf("     [hasItemAtPosition] (%d,%d): diff=%.3f (threshold: %.3f) -> %v (saved: %s)\n",
@@ -115,7 +120,7 @@ func (e *Engine) HasItemAtPosition(cfg config.Config, x, y int) bool {
 }
 
 // FindNextItemInArea scans the area and returns the position of the first item found
-func (e *Engine) FindNextItemInArea(cfg config.Config, areaTopLeft image.Point, areaWidth, areaHeight int, skippedPositions map[string]bool) (int, int, bool) {
+func (e *Engine) FindNextItemInArea(ctx context.Context, cfg config.Config, areaTopLeft image.Point, areaWidth, areaHeight int, skippedPositions map[string]bool) (int, int, bool) {
 	cellWidth := (cfg.BackpackBottomRight.X - cfg.BackpackTopLeft.X) / 12
 	cellHeight := (cfg.BackpackBottomRight.Y - cfg.BackpackTopLeft.Y) / 5
 
@@ -135,8 +140,11 @@ func (e *Engine) FindNextItemInArea(cfg config.Config, areaTopLeft image.Point,
 				continue
 			}
 
+			if ctx.Err() != nil {
+				return 0, 0, false
+			}
 			positionsChecked++
-			if e.HasItemAtPosition(cfg, x, y) {
+			if e.HasItemAtPosition(ctx, cfg, x, y) {
 				fmt.Printf("  [findNextItemInArea] ✓ Found item at (%d,%d) after checking %d positions (skipped %d)\n",
 					x, y, positionsChecked, positionsSkipped)
 				return x, y, true
@@ -150,7 +158,7 @@ func (e *Engine) FindNextItemInArea(cfg config.Config, areaTopLeft image.Point,
 }
 
 // FindEmptySlotInArea finds the first empty slot in an area
-func (e *Engine) FindEmptySlotInArea(cfg config.Config, areaTopLeft image.Point, areaWidth, areaHeight int) (int, int, bool) {
+func (e *Engine) FindEmptySlotInArea(ctx context.Context, cfg config.Config, areaTopLeft image.Point, areaWidth, areaHeight int) (int, int, bool) {
 	cellWidth := (cfg.BackpackBottomRight.X - cfg.BackpackTopLeft.X) / 12
 	cellHeight := (cfg.BackpackBottomRight.Y - cfg.BackpackTopLeft.Y) / 5
 
@@ -159,7 +167,10 @@ func (e *Engine) FindEmptySlotInArea(cfg config.Config, areaTopLeft image.Point,
 			x := areaTopLeft.X + (col * cellWidth)
 			y := areaTopLeft.Y + (row * cellHeight)
 
-			if !e.HasItemAtPosition(cfg, x, y) {
+			if ctx.Err() != nil {
+				return 0, 0, false
+			}
+			if !e.HasItemAtPosition(ctx, cfg, x, y) {
 				return x, y, true
 			}
 		}
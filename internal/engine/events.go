@@ -17,7 +17,9 @@ func MarshalWSMessage(msgType string, data interface{}) ([]byte, error) {
 // Event data types
 
 type StateChangeData struct {
-	State string `json:"state"` // "idle", "running", "paused", "stopped"
+	State string `json:"state"`           // "idle", "countdown", "running", "paused", "stopping", "error"
+	From  string `json:"from,omitempty"`  // Previous state
+	Error string `json:"error,omitempty"` // Set when State is "error"
 }
 
 type RollAttemptedData struct {
@@ -36,13 +38,51 @@ type ModsTrackedData struct {
 	ParsedMods map[string]int    `json:"parsedMods"` // mod name -> value
 	ModStats   map[string]*ModStat `json:"modStats"`
 	TotalRolls int               `json:"totalRolls"`
+	Prefixes   []string          `json:"prefixes"` // Prefix mods on the roll
+	Suffixes   []string          `json:"suffixes"` // Suffix mods on the roll
+	Unknown    []string          `json:"unknown"`  // Affix lines that are neither
+}
+
+type RollVerifiedData struct {
+	AttemptNum     int    `json:"attemptNum"`
+	Outcome        string `json:"outcome"`  // "applied", "unchanged", "timed_out"
+	Distance       int    `json:"distance"` // Hash bit difference to the previous tooltip
+	WaitMs         int64  `json:"waitMs"`
+	UnchangedRolls int    `json:"unchangedRolls"`
+	TimedOutRolls  int    `json:"timedOutRolls"`
+	Reclick        bool   `json:"reclick"` // The attempt is clicked again
 }
 
 type TargetFoundData struct {
-	ModName    string `json:"modName"`
-	Value      int    `json:"value"`
-	AttemptNum int    `json:"attemptNum"`
-	TotalRolls int    `json:"totalRolls"`
+	ModName    string  `json:"modName"`
+	Value      float64 `json:"value"`
+	AttemptNum int     `json:"attemptNum"`
+	TotalRolls int     `json:"totalRolls"`
+}
+
+type RollScoredData struct {
+	AttemptNum int     `json:"attemptNum"`
+	Score      float64 `json:"score"`
+	Threshold  float64 `json:"threshold"`
+	TotalRolls int     `json:"totalRolls"`
+}
+
+type BestRollData struct {
+	AttemptNum int     `json:"attemptNum"`
+	ModName    string  `json:"modName"`
+	Score      float64 `json:"score"`
+	TotalRolls int     `json:"totalRolls"`
+}
+
+type RollMarkedData struct {
+	RollNumber int      `json:"rollNumber"`
+	Mods       []string `json:"mods"`
+}
+
+type AttentionResolvedData struct {
+	ID       int    `json:"id"`
+	Answer   string `json:"answer"`
+	TimedOut bool   `json:"timedOut"`
 }
 
 type ItemStartedData struct {
@@ -51,11 +91,22 @@ type ItemStartedData struct {
 	PendingY   int `json:"pendingY"`
 }
 
+type ItemInspectedData struct {
+	ItemNumber int        `json:"itemNumber"`
+	Item       ItemHeader `json:"item"`
+}
+
+type ItemSkippedData struct {
+	ItemNumber int    `json:"itemNumber"`
+	Reason     string `json:"reason"`
+}
+
 type ItemCompletedData struct {
 	ItemNumber int    `json:"itemNumber"`
 	Success    bool   `json:"success"`
 	ResultX    int    `json:"resultX"`
 	ResultY    int    `json:"resultY"`
+	Error      string `json:"error,omitempty"` // Skip reason, e.g. "item is corrupted"
 }
 
 type CraftCountdownData struct {
@@ -85,32 +136,54 @@ type CaptureResultData struct {
 
 // ReportData is a JSON-serializable version of session report
 type ReportData struct {
-	StartTime     string              `json:"startTime"`
-	EndTime       string              `json:"endTime"`
-	Duration      string              `json:"duration"`
-	TotalRolls    int                 `json:"totalRolls"`
-	RollsPerMin   float64             `json:"rollsPerMin"`
-	TargetMods    []string            `json:"targetMods"`
-	TargetModHit  bool                `json:"targetModHit"`
-	TargetModName string              `json:"targetModName"`
-	TargetValue   int                 `json:"targetValue"`
-	ModStats      []ReportModStat     `json:"modStats"`
-	RoundResults  []ReportRoundResult `json:"roundResults"`
+	StartTime      string              `json:"startTime"`
+	EndTime        string              `json:"endTime"`
+	Duration       string              `json:"duration"`
+	TotalRolls     int                 `json:"totalRolls"`
+	UnchangedRolls int                 `json:"unchangedRolls"`
+	TimedOutRolls  int                 `json:"timedOutRolls"`
+	RollsPerMin    float64             `json:"rollsPerMin"`
+	TargetMods     []string            `json:"targetMods"`
+	TargetModHit   bool                `json:"targetModHit"`
+	TargetModName  string              `json:"targetModName"`
+	TargetValue    float64             `json:"targetValue"`
+	Mode           string              `json:"mode,omitempty"`
+	BestScore      float64             `json:"bestScore,omitempty"` // Maximise mode: best roll of the last item
+	BestModName    string              `json:"bestModName,omitempty"`
+	BestAttempt    int                 `json:"bestAttempt,omitempty"`
+	ScoreFormula   string              `json:"scoreFormula,omitempty"`
+	RollScores     []float64           `json:"rollScores,omitempty"` // Score of every roll, in order
+	CurrencyUsed   map[string]int      `json:"currencyUsed,omitempty"` // Upgrade orbs used on non-rare items
+	MarkedRolls    []int               `json:"markedRolls,omitempty"`  // Roll numbers marked with the mark hotkey
+	ModStats       []ReportModStat     `json:"modStats"`
+	RoundResults   []ReportRoundResult `json:"roundResults"`
 }
 
 type ReportModStat struct {
-	ModName     string  `json:"modName"`
-	Count       int     `json:"count"`
-	MinValue    int     `json:"minValue"`
-	MaxValue    int     `json:"maxValue"`
-	AvgValue    float64 `json:"avgValue"`
-	Probability float64 `json:"probability"` // percentage
+	ModName     string            `json:"modName"`
+	Count       int               `json:"count"`
+	MinValue    float64           `json:"minValue"`
+	MaxValue    float64           `json:"maxValue"`
+	AvgValue    float64           `json:"avgValue"`
+	Probability float64           `json:"probability"` // percentage
+	Values      []ReportValueStat `json:"values,omitempty"`
+}
+
+type ReportValueStat struct {
+	Name         string         `json:"name"`
+	MinValue     float64        `json:"minValue"`
+	MaxValue     float64        `json:"maxValue"`
+	AvgValue     float64        `json:"avgValue"`
+	Distribution map[string]int `json:"distribution"`
 }
 
 type ReportRoundResult struct {
-	RoundNumber   int    `json:"roundNumber"`
-	Success       bool   `json:"success"`
-	TargetHit     bool   `json:"targetHit"`
-	TargetModName string `json:"targetModName"`
-	TargetValue   int    `json:"targetValue"`
+	RoundNumber   int        `json:"roundNumber"`
+	Item          ItemHeader `json:"item"`
+	Success       bool       `json:"success"`
+	TargetHit     bool       `json:"targetHit"`
+	TargetModName string     `json:"targetModName"`
+	TargetValue   float64    `json:"targetValue"`
+	Prefixes      []string   `json:"prefixes"`
+	Suffixes      []string   `json:"suffixes"`
 }
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// Item rarities
+const (
+	RarityNormal = "normal"
+	RarityMagic  = "magic"
+	RarityRare   = "rare"
+	RarityUnique = "unique"
+)
+
+// Tooltip flags of items that chaos orbs must not be used on
+const (
+	FlagCorrupted    = "corrupted"
+	FlagMirrored     = "mirrored"
+	FlagUnmodifiable = "unmodifiable"
+	FlagFractured    = "fractured"
+)
+
+// ItemHeader is what the tooltip header says about an item
+type ItemHeader struct {
+	Name      string   `json:"name"`
+	Base      string   `json:"base"`
+	Class     string   `json:"class"` // Catalog item class, e.g. "ring" (empty = unknown)
+	ItemLevel int      `json:"itemLevel"`
+	Rarity    string   `json:"rarity"`          // RarityNormal ... RarityUnique (empty = unknown)
+	Flags     []string `json:"flags,omitempty"` // FlagCorrupted ... FlagFractured
+
+	rarityLabelled bool // Rarity was read from a "Rarity:" line rather than guessed
+}
+
+// String summarizes the header for logs and reports
+func (h ItemHeader) String() string {
+	name := h.Name
+	if h.Base != "" && h.Base != h.Name {
+		name += " (" + h.Base + ")"
+	}
+	if name == "" {
+		name = "?"
+	}
+	s := fmt.Sprintf("%s, class %s, ilvl %d, %s", name, orUnknown(h.Class), h.ItemLevel, orUnknown(h.Rarity))
+	if len(h.Flags) > 0 {
+		s += ", " + strings.Join(h.Flags, ", ")
+	}
+	return s
+}
+
+// HasFlag reports whether the tooltip showed the given flag
+func (h ItemHeader) HasFlag(flag string) bool {
+	for _, f := range h.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// ParseItemHeader reads name, base type, item class, item level and rarity from OCR text.
+// The header is the run of text lines before the first line with a number; two lines are
+// name and base of a rare or unique item, one line is the base (with affixes if magic).
+func ParseItemHeader(text string, gameLang string) ItemHeader {
+	catalog := modcatalog.Default()
+	labels := catalog.Language(gameLang).Labels
+	ilvlRe := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(labels.ItemLevel) + `\s*[:：]?\s*(\d+)`)
+
+	var h ItemHeader
+	var names []string
+	inHeader := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if value, ok := cutLabel(line, labels.ItemClass); ok {
+			h.Class = catalog.ItemClass(value)
+			if h.Class == "" {
+				h.Class = strings.ToLower(value)
+			}
+			continue
+		}
+		if value, ok := cutLabel(line, labels.Rarity); ok {
+			h.Rarity = rarityFromName(labels, value)
+			h.rarityLabelled = h.Rarity != ""
+			continue
+		}
+		if flag := flagFromLine(labels, line); flag != "" {
+			if !h.HasFlag(flag) {
+				h.Flags = append(h.Flags, flag)
+			}
+			continue
+		}
+		if m := ilvlRe.FindStringSubmatch(line); m != nil {
+			h.ItemLevel, _ = strconv.Atoi(m[1])
+			inHeader = false
+			continue
+		}
+		if !inHeader {
+			continue
+		}
+		if strings.ContainsAny(line, "0123456789") || strings.HasPrefix(line, "-") {
+			inHeader = false
+			continue
+		}
+		if len([]rune(line)) >= 3 {
+			names = append(names, line)
+			inHeader = len(names) < 2
+		}
+	}
+
+	switch len(names) {
+	case 2:
+		h.Name, h.Base = names[0], names[1]
+		if h.Rarity == "" {
+			h.Rarity = RarityRare
+		}
+	case 1:
+		h.Name, h.Base = names[0], names[0]
+	}
+	if h.Class == "" {
+		h.Class = catalog.ItemClass(h.Base)
+	}
+	return h
+}
+
+// cutLabel returns the text after a "Label:" prefix, ignoring case
+func cutLabel(line string, label string) (string, bool) {
+	if label == "" || len(line) < len(label) || !strings.EqualFold(line[:len(label)], label) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimLeft(line[len(label):], " :：")), true
+}
+
+// rarityFromName maps a localized rarity name to RarityNormal ... RarityUnique
+func rarityFromName(labels modcatalog.Labels, name string) string {
+	for localized, rarity := range labels.Rarities {
+		if strings.EqualFold(strings.TrimSpace(name), localized) {
+			return rarity
+		}
+	}
+	return ""
+}
+
+// flagFromLine maps a localized flag line such as "Corrupted" to FlagCorrupted ... FlagFractured
+func flagFromLine(labels modcatalog.Labels, line string) string {
+	for localized, flag := range labels.Flags {
+		if strings.EqualFold(line, localized) {
+			return flag
+		}
+	}
+	return ""
+}
+
+// BlockedReason returns why chaos orbs cannot be used on the item, or "" if they can
+func BlockedReason(h ItemHeader) string {
+	switch {
+	case h.HasFlag(FlagCorrupted):
+		return "item is corrupted"
+	case h.HasFlag(FlagMirrored):
+		return "item is mirrored"
+	case h.HasFlag(FlagUnmodifiable):
+		return "item is unmodifiable"
+	case h.HasFlag(FlagFractured):
+		return "item is fractured"
+	}
+	return ""
+}
+
+// CheckItemConstraints returns why an item does not fit the profile, or "" if it does
+func CheckItemConstraints(cfg *config.Config, h ItemHeader) string {
+	if cfg.ExpectedBase != "" {
+		want := strings.ToLower(cfg.ExpectedBase)
+		if !strings.Contains(strings.ToLower(h.Base), want) && !strings.Contains(strings.ToLower(h.Name), want) {
+			return fmt.Sprintf("base %q is not %q", h.Base, cfg.ExpectedBase)
+		}
+	}
+	if cfg.ExpectedClass != "" && !strings.EqualFold(h.Class, cfg.ExpectedClass) {
+		return fmt.Sprintf("item class %s is not %s", orUnknown(h.Class), cfg.ExpectedClass)
+	}
+	if (cfg.MinItemLevel > 0 || cfg.MaxItemLevel > 0) && h.ItemLevel == 0 {
+		return "could not read the item level"
+	}
+	if cfg.MinItemLevel > 0 && h.ItemLevel < cfg.MinItemLevel {
+		return fmt.Sprintf("item level %d is below %d", h.ItemLevel, cfg.MinItemLevel)
+	}
+	if cfg.MaxItemLevel > 0 && h.ItemLevel > cfg.MaxItemLevel {
+		return fmt.Sprintf("item level %d is above %d", h.ItemLevel, cfg.MaxItemLevel)
+	}
+	return ""
+}
+
+// hasItemConstraints reports whether the profile restricts which items may be crafted
+func hasItemConstraints(cfg *config.Config) bool {
+	return cfg.ExpectedBase != "" || cfg.ExpectedClass != "" || cfg.MinItemLevel > 0 || cfg.MaxItemLevel > 0
+}
+
+// InspectItem hovers the item and reads its tooltip header before any currency is used.
+// Without a "Rarity:" line the rarity comes from the colour of the item name.
+func (e *Engine) InspectItem(ctx context.Context, cfg *config.Config, tempDir string) (ItemHeader, error) {
+	e.Backend.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 250, 50)
+
+	img := e.CaptureTooltip(cfg)
+	text, err := e.RunTesseractOCR(ctx, img, tempDir, cfg.GameLanguage, cfg.Preprocess)
+	if err != nil {
+		return ItemHeader{}, err
+	}
+	h := ParseItemHeader(text, cfg.GameLanguage)
+	if !h.rarityLabelled {
+		if rarity := RarityFromColour(img); rarity != "" {
+			h.Rarity = rarity
+		}
+	}
+	return h, nil
+}
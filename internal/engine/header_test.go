@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+func TestParseItemHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		text string
+		want ItemHeader
+	}{
+		{
+			"en rare", "en",
+			"Item Class: Rings\nRarity: Rare\nDoom Loop\nSapphire Ring\nItem Level: 75\n+30 to maximum Life",
+			ItemHeader{Name: "Doom Loop", Base: "Sapphire Ring", Class: "ring", ItemLevel: 75, Rarity: RarityRare},
+		},
+		{
+			"en rare without labels", "en",
+			"Doom Loop\nSapphire Ring\nItem Level: 75\n+30 to maximum Life",
+			ItemHeader{Name: "Doom Loop", Base: "Sapphire Ring", Class: "ring", ItemLevel: 75, Rarity: RarityRare},
+		},
+		{
+			"en magic", "en",
+			"Rarity: Magic\nSapphire Ring of the Fox\nItem Level: 68\n+12 to Dexterity",
+			ItemHeader{Name: "Sapphire Ring of the Fox", Base: "Sapphire Ring of the Fox", Class: "ring", ItemLevel: 68, Rarity: RarityMagic},
+		},
+		{
+			"en one line without labels", "en",
+			"Lapis Amulet\n+24 to Intelligence",
+			ItemHeader{Name: "Lapis Amulet", Base: "Lapis Amulet", Class: "amulet"},
+		},
+		{
+			"de rare", "de",
+			"Gegenstandsklasse: Ringe\nSeltenheit: Selten\nUntergangsschleife\nSaphirring\nGegenstandsstufe: 75\n+30 zu maximalem Leben",
+			ItemHeader{Name: "Untergangsschleife", Base: "Saphirring", Class: "ring", ItemLevel: 75, Rarity: RarityRare},
+		},
+		{
+			"de magic", "de",
+			"Seltenheit: Magisch\nSaphirring des Fuchses\nGegenstandsstufe: 68",
+			ItemHeader{Name: "Saphirring des Fuchses", Base: "Saphirring des Fuchses", ItemLevel: 68, Rarity: RarityMagic},
+		},
+	}
+	for _, tt := range tests {
+		got := ParseItemHeader(tt.text, tt.lang)
+		got.rarityLabelled = false
+		if got.String() != tt.want.String() {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseItemHeaderLabelledRarity(t *testing.T) {
+	if h := ParseItemHeader("Rarity: Rare\nDoom Loop\nSapphire Ring", "en"); !h.rarityLabelled {
+		t.Error("a Rarity: line was not marked as read from the tooltip")
+	}
+	if h := ParseItemHeader("Doom Loop\nSapphire Ring", "en"); h.rarityLabelled {
+		t.Error("a rarity guessed from two name lines was marked as read from the tooltip")
+	}
+}
+
+func TestCheckItemConstraints(t *testing.T) {
+	h := ItemHeader{Name: "Doom Loop", Base: "Sapphire Ring", Class: "ring", ItemLevel: 75, Rarity: RarityRare}
+	tests := []struct {
+		name string
+		cfg  config.Config
+		h    ItemHeader
+		want string // Part of the reason, "" = the item fits
+	}{
+		{"no constraints", config.Config{}, h, ""},
+		{"base matches", config.Config{ExpectedBase: "sapphire"}, h, ""},
+		{"base differs", config.Config{ExpectedBase: "Ruby Ring"}, h, `is not "Ruby Ring"`},
+		{"class matches", config.Config{ExpectedClass: "Ring"}, h, ""},
+		{"class differs", config.Config{ExpectedClass: "amulet"}, h, "item class ring is not amulet"},
+		{"class unknown", config.Config{ExpectedClass: "ring"}, ItemHeader{Base: "?"}, "item class unknown"},
+		{"level in range", config.Config{MinItemLevel: 75, MaxItemLevel: 80}, h, ""},
+		{"level too low", config.Config{MinItemLevel: 76}, h, "below 76"},
+		{"level too high", config.Config{MaxItemLevel: 74}, h, "above 74"},
+		{"level unread", config.Config{MinItemLevel: 1}, ItemHeader{Base: "Sapphire Ring"}, "could not read the item level"},
+	}
+	for _, tt := range tests {
+		got := CheckItemConstraints(&tt.cfg, tt.h)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: CheckItemConstraints() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlockedReason(t *testing.T) {
+	tests := []struct {
+		lang string
+		line string
+		flag string
+	}{
+		{"en", "Corrupted", FlagCorrupted},
+		{"en", "Mirrored", FlagMirrored},
+		{"en", "Unmodifiable", FlagUnmodifiable},
+		{"en", "Fractured Item", FlagFractured},
+		{"de", "Verderbt", FlagCorrupted},
+		{"de", "Gespiegelt", FlagMirrored},
+		{"de", "Unveränderbar", FlagUnmodifiable},
+		{"de", "Gebrochener Gegenstand", FlagFractured},
+	}
+	for _, tt := range tests {
+		h := ParseItemHeader("Doom Loop\nSapphire Ring\n+30 to maximum Life\n"+tt.line, tt.lang)
+		if len(h.Flags) != 1 || !h.HasFlag(tt.flag) {
+			t.Errorf("%s %q: flags %v, want %s", tt.lang, tt.line, h.Flags, tt.flag)
+		}
+		if reason := BlockedReason(h); !strings.Contains(reason, tt.flag) {
+			t.Errorf("%s %q: BlockedReason() = %q, want it to say %s", tt.lang, tt.line, reason, tt.flag)
+		}
+	}
+
+	if reason := BlockedReason(ParseItemHeader("Doom Loop\nSapphire Ring\nItem Level: 75", "en")); reason != "" {
+		t.Errorf("BlockedReason() of a clean item = %q, want none", reason)
+	}
+	// Flags are only read in the game language
+	if h := ParseItemHeader("Doom Loop\nSapphire Ring\nCorrupted", "de"); len(h.Flags) != 0 {
+		t.Errorf("de header with an en flag line: flags %v, want none", h.Flags)
+	}
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/hotkey"
+)
+
+// StartHotkeys listens for the profile's hotkeys until the returned function is called.
+// Only the local engine reads this machine's keyboard; other engines listen to
+// HotkeyBackend if one is set. Without a backend crafting continues with the web buttons.
+func (e *Engine) StartHotkeys(cfg *config.Config) func() {
+	bindings, err := hotkey.ParseBindings(cfg.Hotkeys)
+	if err != nil {
+		fmt.Printf("⚠ WARNING: Invalid hotkeys (%v), using defaults\n", err)
+		bindings = hotkey.DefaultBindings()
+	}
+	e.hotkeys = bindings
+
+	backend := e.HotkeyBackend
+	if backend == nil && !e.Local {
+		fmt.Printf("⌨  Hotkeys off for engine %s: use the web buttons to pause and stop\n", e.Name)
+		return func() {}
+	}
+	if backend == nil {
+		backend, err = hotkey.NewBackend()
+		if err != nil {
+			fmt.Printf("⚠ WARNING: Hotkeys disabled: %v\n", err)
+			return func() {}
+		}
+	}
+
+	fmt.Printf("⌨  Hotkeys: pause %s, stop %s, skip item %s, mark roll %s\n",
+		bindings[hotkey.ActionPause], bindings[hotkey.ActionStop], bindings[hotkey.ActionSkip], bindings[hotkey.ActionMark])
+	listener := hotkey.NewListener(backend, bindings)
+	listener.Start(e.handleHotkey)
+	return listener.Stop
+}
+
+// handleHotkey runs on the listener goroutine and only changes state the craft loop reads
+func (e *Engine) handleHotkey(action hotkey.Action) {
+	switch action {
+	case hotkey.ActionPause:
+		e.TogglePause()
+	case hotkey.ActionStop:
+		if e.RequestStop() == nil {
+			fmt.Println("\n🛑 Stop hotkey pressed... Exiting safely.")
+		}
+	case hotkey.ActionSkip:
+		fmt.Print("\n⏭  Skipping current item...")
+		e.SkipRequested.Store(true)
+	case hotkey.ActionMark:
+		e.MarkRequested.Store(true)
+	}
+}
+
+// TogglePause pauses a running craft or resumes a paused one and returns the new state.
+// Returns a *TransitionError in any other state.
+func (e *Engine) TogglePause() (State, error) {
+	if err := e.states.TransitionFrom(StatePaused, StateRunning); err == nil {
+		fmt.Printf("\n⏸  PAUSED - Press %s to resume or %s to stop", e.hotkeyName(hotkey.ActionPause), e.hotkeyName(hotkey.ActionStop))
+		return StatePaused, nil
+	}
+	if err := e.states.TransitionFrom(StateRunning, StatePaused); err != nil {
+		return e.State(), err
+	}
+	fmt.Print("\n▶  RESUMED")
+	return StateRunning, nil
+}
+
+// hotkeyName returns the key bound to an action, for console hints
+func (e *Engine) hotkeyName(action hotkey.Action) string {
+	if combo, ok := e.hotkeys[action]; ok {
+		return combo.String()
+	}
+	return hotkey.DefaultBindings()[action].String()
+}
+
+// MarkedRoll is a roll flagged with the mark hotkey
+type MarkedRoll struct {
+	Roll     int      // Session roll number
+	Mods     []string // Mod names recognized on the roll
+	Snapshot string   // Path of the saved tooltip image
+}
+
+// markRoll records the latest roll if the mark hotkey was pressed since the last check
+func (e *Engine) markRoll(session *CraftingSession) {
+	if !e.MarkRequested.Swap(false) {
+		return
+	}
+	if session.TotalRolls == 0 || session.lastImage == nil {
+		fmt.Print("\n⚠ Nothing to mark yet")
+		return
+	}
+	if n := len(session.MarkedRolls); n > 0 && session.MarkedRolls[n-1].Roll == session.TotalRolls {
+		return
+	}
+
+	mark := MarkedRoll{Roll: session.TotalRolls}
+	if session.LastItem != nil {
+		for _, mod := range session.LastItem.Mods {
+			mark.Mods = append(mark.Mods, mod.Name)
+		}
+	}
+	mark.Snapshot = filepath.Join(e.SnapshotsDir, fmt.Sprintf("marked_roll_%d.png", mark.Roll))
+	if err := SaveImage(session.lastImage, mark.Snapshot); err != nil {
+		fmt.Printf("\n⚠ Warning: Could not save marked roll snapshot: %v\n", err)
+		mark.Snapshot = ""
+	}
+	session.MarkedRolls = append(session.MarkedRolls, mark)
+
+	fmt.Printf("\n🔖 Marked roll #%d", mark.Roll)
+	e.Emit("roll_marked", RollMarkedData{RollNumber: mark.Roll, Mods: mark.Mods})
+}
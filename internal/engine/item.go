@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// ParsedItem is every mod recognized on a roll plus its prefix/suffix layout
+type ParsedItem struct {
+	Mods     []ParsedMod `json:"mods"`
+	Prefixes []string    `json:"prefixes"`          // Names of the prefix mods
+	Suffixes []string    `json:"suffixes"`          // Names of the suffix mods
+	Unknown  []string    `json:"unknown,omitempty"` // Affix lines that are neither, e.g. misread or not in the catalog
+}
+
+// ParseItem recognizes the catalog mods in OCR text and classifies them as prefix or
+// suffix. A match inside a longer one (part of a hybrid mod line) is dropped, so the
+// hybrid's values are only counted once, by the hybrid mod. Every other line of the
+// affix block is listed as unknown, so it still takes up a slot.
+func ParseItem(text string, gameLang string) ParsedItem {
+	var item ParsedItem
+	var kept []modcatalog.Match
+	matches := modcatalog.Default().Parse(text, gameLang)
+	for i, m := range matches {
+		if partOfLongerMatch(matches, i) {
+			continue
+		}
+		kept = append(kept, m)
+		mod := ParsedMod{ID: m.Mod.ID, Name: m.Mod.Name, Value: m.Value, Group: m.Mod.Group()}
+		if len(m.Values) > 1 {
+			mod.Values = m.Values
+		}
+		item.Mods = append(item.Mods, mod)
+
+		switch mod.Group {
+		case modcatalog.Prefix:
+			item.Prefixes = append(item.Prefixes, mod.Name)
+		case modcatalog.Suffix:
+			item.Suffixes = append(item.Suffixes, mod.Name)
+		default:
+			item.Unknown = append(item.Unknown, mod.Name)
+		}
+	}
+
+	for _, line := range affixLines(text, gameLang) {
+		if !coveredByMatch(kept, line) {
+			item.Unknown = append(item.Unknown, strings.TrimSpace(text[line[0]:line[1]]))
+		}
+	}
+	return item
+}
+
+// affixLines returns the byte ranges of the non-empty lines of the affix block: the lines
+// after "Item Level", or after the header when OCR missed that line. Header labels and
+// flags such as "Corrupted" are left out.
+func affixLines(text string, gameLang string) [][2]int {
+	labels := modcatalog.Default().Language(gameLang).Labels
+	ilvlRe := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(labels.ItemLevel) + `\s*[:：]?\s*\d+`)
+
+	var lines [][2]int
+	for start := 0; start <= len(text); {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		if strings.TrimSpace(text[start:end]) != "" {
+			lines = append(lines, [2]int{start, end})
+		}
+		start = end + 1
+	}
+
+	// The block starts after the item level, or else at the first line with a number
+	first := -1
+	for i, line := range lines {
+		if ilvlRe.MatchString(text[line[0]:line[1]]) {
+			first = i + 1
+		}
+	}
+	if first < 0 {
+		first = len(lines)
+		for i, line := range lines {
+			s := strings.TrimSpace(text[line[0]:line[1]])
+			if strings.ContainsAny(s, "0123456789") || strings.HasPrefix(s, "-") {
+				first = i
+				break
+			}
+		}
+	}
+
+	var block [][2]int
+	for _, line := range lines[first:] {
+		s := strings.TrimSpace(text[line[0]:line[1]])
+		if _, ok := cutLabel(s, labels.ItemClass); ok {
+			continue
+		}
+		if _, ok := cutLabel(s, labels.Rarity); ok {
+			continue
+		}
+		if flagFromLine(labels, s) != "" {
+			continue
+		}
+		block = append(block, line)
+	}
+	return block
+}
+
+// coveredByMatch reports whether a mod match overlaps the line
+func coveredByMatch(matches []modcatalog.Match, line [2]int) bool {
+	for _, m := range matches {
+		if m.Start < line[1] && m.End > line[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// partOfLongerMatch reports whether match i lies inside a longer match
+func partOfLongerMatch(matches []modcatalog.Match, i int) bool {
+	m := matches[i]
+	for j, other := range matches {
+		if j != i && other.Start <= m.Start && other.End >= m.End && other.End-other.Start > m.End-m.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenPrefixes returns how many prefix slots are surely still free: unknown lines count as taken
+func (it ParsedItem) OpenPrefixes() int {
+	return max(0, modcatalog.MaxPrefixes-len(it.Prefixes)-len(it.Unknown))
+}
+
+// OpenSuffixes returns how many suffix slots are surely still free: unknown lines count as taken
+func (it ParsedItem) OpenSuffixes() int {
+	return max(0, modcatalog.MaxSuffixes-len(it.Suffixes)-len(it.Unknown))
+}
+
+// Satisfies reports whether the item meets every affix condition. An unknown line may be
+// a prefix or a suffix, so a condition must hold for every way of splitting them; an item
+// with more affix lines than slots fails.
+func (it ParsedItem) Satisfies(conds []config.AffixCondition) bool {
+	if len(conds) == 0 {
+		return true
+	}
+	possible := false
+	for p := 0; p <= len(it.Unknown); p++ {
+		prefixes := len(it.Prefixes) + p
+		suffixes := len(it.Suffixes) + len(it.Unknown) - p
+		if prefixes > modcatalog.MaxPrefixes || suffixes > modcatalog.MaxSuffixes {
+			continue
+		}
+		possible = true
+		for _, cond := range conds {
+			if !cond.Accepts(prefixes, suffixes) {
+				return false
+			}
+		}
+	}
+	return possible
+}
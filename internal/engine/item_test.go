@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"testing"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/scoring"
+)
+
+const hybridRoll = "25% increased Armour\n+30 to maximum Life\n+85 to maximum Mana\n+24 to Intelligence"
+
+func TestParseItemDropsHybridParts(t *testing.T) {
+	item := ParseItem(hybridRoll, "en")
+
+	ids := make(map[string]int)
+	for _, mod := range item.Mods {
+		ids[mod.ID]++
+	}
+	if ids["life"] != 0 {
+		t.Errorf("the life part of the hybrid line is listed as its own mod: %+v", item.Mods)
+	}
+	if ids["armour-life"] != 1 || ids["mana"] != 1 || ids["int"] != 1 || len(item.Mods) != 3 {
+		t.Errorf("Mods = %+v, want armour-life, mana and int", item.Mods)
+	}
+	if len(item.Prefixes) != 2 || len(item.Suffixes) != 1 {
+		t.Errorf("prefixes %v, suffixes %v; want 2 and 1", item.Prefixes, item.Suffixes)
+	}
+}
+
+func TestTrackModsCountsHybridOnce(t *testing.T) {
+	session := &CraftingSession{ModStats: make(map[string]*ModStat)}
+	TrackMods(hybridRoll, "en", session, 1)
+
+	if _, ok := session.ModStats["Life"]; ok {
+		t.Error("the hybrid's life was tracked as a Life roll")
+	}
+	if stat := session.ModStats["Armour and Life"]; stat == nil || stat.Count != 1 {
+		t.Errorf("Armour and Life stat = %+v, want one roll", stat)
+	}
+}
+
+func TestRollScoreCountsHybridOnce(t *testing.T) {
+	tests := map[string]float64{
+		"life":             0,
+		"armour_life.life": 30,
+		"mana + int":       109,
+	}
+	for src, want := range tests {
+		f, err := scoring.Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := RollScore(f, hybridRoll, "en"); got != want {
+			t.Errorf("RollScore(%q) = %v, want %v", src, got, want)
+		}
+	}
+}
+
+const rareAmulet = "Item Class: Amulets\nRarity: Rare\nStorm Locket\nLapis Amulet\nItem Level: 81\n"
+
+func TestParseItemCountsEveryAffixLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		unknown int
+	}{
+		{"all known", rareAmulet + "+85 to maximum Life\n+24 to Intelligence\nCorrupted", 0},
+		{"not in the catalog", rareAmulet + "+85 to maximum Life\n+24 to Intelligence\nGain 3 Rage on Hit", 1},
+		{"misread", rareAmulet + "+85 to maximum Life\n+24 to Intelligence\n+3O to Spirlt", 1},
+		{"no item level", "+85 to maximum Life\n+24 to Intelligence\nGain 3 Rage on Hit", 1},
+	}
+	for _, tt := range tests {
+		item := ParseItem(tt.text, "en")
+		if len(item.Prefixes) != 1 || len(item.Suffixes) != 1 || len(item.Unknown) != tt.unknown {
+			t.Errorf("%s: prefixes %v, suffixes %v, unknown %v; want 1, 1 and %d",
+				tt.name, item.Prefixes, item.Suffixes, item.Unknown, tt.unknown)
+		}
+	}
+}
+
+func TestSatisfiesWithUnknownLines(t *testing.T) {
+	known := ParsedItem{Prefixes: []string{"Life"}, Suffixes: []string{"Intelligence", "Strength"}}
+	unknown := known
+	unknown.Unknown = []string{"+3O to Spirlt"}
+	full := ParsedItem{Prefixes: []string{"Life", "Mana", "Spirit"}, Suffixes: []string{"Strength", "Dexterity", "Intelligence"}, Unknown: []string{"?"}}
+
+	tests := []struct {
+		cond  string
+		item  ParsedItem
+		want  bool
+		cause string
+	}{
+		{"open-suffix", known, true, ""},
+		{"open-suffix", unknown, false, "the unknown line may be the third suffix"},
+		{"open-prefix", unknown, true, "a prefix slot is left either way"},
+		{"prefixes<=1", unknown, false, "the unknown line may be a second prefix"},
+		{"suffixes>=2", unknown, true, "two suffixes are known"},
+		{"prefixes>=0", full, false, "more lines than slots"},
+	}
+	for _, tt := range tests {
+		cond, err := config.ParseAffixCondition(tt.cond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.item.Satisfies([]config.AffixCondition{cond}); got != tt.want {
+			t.Errorf("%s on %+v = %v, want %v (%s)", tt.cond, tt.item, got, tt.want, tt.cause)
+		}
+	}
+
+	if unknown.OpenPrefixes() != 1 || unknown.OpenSuffixes() != 0 {
+		t.Errorf("open slots %d/%d, want 1/0", unknown.OpenPrefixes(), unknown.OpenSuffixes())
+	}
+	if !full.Satisfies(nil) {
+		t.Error("an item without conditions must pass")
+	}
+}
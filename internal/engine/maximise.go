@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// BestRollFile is the snapshot of the best roll seen in maximise mode
+const BestRollFile = "best_roll.png"
+
+// BestRoll is the highest-scoring roll of the current item in maximise mode
+type BestRoll struct {
+	Attempt  int
+	Score    float64
+	ModName  string
+	Text     string
+	Snapshot string // Path of the saved tooltip image
+}
+
+// ImprovementChance estimates how likely `remaining` more rolls beat the best of `rolled`
+// rolls so far. With independent rolls the best of all rolled+remaining is equally likely
+// to be any of them, so the chance is remaining/(rolled+remaining).
+func ImprovementChance(rolled, remaining int) float64 {
+	if remaining <= 0 {
+		return 0
+	}
+	return float64(remaining) / float64(rolled+remaining)
+}
+
+// maximiseStopChance returns the configured stop threshold for maximise mode
+func maximiseStopChance(cfg *config.Config) float64 {
+	if cfg.MaximiseStopChance > 0 {
+		return cfg.MaximiseStopChance
+	}
+	return 0.2
+}
+
+// recordBestRoll keeps the snapshot of a new best roll and reports it to the GUI
+func (e *Engine) recordBestRoll(session *CraftingSession, img image.Image, best BestRoll) {
+	best.Snapshot = filepath.Join(e.SnapshotsDir, BestRollFile)
+	if err := SaveImage(img, best.Snapshot); err != nil {
+		fmt.Printf("\n⚠ Warning: Could not save best roll snapshot: %v\n", err)
+		best.Snapshot = ""
+	}
+	session.Best = &best
+
+	fmt.Printf("\n⭐ New best (attempt %d): %s = %s", best.Attempt, best.ModName, modcatalog.FormatValue(best.Score))
+	e.Emit("best_roll", BestRollData{
+		AttemptNum: best.Attempt,
+		ModName:    best.ModName,
+		Score:      best.Score,
+		TotalRolls: session.TotalRolls,
+	})
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package engine
+
+// PlayBeep does nothing: only Windows has a beep API
+func PlayBeep(frequency int, durationMs int) {}
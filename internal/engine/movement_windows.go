@@ -0,0 +1,16 @@
+//go:build windows
+
+package engine
+
+import "syscall"
+
+// Windows API for sound
+var (
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+	procBeep = kernel32.NewProc("Beep")
+)
+
+// PlayBeep plays a beep sound with specified frequency and duration
+func PlayBeep(frequency int, durationMs int) {
+	procBeep.Call(uintptr(frequency), uintptr(durationMs))
+}
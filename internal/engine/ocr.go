@@ -1,75 +1,24 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"image"
-	"image/color"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/modcatalog"
 )
 
-// PreprocessForOCR improves image quality for better OCR accuracy
-func PreprocessForOCR(img image.Image) image.Image {
-	bounds := img.Bounds()
-
-	// Step 1: Convert to grayscale
-	grayImg := image.NewGray(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			grayImg.Set(x, y, img.At(x, y))
-		}
-	}
-
-	// Step 2: More aggressive contrast enhancement and binarization
-	contrastImg := image.NewGray(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			oldColor := grayImg.GrayAt(x, y)
-			brightness := oldColor.Y
-
-			var newBrightness uint8
-			if brightness > 80 {
-				enhanced := float64(brightness) * 1.5
-				if enhanced > 255 {
-					enhanced = 255
-				}
-				newBrightness = uint8(enhanced)
-			} else {
-				enhanced := float64(brightness) * 0.3
-				newBrightness = uint8(enhanced)
-			}
-
-			contrastImg.SetGray(x, y, color.Gray{Y: newBrightness})
-		}
-	}
-
-	// Step 3: Scale up 3x for better OCR
-	scaledWidth := bounds.Dx() * 3
-	scaledHeight := bounds.Dy() * 3
-	scaledImg := image.NewGray(image.Rect(0, 0, scaledWidth, scaledHeight))
-
-	for y := 0; y < scaledHeight; y++ {
-		for x := 0; x < scaledWidth; x++ {
-			srcX := bounds.Min.X + x/3
-			srcY := bounds.Min.Y + y/3
-			scaledImg.Set(x, y, contrastImg.At(srcX, srcY))
-		}
-	}
-
-	return scaledImg
-}
-
-// RunTesseractOCRSingle runs OCR with specific settings
-func RunTesseractOCRSingle(img image.Image, tempDir string, suffix string, psm int, usePreprocess bool, gameLang string) (string, error) {
+// RunTesseractOCRSingle runs OCR with specific settings. Cancelling ctx kills tesseract.
+func RunTesseractOCRSingle(ctx context.Context, img image.Image, tempDir string, suffix string, psm int, usePreprocess bool, gameLang string, pre config.PreprocessConfig) (string, error) {
 	var processedImg image.Image
 	if usePreprocess {
-		processedImg = PreprocessForOCR(img)
+		processedImg = PreprocessForOCR(img, pre)
 	} else {
 		processedImg = img
 	}
@@ -87,21 +36,18 @@ func RunTesseractOCRSingle(img image.Image, tempDir string, suffix string, psm i
 	defer os.Remove(tempOutTxt)
 
 	// Select language and whitelist based on game language
-	tessLang := "eng"
-	var tessArgs []string
-	if gameLang == "zh-CN" {
-		tessLang = "chi_sim"
-		tessArgs = []string{tempImg, tempOut, "-l", tessLang,
-			"--psm", fmt.Sprintf("%d", psm),
-			"--oem", "1"}
-	} else {
-		tessArgs = []string{tempImg, tempOut, "-l", tessLang,
-			"--psm", fmt.Sprintf("%d", psm),
-			"--oem", "1",
-			"-c", "tessedit_char_whitelist=ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789 +-()%#"}
-	}
-	cmd := exec.Command("tesseract", tessArgs...)
+	lang := modcatalog.Default().Language(gameLang)
+	tessArgs := []string{tempImg, tempOut, "-l", lang.Tesseract,
+		"--psm", fmt.Sprintf("%d", psm),
+		"--oem", "1"}
+	if lang.Whitelist != "" {
+		tessArgs = append(tessArgs, "-c", "tessedit_char_whitelist="+lang.Whitelist)
+	}
+	cmd := exec.CommandContext(ctx, "tesseract", tessArgs...)
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 		return "", fmt.Errorf("tesseract failed: %w", err)
 	}
 
@@ -114,16 +60,47 @@ func RunTesseractOCRSingle(img image.Image, tempDir string, suffix string, psm i
 	return string(data), nil
 }
 
-// RunTesseractOCR runs OCR with multiple strategies and returns the best result
-func (e *Engine) RunTesseractOCR(img image.Image, tempDir string, gameLang string) (string, error) {
+// RunTesseractOCR returns the tooltip text, reusing the cached result when an identical
+// tooltip (same pixels, language and preprocessing) was already read
+func (e *Engine) RunTesseractOCR(ctx context.Context, img image.Image, tempDir string, gameLang string, pre config.PreprocessConfig) (string, error) {
+	if e.ocrCache == nil {
+		return e.runTesseractStrategies(ctx, img, tempDir, gameLang, pre)
+	}
+
+	key := ocrCacheKey(img, gameLang, pre)
+	if text, ok := e.ocrCache.get(key); ok {
+		e.SnapshotCounter.Add(1)
+		return text, nil
+	}
+
+	text, err := e.runTesseractStrategies(ctx, img, tempDir, gameLang, pre)
+	if err == nil && len(strings.TrimSpace(text)) >= 10 {
+		e.ocrCache.put(key, text)
+	}
+	return text, err
+}
+
+// ocrCacheKey identifies a tooltip image and the settings it is read with
+func ocrCacheKey(img image.Image, gameLang string, pre config.PreprocessConfig) string {
+	return fmt.Sprintf("%s|%s|%v", ContentHash(img), gameLang, pre)
+}
+
+// runTesseractStrategies runs OCR with multiple strategies and returns the best result
+func (e *Engine) runTesseractStrategies(ctx context.Context, img image.Image, tempDir string, gameLang string, pre config.PreprocessConfig) (string, error) {
 	seqNum := e.SnapshotCounter.Add(1)
 
-	// Save original and preprocessed snapshots
+	// Save original, intermediate and preprocessed snapshots
 	if e.DebugMode {
-		debugOriginalFile := filepath.Join(config.SnapshotsDir, fmt.Sprintf("snap_%d_raw.png", seqNum))
-		debugProcessedFile := filepath.Join(config.SnapshotsDir, fmt.Sprintf("snap_%d_processed.png", seqNum))
+		debugOriginalFile := filepath.Join(e.SnapshotsDir, fmt.Sprintf("snap_%d_raw.png", seqNum))
 		SaveImage(img, debugOriginalFile)
-		SaveImage(PreprocessForOCR(img), debugProcessedFile)
+
+		stages := RunPreprocessPipeline(img, pre)
+		for i, stage := range stages {
+			stageFile := filepath.Join(e.SnapshotsDir, fmt.Sprintf("snap_%d_stage%d_%s.png", seqNum, i+1, stage.Name))
+			SaveImage(stage.Image, stageFile)
+		}
+		debugProcessedFile := filepath.Join(e.SnapshotsDir, fmt.Sprintf("snap_%d_processed.png", seqNum))
+		SaveImage(stages[len(stages)-1].Image, debugProcessedFile)
 	}
 
 	type ocrStrategy struct {
@@ -142,7 +119,10 @@ func (e *Engine) RunTesseractOCR(img image.Image, tempDir string, gameLang strin
 	bestScore := 0
 
 	for _, strategy := range fastStrategies {
-		text, err := RunTesseractOCRSingle(img, tempDir, strategy.name, strategy.psm, strategy.usePreprocess, gameLang)
+		text, err := RunTesseractOCRSingle(ctx, img, tempDir, strategy.name, strategy.psm, strategy.usePreprocess, gameLang, pre)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 		if err != nil {
 			continue
 		}
@@ -176,7 +156,10 @@ func (e *Engine) RunTesseractOCR(img image.Image, tempDir string, gameLang strin
 	}
 
 	for _, strategy := range slowStrategies {
-		text, err := RunTesseractOCRSingle(img, tempDir, strategy.name, strategy.psm, strategy.usePreprocess, gameLang)
+		text, err := RunTesseractOCRSingle(ctx, img, tempDir, strategy.name, strategy.psm, strategy.usePreprocess, gameLang, pre)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 		if err != nil {
 			continue
 		}
@@ -206,8 +189,9 @@ func (e *Engine) RunTesseractOCR(img image.Image, tempDir string, gameLang strin
 }
 
 // CheckMod checks if a specific mod appears in the OCR text
-func CheckMod(text string, mod config.ModRequirement) (bool, int) {
-	re := regexp.MustCompile(mod.Pattern)
+func CheckMod(text string, mod config.ModRequirement) (bool, float64) {
+	re := regexp.MustCompile(modcatalog.ExpandPattern(mod.Pattern))
+	names := modcatalog.ValueNames(re)
 	matches := re.FindAllStringSubmatch(text, -1)
 
 	if len(matches) == 0 {
@@ -219,17 +203,13 @@ func CheckMod(text string, mod config.ModRequirement) (bool, int) {
 	}
 
 	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
-
-		numStr := strings.TrimSpace(match[1])
-		value, err := strconv.Atoi(numStr)
-		if err != nil {
+		values, ok := modcatalog.ExtractValues(names, match)
+		if !ok {
 			continue
 		}
 
-		if value >= mod.MinValue {
+		value := modcatalog.Derive(mod.Derive, names, values)
+		if mod.Accepts(value) && meetsValueMins(values, mod.ValueMins) {
 			return true, value
 		}
 	}
@@ -237,8 +217,18 @@ func CheckMod(text string, mod config.ModRequirement) (bool, int) {
 	return false, 0
 }
 
+// meetsValueMins checks the per-value minimums of a multi-value mod
+func meetsValueMins(values map[string]float64, mins map[string]float64) bool {
+	for name, want := range mins {
+		if v, ok := values[name]; !ok || v < want {
+			return false
+		}
+	}
+	return true
+}
+
 // CheckAnyMod checks if any of the target mods appear in the text
-func CheckAnyMod(text string, mods []config.ModRequirement) (bool, config.ModRequirement, int) {
+func CheckAnyMod(text string, mods []config.ModRequirement) (bool, config.ModRequirement, float64) {
 	for _, mod := range mods {
 		matched, value := CheckMod(text, mod)
 		if matched {
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// Hash grid size. Tooltip rolls often differ by a single text line, so the hash uses a
+// finer grid than the classic 8x8 dHash to keep such changes visible.
+const (
+	hashWidth  = 32
+	hashHeight = 32
+)
+
+// ImageHash is a perceptual difference hash (one bit per horizontal gradient sign)
+type ImageHash []uint64
+
+// PerceptualHash computes a difference hash of the image. Small capture noise barely
+// changes it, while different mod text flips many bits.
+func PerceptualHash(img image.Image) ImageHash {
+	small := image.NewGray(image.Rect(0, 0, hashWidth+1, hashHeight))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	hash := make(ImageHash, hashWidth*hashHeight/64)
+	bit := 0
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth; x++ {
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				hash[bit/64] |= 1 << uint(bit%64)
+			}
+			bit++
+		}
+	}
+	return hash
+}
+
+// Distance returns the number of differing bits between two hashes.
+// Hashes of different sizes are treated as completely different.
+func (h ImageHash) Distance(other ImageHash) int {
+	if len(h) != len(other) {
+		return hashWidth * hashHeight
+	}
+	dist := 0
+	for i := range h {
+		dist += bits.OnesCount64(h[i] ^ other[i])
+	}
+	return dist
+}
+
+// String returns the hash as hex
+func (h ImageHash) String() string {
+	var sb strings.Builder
+	for _, word := range h {
+		fmt.Fprintf(&sb, "%016x", word)
+	}
+	return sb.String()
+}
+
+// ContentHash returns a hash of the exact pixels. Unlike PerceptualHash it changes with a
+// single digit of mod text, so it is safe as the OCR cache key.
+func ContentHash(img image.Image) string {
+	h := sha256.New()
+	b := img.Bounds()
+	binary.Write(h, binary.LittleEndian, [4]int32{int32(b.Min.X), int32(b.Min.Y), int32(b.Max.X), int32(b.Max.Y)})
+	px := make([]byte, 8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			binary.LittleEndian.PutUint16(px[0:], uint16(r))
+			binary.LittleEndian.PutUint16(px[2:], uint16(g))
+			binary.LittleEndian.PutUint16(px[4:], uint16(bl))
+			binary.LittleEndian.PutUint16(px[6:], uint16(a))
+			h.Write(px)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ocrCache remembers OCR text for recently seen tooltips
+type ocrCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+	order   []string
+	limit   int
+}
+
+func newOCRCache(limit int) *ocrCache {
+	return &ocrCache{entries: make(map[string]string), limit: limit}
+}
+
+func (c *ocrCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	text, ok := c.entries[key]
+	return text, ok
+}
+
+func (c *ocrCache) put(key, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	if len(c.order) >= c.limit {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = text
+	c.order = append(c.order, key)
+}
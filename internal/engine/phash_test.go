@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// nearIdenticalTooltips returns two tooltips that differ by a few pixels, like +81 and +89 Life
+func nearIdenticalTooltips() (*image.RGBA, *image.RGBA) {
+	a := image.NewRGBA(image.Rect(0, 0, 400, 160))
+	for y := 20; y < 140; y += 30 {
+		for x := 20; x < 380; x++ {
+			if x%7 < 4 {
+				for dy := 0; dy < 12; dy++ {
+					a.Set(x, y+dy, color.RGBA{136, 136, 255, 255})
+				}
+			}
+		}
+	}
+	b := image.NewRGBA(a.Bounds())
+	copy(b.Pix, a.Pix)
+	for dy := 4; dy < 8; dy++ {
+		b.Set(32, 20+dy, color.RGBA{136, 136, 255, 255})
+		b.Set(33, 20+dy, color.RGBA{136, 136, 255, 255})
+	}
+	return a, b
+}
+
+func TestContentHashTellsNearIdenticalTooltipsApart(t *testing.T) {
+	a, b := nearIdenticalTooltips()
+	if d := PerceptualHash(a).Distance(PerceptualHash(b)); d > 6 {
+		t.Fatalf("perceptual distance %d; the test needs tooltips that look unchanged", d)
+	}
+	if ContentHash(a) == ContentHash(b) {
+		t.Error("ContentHash is the same for different pixels")
+	}
+	same := image.NewNRGBA(a.Bounds())
+	draw.Draw(same, same.Bounds(), a, image.Point{}, draw.Src)
+	if ContentHash(a) != ContentHash(same) {
+		t.Error("ContentHash differs for the same pixels")
+	}
+}
+
+func TestOCRCacheIgnoresNearIdenticalTooltips(t *testing.T) {
+	a, b := nearIdenticalTooltips()
+	e := NewEngine(false)
+	var pre config.PreprocessConfig
+	e.ocrCache.put(ocrCacheKey(a, "en", pre), "+81 to maximum Life")
+
+	if text, err := e.RunTesseractOCR(context.Background(), a, t.TempDir(), "en", pre); err != nil || text != "+81 to maximum Life" {
+		t.Errorf("same tooltip = %q, %v; want the cached text", text, err)
+	}
+	if text, _ := e.RunTesseractOCR(context.Background(), b, t.TempDir(), "en", pre); text == "+81 to maximum Life" {
+		t.Error("a tooltip with different pixels got the cached text")
+	}
+}
@@ -0,0 +1,349 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+
+	"golang.org/x/image/draw"
+)
+
+// TextColorClasses maps PoE2 tooltip text classes to their reference colours
+var TextColorClasses = map[string]color.RGBA{
+	"white":  {255, 255, 255, 255}, // Values and default text
+	"magic":  {136, 136, 255, 255}, // Explicit mod lines, magic item names
+	"rare":   {255, 255, 119, 255}, // Rare item names
+	"grey":   {127, 127, 127, 255}, // Property labels, value ranges
+	"unique": {175, 96, 37, 255},   // Unique item names
+}
+
+// PreprocessStage is one intermediate image of the preprocessing pipeline
+type PreprocessStage struct {
+	Name  string
+	Image image.Image
+}
+
+// withPreprocessDefaults fills in zero-valued pipeline options
+func withPreprocessDefaults(opts config.PreprocessConfig) config.PreprocessConfig {
+	if opts.MaskTolerance <= 0 {
+		opts.MaskTolerance = 70
+	}
+	if opts.Scale <= 0 {
+		opts.Scale = 3
+	}
+	if opts.Resample == "" {
+		opts.Resample = "catmullrom"
+	}
+	if opts.Threshold == "" {
+		opts.Threshold = "otsu"
+	}
+	if opts.FixedLevel <= 0 {
+		opts.FixedLevel = 80
+	}
+	if opts.SauvolaWindow <= 0 {
+		opts.SauvolaWindow = 25
+	}
+	if opts.SauvolaK <= 0 {
+		opts.SauvolaK = 0.2
+	}
+	if opts.MaxDeskewAngle <= 0 {
+		opts.MaxDeskewAngle = 3
+	}
+	return opts
+}
+
+// PreprocessForOCR improves image quality for better OCR accuracy
+func PreprocessForOCR(img image.Image, opts config.PreprocessConfig) image.Image {
+	stages := RunPreprocessPipeline(img, opts)
+	return stages[len(stages)-1].Image
+}
+
+// RunPreprocessPipeline runs every configured stage and returns all intermediate images.
+// The last stage is the image handed to Tesseract (dark text on a light background).
+func RunPreprocessPipeline(img image.Image, opts config.PreprocessConfig) []PreprocessStage {
+	opts = withPreprocessDefaults(opts)
+	var stages []PreprocessStage
+
+	// Step 1: Keep only pixels of the selected text colours (or plain luminance)
+	masked := ApplyColorMasks(img, opts.ColorMasks, opts.MaskTolerance)
+	maskName := "gray"
+	if len(opts.ColorMasks) > 0 {
+		maskName = "mask_" + strings.Join(opts.ColorMasks, "+")
+	}
+	stages = append(stages, PreprocessStage{maskName, masked})
+
+	// Step 2: Upscale with a smoothing filter so glyph edges survive thresholding
+	scaled := ResampleGray(masked, opts.Scale, opts.Resample)
+	stages = append(stages, PreprocessStage{"resample_" + opts.Resample, scaled})
+
+	// Step 3: Binarize (text becomes black, background white)
+	var binary *image.Gray
+	switch opts.Threshold {
+	case "fixed":
+		binary = ThresholdFixed(scaled, uint8(opts.FixedLevel))
+	case "sauvola":
+		binary = ThresholdSauvola(scaled, opts.SauvolaWindow, opts.SauvolaK)
+	case "none":
+		binary = invertGray(scaled)
+	default:
+		binary = ThresholdFixed(scaled, OtsuLevel(scaled))
+	}
+	stages = append(stages, PreprocessStage{"threshold_" + opts.Threshold, binary})
+
+	// Step 4: Optional deskew
+	if opts.Deskew {
+		angle := EstimateSkew(binary, opts.MaxDeskewAngle)
+		if angle != 0 {
+			stages = append(stages, PreprocessStage{"deskew", RotateGray(binary, -angle)})
+		}
+	}
+
+	return stages
+}
+
+// ApplyColorMasks converts the image to grayscale, keeping only pixels close to one of the
+// given text colour classes. With no classes every pixel keeps its luminance.
+func ApplyColorMasks(img image.Image, classes []string, tolerance int) *image.Gray {
+	bounds := img.Bounds()
+	out := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	var refs []color.RGBA
+	for _, name := range classes {
+		if ref, ok := TextColorClasses[strings.ToLower(name)]; ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			r8, g8, b8 := float64(r>>8), float64(g>>8), float64(b>>8)
+			lum := 0.299*r8 + 0.587*g8 + 0.114*b8
+
+			if len(refs) > 0 && !matchesTextColor(r8, g8, b8, refs, float64(tolerance)) {
+				continue
+			}
+			out.SetGray(x-bounds.Min.X, y-bounds.Min.Y, color.Gray{Y: uint8(lum)})
+		}
+	}
+	return out
+}
+
+// matchesTextColor compares a pixel against reference colours after normalizing its
+// brightness, so anti-aliased glyph edges still match their class.
+func matchesTextColor(r, g, b float64, refs []color.RGBA, tolerance float64) bool {
+	peak := math.Max(r, math.Max(g, b))
+	if peak < 40 {
+		return false
+	}
+	for _, ref := range refs {
+		refPeak := float64(max(ref.R, ref.G, ref.B))
+		scale := refPeak / peak
+		dr := r*scale - float64(ref.R)
+		dg := g*scale - float64(ref.G)
+		db := b*scale - float64(ref.B)
+		if math.Sqrt(dr*dr+dg*dg+db*db) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// ResampleGray scales a grayscale image by an integer factor using the named filter
+func ResampleGray(src *image.Gray, factor int, filter string) *image.Gray {
+	bounds := src.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, bounds.Dx()*factor, bounds.Dy()*factor))
+
+	var scaler draw.Scaler
+	switch filter {
+	case "nearest":
+		scaler = draw.NearestNeighbor
+	case "bilinear":
+		scaler = draw.BiLinear
+	default:
+		scaler = draw.CatmullRom
+	}
+	scaler.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// OtsuLevel computes the global threshold that best separates text from background
+func OtsuLevel(img *image.Gray) uint8 {
+	var hist [256]int
+	for _, v := range img.Pix {
+		hist[v]++
+	}
+	total := len(img.Pix)
+	if total == 0 {
+		return 128
+	}
+
+	sumAll := 0.0
+	for i, n := range hist {
+		sumAll += float64(i * n)
+	}
+
+	sumBg, weightBg := 0.0, 0
+	bestVar, bestLevel := 0.0, 0
+	for t := 0; t < 256; t++ {
+		weightBg += hist[t]
+		if weightBg == 0 {
+			continue
+		}
+		weightFg := total - weightBg
+		if weightFg == 0 {
+			break
+		}
+		sumBg += float64(t * hist[t])
+		meanBg := sumBg / float64(weightBg)
+		meanFg := (sumAll - sumBg) / float64(weightFg)
+		between := float64(weightBg) * float64(weightFg) * (meanBg - meanFg) * (meanBg - meanFg)
+		if between > bestVar {
+			bestVar = between
+			bestLevel = t
+		}
+	}
+	return uint8(bestLevel)
+}
+
+// ThresholdFixed marks pixels brighter than level as text (black) and the rest as background (white)
+func ThresholdFixed(img *image.Gray, level uint8) *image.Gray {
+	out := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		if v > level {
+			out.Pix[i] = 0
+		} else {
+			out.Pix[i] = 255
+		}
+	}
+	return out
+}
+
+// ThresholdSauvola applies Sauvola local thresholding, which copes with the uneven
+// background gradients behind tooltip text better than a single global level.
+func ThresholdSauvola(img *image.Gray, window int, k float64) *image.Gray {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	out := image.NewGray(bounds)
+
+	// Integral images of the inverted image (Sauvola expects dark text on light background)
+	stride := w + 1
+	sum := make([]float64, stride*(h+1))
+	sqSum := make([]float64, stride*(h+1))
+	for y := 0; y < h; y++ {
+		rowSum, rowSq := 0.0, 0.0
+		for x := 0; x < w; x++ {
+			v := float64(255 - img.Pix[y*img.Stride+x])
+			rowSum += v
+			rowSq += v * v
+			sum[(y+1)*stride+x+1] = sum[y*stride+x+1] + rowSum
+			sqSum[(y+1)*stride+x+1] = sqSum[y*stride+x+1] + rowSq
+		}
+	}
+
+	half := window / 2
+	const dynamicRange = 128.0
+	for y := 0; y < h; y++ {
+		y0, y1 := max(0, y-half), min(h, y+half+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(0, x-half), min(w, x+half+1)
+			n := float64((x1 - x0) * (y1 - y0))
+			s := sum[y1*stride+x1] - sum[y0*stride+x1] - sum[y1*stride+x0] + sum[y0*stride+x0]
+			sq := sqSum[y1*stride+x1] - sqSum[y0*stride+x1] - sqSum[y1*stride+x0] + sqSum[y0*stride+x0]
+			mean := s / n
+			stddev := math.Sqrt(math.Max(0, sq/n-mean*mean))
+			level := mean * (1 + k*(stddev/dynamicRange-1))
+
+			v := float64(255 - img.Pix[y*img.Stride+x])
+			if v <= level {
+				out.Pix[y*out.Stride+x] = 0
+			} else {
+				out.Pix[y*out.Stride+x] = 255
+			}
+		}
+	}
+	return out
+}
+
+// invertGray turns bright-on-dark text into dark-on-light without binarizing
+func invertGray(img *image.Gray) *image.Gray {
+	out := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		out.Pix[i] = 255 - v
+	}
+	return out
+}
+
+// EstimateSkew finds the rotation (in degrees) that makes text rows most horizontal,
+// using the variance of the horizontal projection profile of black pixels.
+func EstimateSkew(img *image.Gray, maxAngle float64) float64 {
+	bounds := img.Bounds()
+	var xs, ys []float64
+	for y := 0; y < bounds.Dy(); y += 2 {
+		for x := 0; x < bounds.Dx(); x += 2 {
+			if img.Pix[y*img.Stride+x] == 0 {
+				xs = append(xs, float64(x))
+				ys = append(ys, float64(y))
+			}
+		}
+	}
+	if len(xs) < 100 {
+		return 0
+	}
+
+	bestAngle, bestScore := 0.0, -1.0
+	rows := make([]int, bounds.Dy()*2)
+	for angle := -maxAngle; angle <= maxAngle+1e-9; angle += 0.25 {
+		rad := angle * math.Pi / 180
+		sin, cos := math.Sin(rad), math.Cos(rad)
+		for i := range rows {
+			rows[i] = 0
+		}
+		for i := range xs {
+			r := int(ys[i]*cos-xs[i]*sin) + bounds.Dy()/2
+			if r >= 0 && r < len(rows) {
+				rows[r]++
+			}
+		}
+		score := 0.0
+		for _, n := range rows {
+			score += float64(n * n)
+		}
+		if score > bestScore {
+			bestScore = score
+			bestAngle = angle
+		}
+	}
+
+	if math.Abs(bestAngle) < 0.25 {
+		return 0
+	}
+	return bestAngle
+}
+
+// RotateGray rotates the image around its centre, filling uncovered areas with white
+func RotateGray(img *image.Gray, degrees float64) *image.Gray {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	out := image.NewGray(bounds)
+	rad := degrees * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+	cx, cy := float64(w)/2, float64(h)/2
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			srcX := int(math.Round(dx*cos + dy*sin + cx))
+			srcY := int(math.Round(-dx*sin + dy*cos + cy))
+			if srcX >= 0 && srcX < w && srcY >= 0 && srcY < h {
+				out.Pix[y*out.Stride+x] = img.Pix[srcY*img.Stride+srcX]
+			} else {
+				out.Pix[y*out.Stride+x] = 255
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// rarityNameColors are the item name colours of each rarity in the tooltip header
+var rarityNameColors = map[string]string{
+	RarityMagic:  "magic",
+	RarityRare:   "rare",
+	RarityUnique: "unique",
+	RarityNormal: "white",
+}
+
+// RarityFromColour guesses the rarity from the colour of the item name at the top of the
+// tooltip. It returns "" when no name colour clearly dominates.
+func RarityFromColour(img image.Image) string {
+	bounds := img.Bounds()
+	band := min(bounds.Dy()/5, 80)
+	if band <= 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for y := bounds.Min.Y; y < bounds.Min.Y+band; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			r8, g8, b8 := float64(r>>8), float64(g>>8), float64(b>>8)
+			for rarity, class := range rarityNameColors {
+				if matchesTextColor(r8, g8, b8, []color.RGBA{TextColorClasses[class]}, 40) {
+					counts[rarity]++
+				}
+			}
+		}
+	}
+
+	// Coloured names win over white, which also matches grey and anti-aliased edges
+	best, bestCount := "", 0
+	for _, rarity := range []string{RarityRare, RarityMagic, RarityUnique} {
+		if counts[rarity] > bestCount {
+			best, bestCount = rarity, counts[rarity]
+		}
+	}
+	if bestCount >= 30 {
+		return best
+	}
+	if counts[RarityNormal] >= 30 {
+		return RarityNormal
+	}
+	return ""
+}
+
+// makeRare handles an item that is not rare: it is skipped, or upgraded with the configured
+// path and inspected again. It returns why the item cannot be crafted, or "" once it is rare.
+func (e *Engine) makeRare(ctx context.Context, cfg *config.Config, session *CraftingSession, header *ItemHeader, tempDir string) string {
+	if header.Rarity == RarityUnique {
+		return "item is unique"
+	}
+	if cfg.NonRareAction != config.NonRareUpgrade {
+		return fmt.Sprintf("item is %s, not rare", header.Rarity)
+	}
+
+	path := cfg.UpgradePath(header.Rarity)
+	if err := config.ValidateUpgradePath(header.Rarity, path); err != nil {
+		return err.Error()
+	}
+	for _, id := range path {
+		pos, ok := cfg.CurrencyPos[id]
+		if !ok || pos == (image.Point{}) {
+			return fmt.Sprintf("no position set for %s", id)
+		}
+		if ctx.Err() != nil {
+			return "stopped by user"
+		}
+		currency, _ := config.LookupCurrency(id)
+		fmt.Printf("  → Applying %s...\n", currency.Name)
+		e.ApplyCurrency(ctx, cfg, pos)
+		if session.CurrencyUsed == nil {
+			session.CurrencyUsed = make(map[string]int)
+		}
+		session.CurrencyUsed[id]++
+	}
+
+	upgraded, err := e.InspectItem(ctx, cfg, tempDir)
+	if err != nil {
+		return "could not read the item after upgrading"
+	}
+	*header = upgraded
+	fmt.Printf("  🔎 %s\n", upgraded)
+	if upgraded.Rarity != "" && upgraded.Rarity != RarityRare {
+		return fmt.Sprintf("item is still %s after upgrading", upgraded.Rarity)
+	}
+	return ""
+}
+
+// ApplyCurrency picks up the orb at pos and uses it once on the item
+func (e *Engine) ApplyCurrency(ctx context.Context, cfg *config.Config, pos image.Point) {
+	e.Backend.MoveSmooth(pos.X, pos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 20, 10)
+	e.Backend.Click("right")
+	HumanDelay(ctx, 50, 10)
+
+	e.Backend.MoveSmooth(cfg.ItemPos.X, cfg.ItemPos.Y, 0.1, 0.1)
+	HumanDelay(ctx, 30, 10)
+	e.Backend.Click("left")
+	HumanDelay(ctx, int(cfg.Delay.Milliseconds()), 20)
+}
+
+// formatCurrencyUsed lists upgrade orbs as "1 alchemy, 2 regal"
+func formatCurrencyUsed(used map[string]int) string {
+	ids := make([]string, 0, len(used))
+	for id := range used {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%d %s", used[id], id)
+	}
+	return strings.Join(parts, ", ")
+}
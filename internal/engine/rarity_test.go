@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// tooltipWithName returns a dark tooltip with a block of the given colour where the name is
+func tooltipWithName(c color.Color, nameRect image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 400))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{12, 10, 8, 255}), image.Point{}, draw.Src)
+	draw.Draw(img, nameRect, image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestRarityFromColour(t *testing.T) {
+	name := image.Rect(60, 20, 240, 40)
+	tests := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{"normal", tooltipWithName(TextColorClasses["white"], name), RarityNormal},
+		{"magic", tooltipWithName(TextColorClasses["magic"], name), RarityMagic},
+		{"rare", tooltipWithName(TextColorClasses["rare"], name), RarityRare},
+		{"unique", tooltipWithName(TextColorClasses["unique"], name), RarityUnique},
+		{"no name", tooltipWithName(color.RGBA{12, 10, 8, 255}, name), ""},
+		{"a few pixels", tooltipWithName(TextColorClasses["rare"], image.Rect(60, 20, 65, 25)), ""},
+		{"other colour", tooltipWithName(color.RGBA{40, 160, 40, 255}, name), ""},
+		{"below the header", tooltipWithName(TextColorClasses["rare"], image.Rect(60, 200, 240, 220)), ""},
+	}
+	for _, tt := range tests {
+		if got := RarityFromColour(tt.img); got != tt.want {
+			t.Errorf("%s: RarityFromColour() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRarityFromColourPrefersColouredName(t *testing.T) {
+	// A rare name next to a larger patch of white, such as an item class line
+	img := tooltipWithName(TextColorClasses["white"], image.Rect(0, 0, 300, 15))
+	draw.Draw(img, image.Rect(60, 20, 160, 40), image.NewUniform(TextColorClasses["rare"]), image.Point{}, draw.Src)
+	if got := RarityFromColour(img); got != RarityRare {
+		t.Errorf("RarityFromColour() = %q, want rare", got)
+	}
+}
@@ -1,119 +1,263 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
 	"image"
+	"math"
 	"os"
-	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/modcatalog"
+	"poe2-chaos-crafter/internal/scoring"
 )
 
 // ModStat tracks statistics for a specific mod
 type ModStat struct {
 	ModName    string
 	Count      int
-	MinValue   int
-	MaxValue   int
+	MinValue   float64
+	MaxValue   float64
 	AvgValue   float64
-	TotalValue int
+	TotalValue float64
+	Values     map[string]*ValueStat `json:",omitempty"` // Per-value stats of multi-value mods
+}
+
+// ValueStat tracks one named value of a multi-value mod
+type ValueStat struct {
+	Min          float64
+	Max          float64
+	Avg          float64
+	Total        float64
+	Count        int
+	Distribution map[string]int // formatted value -> times rolled
+}
+
+// add records one rolled value
+func (v *ValueStat) add(value float64) {
+	if v.Count == 0 || value < v.Min {
+		v.Min = value
+	}
+	if v.Count == 0 || value > v.Max {
+		v.Max = value
+	}
+	v.Count++
+	v.Total += value
+	v.Avg = v.Total / float64(v.Count)
+	v.Distribution[modcatalog.FormatValue(value)]++
 }
 
 // RoundResult tracks data for a single round/item
 type RoundResult struct {
 	RoundNumber   int
+	Item          ItemHeader // Tooltip header read before crafting
 	Success       bool
 	StartPos      image.Point
 	EndPos        image.Point
 	ModsFound     []string
 	TargetHit     bool
 	TargetModName string
-	TargetValue   int
+	TargetValue   float64
+	Prefixes      []string // Final prefix layout of the item
+	Suffixes      []string // Final suffix layout of the item
 	ErrorMessage  string
 }
 
 // CraftingSession tracks all data during a crafting session
 type CraftingSession struct {
-	StartTime     time.Time
-	EndTime       time.Time
-	TotalRolls    int
-	ModStats      map[string]*ModStat // Key: mod name
-	TargetModHit  bool
-	TargetModName string // Which target mod was found
-	TargetValue   int
-	RoundResults  []RoundResult // Track each individual round
+	StartTime      time.Time
+	EndTime        time.Time
+	TotalRolls     int
+	UnchangedRolls int                 // Clicks that left the tooltip unchanged (not counted in TotalRolls)
+	TimedOutRolls  int                 // Clicks whose tooltip never settled (not counted in TotalRolls)
+	Attempts       []AttemptRecord     // Verification outcome of every click
+	ModStats       map[string]*ModStat // Key: mod name
+	TargetModHit   bool
+	TargetModName  string // Which target mod was found
+	TargetValue    float64
+	Best           *BestRoll      // Maximise mode: best roll of the current item
+	RollScores     []float64      // Formula score of every roll, when a score formula is set
+	LastItem       *ParsedItem    // Mods and affix layout of the latest roll
+	CurrencyUsed   map[string]int // Upgrade orbs used on non-rare items, by currency id
+	MarkedRolls    []MarkedRoll   // Rolls marked with the mark hotkey
+	RoundResults   []RoundResult  // Track each individual round
+
+	formula         *scoring.Formula
+	affixConditions []config.AffixCondition
+	lastImage       image.Image // Tooltip of the latest roll, for the mark hotkey
+	checkpoint      *Checkpoint // Saved after every roll, nil outside batch mode
+	mu              sync.Mutex  // Guards TotalRolls and RoundResults for Progress
 }
 
-// TrackMods parses OCR text and tracks all mods found
-func TrackMods(text string, session *CraftingSession, rollNumber int) {
-	// Common mod patterns to track
-	modPatterns := []struct {
-		name    string
-		pattern string
-	}{
-		{"Life", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+LIFE`},
-		{"Mana", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+MANA`},
-		{"Strength", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+STRENGTH`},
-		{"Dexterity", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+DEXTERITY`},
-		{"Intelligence", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+INTELLIGENCE`},
-		{"Spirit", `(?i)[+#]?(\d+)(?:\(\d+-\d+\))?\s+TO\s+SPIRIT`},
-		{"Spell Skills Level", `\+(\d+)\s+TO\s+LEVEL\s+OF\s+ALL\s+SPELL\s+SKILLS`},
-		{"Projectile Skills Level", `\+(\d+)\s+TO\s+LEVEL\s+OF\s+ALL\s+PROJECTILE\s+SKILLS`},
-		{"Critical Damage Bonus", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*INCREASED\s+CRITICAL\s+DAMAGE\s+BONUS`},
-		{"Fire Resistance", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?FIRE\s+RESISTANCE`},
-		{"Cold Resistance", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?COLD\s+RESISTANCE`},
-		{"Lightning Resistance", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?LIGHTNING\s+RESISTANCE`},
-		{"Chaos Resistance", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?CHAOS\s+RESISTANCE`},
-		{"Armour", `(?i)(\d+)(?:\(\d+-\d+\))?\s+(?:INCREASED\s+)?ARMOUR`},
-		{"Evasion", `(?i)(\d+)(?:\(\d+-\d+\))?\s+(?:INCREASED\s+)?EVASION`},
-		{"Energy Shield", `(?i)\+(\d+)(?:\(\d+-\d+\))?\s+TO\s+MAXIMUM\s+ENERGY\s+SHIELD`},
-		{"Movement Speed", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?MOVEMENT\s+SPEED`},
-		{"Attack Speed", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?ATTACK\s+SPEED`},
-		{"Cast Speed", `(?i)(\d+)(?:\(\d+-\d+\))?%?\s*(?:INCREASED\s+)?CAST\s+SPEED`},
-	}
-
-	for _, mod := range modPatterns {
-		re := regexp.MustCompile(mod.pattern)
-		matches := re.FindAllStringSubmatch(text, -1)
-
-		for _, match := range matches {
-			if len(match) < 2 {
-				continue
-			}
+// countRoll adds an applied roll to TotalRolls
+func (s *CraftingSession) countRoll() {
+	s.mu.Lock()
+	s.TotalRolls++
+	s.mu.Unlock()
+}
 
-			value, err := strconv.Atoi(match[1])
-			if err != nil {
-				continue
-			}
+// addRound records the result of a finished or skipped item
+func (s *CraftingSession) addRound(round RoundResult) {
+	s.mu.Lock()
+	s.RoundResults = append(s.RoundResults, round)
+	s.mu.Unlock()
+}
 
-			// Update or create mod stat
-			stat, exists := session.ModStats[mod.name]
-			if !exists {
-				stat = &ModStat{
-					ModName:  mod.name,
-					MinValue: value,
-					MaxValue: value,
-				}
-				session.ModStats[mod.name] = stat
+// Progress returns how many items are done and how many rolls were counted. Unlike the
+// fields themselves it is safe to call from another goroutine while crafting.
+func (s *CraftingSession) Progress() (items, rolls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.RoundResults), s.TotalRolls
+}
+
+// RecordAttempt logs a click's verification outcome and updates the unapplied counters
+func (s *CraftingSession) RecordAttempt(record AttemptRecord) {
+	s.Attempts = append(s.Attempts, record)
+	switch record.Outcome {
+	case RollUnchanged:
+		s.UnchangedRolls++
+	case RollTimedOut:
+		s.TimedOutRolls++
+	}
+}
+
+// ParsedMod is a single mod line recognized in OCR text
+type ParsedMod struct {
+	ID     string             `json:"id,omitempty"` // Catalog id, e.g. "life"
+	Name   string             `json:"name"`
+	Value  float64            `json:"value"`            // Single or derived value
+	Values map[string]float64 `json:"values,omitempty"` // Named values of multi-value mods
+	Group  string             `json:"group,omitempty"`  // modcatalog.Prefix or modcatalog.Suffix
+}
+
+// ParseMods extracts all catalog mods and their values from OCR text in the given game
+// language. Names are the language-independent statistics names.
+func ParseMods(text string, gameLang string) []ParsedMod {
+	return ParseItem(text, gameLang).Mods
+}
+
+// TrackMods parses OCR text and tracks all mods found
+func TrackMods(text string, gameLang string, session *CraftingSession, rollNumber int) {
+	for _, mod := range ParseMods(text, gameLang) {
+		value := mod.Value
+
+		// Update or create mod stat
+		stat, exists := session.ModStats[mod.Name]
+		if !exists {
+			stat = &ModStat{
+				ModName:  mod.Name,
+				MinValue: value,
+				MaxValue: value,
 			}
+			session.ModStats[mod.Name] = stat
+		}
 
-			stat.Count++
-			stat.TotalValue += value
-			stat.AvgValue = float64(stat.TotalValue) / float64(stat.Count)
+		stat.Count++
+		stat.TotalValue += value
+		stat.AvgValue = stat.TotalValue / float64(stat.Count)
+
+		if value < stat.MinValue {
+			stat.MinValue = value
+		}
+		if value > stat.MaxValue {
+			stat.MaxValue = value
+		}
 
-			if value < stat.MinValue {
-				stat.MinValue = value
+		for name, v := range mod.Values {
+			if stat.Values == nil {
+				stat.Values = make(map[string]*ValueStat)
 			}
-			if value > stat.MaxValue {
-				stat.MaxValue = value
+			vs, ok := stat.Values[name]
+			if !ok {
+				vs = &ValueStat{Distribution: make(map[string]int)}
+				stat.Values[name] = vs
 			}
+			vs.add(v)
 		}
 	}
 }
 
+// sortedValueNames returns the value names of a multi-value mod in stable order
+func sortedValueNames(values map[string]*ValueStat) []string {
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatLayout lists affix mod names, or "-" when there are none
+func formatLayout(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ", ")
+}
+
+// FormatDistribution summarizes a value distribution as up to `buckets` ranges with counts.
+// Whole-number values keep single-value buckets when the range is small enough.
+func FormatDistribution(dist map[string]int, buckets int) string {
+	if len(dist) == 0 {
+		return ""
+	}
+	counts := make(map[float64]int)
+	lo, hi := 0.0, 0.0
+	first := true
+	whole := true
+	for key, n := range dist {
+		v, err := modcatalog.ParseValue(key)
+		if err != nil {
+			continue
+		}
+		counts[v] += n
+		if first || v < lo {
+			lo = v
+		}
+		if first || v > hi {
+			hi = v
+		}
+		if v != math.Trunc(v) {
+			whole = false
+		}
+		first = false
+	}
+	if first {
+		return ""
+	}
+
+	width := (hi - lo) / float64(buckets)
+	if whole {
+		width = math.Floor(width) + 1
+	}
+	if width == 0 {
+		width = 1
+	}
+	bucketCounts := make([]int, int((hi-lo)/width)+1)
+	for v, n := range counts {
+		bucketCounts[min(int((v-lo)/width), len(bucketCounts)-1)] += n
+	}
+
+	var parts []string
+	for i, n := range bucketCounts {
+		from := lo + float64(i)*width
+		switch {
+		case whole && width == 1:
+			parts = append(parts, fmt.Sprintf("%s:%d", modcatalog.FormatValue(from), n))
+		case whole:
+			parts = append(parts, fmt.Sprintf("%s-%s:%d", modcatalog.FormatValue(from), modcatalog.FormatValue(from+width-1), n))
+		default:
+			parts = append(parts, fmt.Sprintf("%.2f-%.2f:%d", from, math.Min(from+width, hi), n))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // BuildReportData builds a JSON-serializable report from the session
 func BuildReportData(session *CraftingSession, cfg config.Config) *ReportData {
 	duration := session.EndTime.Sub(session.StartTime)
@@ -126,14 +270,28 @@ func BuildReportData(session *CraftingSession, cfg config.Config) *ReportData {
 	}
 
 	report := &ReportData{
-		StartTime:     session.StartTime.Format("2006-01-02 15:04:05"),
-		EndTime:       session.EndTime.Format("2006-01-02 15:04:05"),
-		Duration:      duration.Round(time.Second).String(),
-		TotalRolls:    session.TotalRolls,
-		RollsPerMin:   rollsPerMin,
-		TargetModHit:  session.TargetModHit,
-		TargetModName: session.TargetModName,
-		TargetValue:   session.TargetValue,
+		StartTime:      session.StartTime.Format("2006-01-02 15:04:05"),
+		EndTime:        session.EndTime.Format("2006-01-02 15:04:05"),
+		Duration:       duration.Round(time.Second).String(),
+		TotalRolls:     session.TotalRolls,
+		UnchangedRolls: session.UnchangedRolls,
+		TimedOutRolls:  session.TimedOutRolls,
+		RollsPerMin:    rollsPerMin,
+		TargetModHit:   session.TargetModHit,
+		TargetModName:  session.TargetModName,
+		TargetValue:    session.TargetValue,
+		Mode:           cfg.Mode,
+		ScoreFormula:   cfg.ScoreFormula,
+		RollScores:     session.RollScores,
+		CurrencyUsed:   session.CurrencyUsed,
+	}
+	for _, mark := range session.MarkedRolls {
+		report.MarkedRolls = append(report.MarkedRolls, mark.Roll)
+	}
+	if session.Best != nil {
+		report.BestScore = session.Best.Score
+		report.BestModName = session.Best.ModName
+		report.BestAttempt = session.Best.Attempt
 	}
 
 	for _, mod := range cfg.TargetMods {
@@ -161,35 +319,58 @@ func BuildReportData(session *CraftingSession, cfg config.Config) *ReportData {
 		if session.TotalRolls > 0 {
 			prob = float64(e.stat.Count) / float64(session.TotalRolls) * 100
 		}
-		report.ModStats = append(report.ModStats, ReportModStat{
+		rs := ReportModStat{
 			ModName:     e.stat.ModName,
 			Count:       e.stat.Count,
 			MinValue:    e.stat.MinValue,
 			MaxValue:    e.stat.MaxValue,
 			AvgValue:    e.stat.AvgValue,
 			Probability: prob,
-		})
+		}
+		for _, name := range sortedValueNames(e.stat.Values) {
+			vs := e.stat.Values[name]
+			rs.Values = append(rs.Values, ReportValueStat{
+				Name:         name,
+				MinValue:     vs.Min,
+				MaxValue:     vs.Max,
+				AvgValue:     vs.Avg,
+				Distribution: vs.Distribution,
+			})
+		}
+		report.ModStats = append(report.ModStats, rs)
 	}
 
 	for _, round := range session.RoundResults {
 		report.RoundResults = append(report.RoundResults, ReportRoundResult{
 			RoundNumber:   round.RoundNumber,
+			Item:          round.Item,
 			Success:       round.Success,
 			TargetHit:     round.TargetHit,
 			TargetModName: round.TargetModName,
 			TargetValue:   round.TargetValue,
+			Prefixes:      round.Prefixes,
+			Suffixes:      round.Suffixes,
 		})
 	}
 
 	return report
 }
 
-// GenerateReport creates a detailed report of the crafting session
+// ReportPrefix starts the name of every report file; the session's start time follows,
+// formatted as ReportTimeFormat
+const ReportPrefix = "crafting_report_"
+
+// ReportTimeFormat is the start time in report file names
+const ReportTimeFormat = "2006-01-02_15-04-05"
+
+// GenerateReport creates a detailed report of the crafting session. It is saved as text
+// for reading and as JSON (ReportData) for tools such as "poe2crafter report".
 func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 	duration := session.EndTime.Sub(session.StartTime)
 
 	// Create report filename with timestamp
-	reportFile := fmt.Sprintf("crafting_report_%s.txt", session.StartTime.Format("2006-01-02_15-04-05"))
+	reportBase := ReportPrefix + session.StartTime.Format(ReportTimeFormat)
+	reportFile := reportBase + ".txt"
 
 	var report strings.Builder
 	report.WriteString("╔═══════════════════════════════════════════════╗\n")
@@ -203,10 +384,17 @@ func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 	report.WriteString(fmt.Sprintf("End Time:       %s\n", session.EndTime.Format("2006-01-02 15:04:05")))
 	report.WriteString(fmt.Sprintf("Duration:       %s\n", duration.Round(time.Second)))
 	report.WriteString(fmt.Sprintf("Total Rolls:    %d\n", session.TotalRolls))
+	if session.UnchangedRolls+session.TimedOutRolls > 0 {
+		report.WriteString(fmt.Sprintf("Not Applied:    %d unchanged, %d timed out (not counted)\n",
+			session.UnchangedRolls, session.TimedOutRolls))
+	}
 	if session.TotalRolls > 0 && duration.Seconds() > 0 {
 		rollsPerMin := float64(session.TotalRolls) / duration.Minutes()
 		report.WriteString(fmt.Sprintf("Speed:          %.1f rolls/min\n", rollsPerMin))
 	}
+	if len(session.CurrencyUsed) > 0 {
+		report.WriteString(fmt.Sprintf("Upgrade Orbs:   %s\n", formatCurrencyUsed(session.CurrencyUsed)))
+	}
 	report.WriteString("Target Mods:    ")
 	if len(cfg.TargetMods) > 0 {
 		report.WriteString(cfg.TargetMods[0].Description)
@@ -218,12 +406,48 @@ func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 		report.WriteString("(none)\n")
 	}
 	if session.TargetModHit {
-		report.WriteString(fmt.Sprintf("Result:         ✓ SUCCESS - %s (Value: %d)\n", session.TargetModName, session.TargetValue))
+		report.WriteString(fmt.Sprintf("Result:         ✓ SUCCESS - %s (Value: %s)\n", session.TargetModName, modcatalog.FormatValue(session.TargetValue)))
 	} else {
 		report.WriteString("Result:         ✗ Not found\n")
 	}
+	if session.Best != nil {
+		report.WriteString(fmt.Sprintf("Best Roll:      %s = %s (attempt %d, %s)\n", session.Best.ModName,
+			modcatalog.FormatValue(session.Best.Score), session.Best.Attempt, session.Best.Snapshot))
+	}
 	report.WriteString("\n")
 
+	// Marked Rolls
+	if len(session.MarkedRolls) > 0 {
+		report.WriteString("MARKED ROLLS\n")
+		report.WriteString("─────────────────────────────────────────────────\n")
+		for _, mark := range session.MarkedRolls {
+			report.WriteString(fmt.Sprintf("Roll #%-5d %s\n", mark.Roll, strings.Join(mark.Mods, ", ")))
+			if mark.Snapshot != "" {
+				report.WriteString(fmt.Sprintf("           %s\n", mark.Snapshot))
+			}
+		}
+		report.WriteString("\n")
+	}
+
+	// Roll Scores
+	if len(session.RollScores) > 0 {
+		report.WriteString("ROLL SCORES\n")
+		report.WriteString("─────────────────────────────────────────────────\n")
+		report.WriteString(fmt.Sprintf("Formula:        %s (success at %s)\n", cfg.ScoreFormula, modcatalog.FormatValue(cfg.ScoreThreshold)))
+		lo, hi, total := session.RollScores[0], session.RollScores[0], 0.0
+		for _, score := range session.RollScores {
+			lo, hi, total = min(lo, score), max(hi, score), total+score
+		}
+		report.WriteString(fmt.Sprintf("Min/Max/Avg:    %.1f / %.1f / %.1f\n", lo, hi, total/float64(len(session.RollScores))))
+		for i, score := range session.RollScores {
+			if i%8 == 0 {
+				report.WriteString("\n ")
+			}
+			report.WriteString(fmt.Sprintf(" #%-4d %7.1f", i+1, score))
+		}
+		report.WriteString("\n\n")
+	}
+
 	// Mod Statistics
 	if len(session.ModStats) > 0 {
 		report.WriteString("MOD STATISTICS\n")
@@ -250,8 +474,14 @@ func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 
 		for _, entry := range entries {
 			stat := entry.stat
-			report.WriteString(fmt.Sprintf("%-20s %8d %8d %8d %8.1f\n",
-				stat.ModName, stat.Count, stat.MinValue, stat.MaxValue, stat.AvgValue))
+			report.WriteString(fmt.Sprintf("%-20s %8d %8s %8s %8.1f\n",
+				stat.ModName, stat.Count, modcatalog.FormatValue(stat.MinValue), modcatalog.FormatValue(stat.MaxValue), stat.AvgValue))
+			for _, name := range sortedValueNames(stat.Values) {
+				vs := stat.Values[name]
+				report.WriteString(fmt.Sprintf("  └ %-16s %8d %8s %8s %8.1f\n",
+					name, vs.Count, modcatalog.FormatValue(vs.Min), modcatalog.FormatValue(vs.Max), vs.Avg))
+				report.WriteString(fmt.Sprintf("    %s\n", FormatDistribution(vs.Distribution, 5)))
+			}
 		}
 		report.WriteString("\n")
 	}
@@ -292,18 +522,22 @@ func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 		report.WriteString("─────────────────────────────────────────────────\n")
 		for _, round := range session.RoundResults {
 			report.WriteString(fmt.Sprintf("\n📦 Round #%d\n", round.RoundNumber))
+			report.WriteString(fmt.Sprintf("   Item: %s\n", round.Item))
 			report.WriteString(fmt.Sprintf("   Start Position: (%d, %d)\n", round.StartPos.X, round.StartPos.Y))
 			report.WriteString(fmt.Sprintf("   End Position:   (%d, %d)\n", round.EndPos.X, round.EndPos.Y))
 
 			if round.Success {
 				report.WriteString("   Result: ✓ SUCCESS\n")
 				if round.TargetHit {
-					report.WriteString(fmt.Sprintf("   Target Hit: %s = %d\n", round.TargetModName, round.TargetValue))
+					report.WriteString(fmt.Sprintf("   Target Hit: %s = %s\n", round.TargetModName, modcatalog.FormatValue(round.TargetValue)))
 				}
 			} else {
 				report.WriteString("   Result: ○ No target match\n")
 			}
 
+			report.WriteString(fmt.Sprintf("   Prefixes (%d/%d): %s\n", len(round.Prefixes), modcatalog.MaxPrefixes, formatLayout(round.Prefixes)))
+			report.WriteString(fmt.Sprintf("   Suffixes (%d/%d): %s\n", len(round.Suffixes), modcatalog.MaxSuffixes, formatLayout(round.Suffixes)))
+
 			if round.ErrorMessage != "" {
 				report.WriteString(fmt.Sprintf("   Error: %s\n", round.ErrorMessage))
 			}
@@ -323,7 +557,13 @@ func (e *Engine) GenerateReport(session *CraftingSession, cfg config.Config) {
 	// Also print to console
 	fmt.Println("\n" + reportText)
 
-	// Emit session ended event for web GUI
 	reportData := BuildReportData(session, cfg)
+	if data, err := json.MarshalIndent(reportData, "", "  "); err == nil {
+		if err := os.WriteFile(reportBase+".json", data, 0644); err != nil {
+			fmt.Printf("⚠ Warning: Could not save report data: %v\n", err)
+		}
+	}
+
+	// Emit session ended event for web GUI
 	e.Emit("session_ended", SessionEndedData{Report: reportData})
 }
@@ -0,0 +1,14 @@
+package engine
+
+import (
+	"poe2-chaos-crafter/internal/scoring"
+)
+
+// RollScore evaluates a score formula against the mods recognized in OCR text
+func RollScore(f *scoring.Formula, text string, gameLang string) float64 {
+	var values []scoring.Value
+	for _, mod := range ParseMods(text, gameLang) {
+		values = append(values, scoring.Value{ID: mod.ID, Value: mod.Value, Values: mod.Values})
+	}
+	return f.Eval(scoring.Variables(values))
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -13,16 +14,28 @@ import (
 
 	"poe2-chaos-crafter/internal/config"
 
-	"github.com/go-vgo/robotgo"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 )
 
-// HumanDelay adds a random delay to simulate human behavior (base ± variation in ms)
-func HumanDelay(baseMs int, variationMs int) {
+// HumanDelay adds a random delay to simulate human behavior (base ± variation in ms).
+// It returns early when ctx is cancelled.
+func HumanDelay(ctx context.Context, baseMs int, variationMs int) {
 	delay := baseMs + rand.Intn(variationMs*2) - variationMs
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	Sleep(ctx, time.Duration(delay)*time.Millisecond)
+}
+
+// Sleep waits for d, or returns ctx.Err() as soon as ctx is cancelled
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // SaveImage saves an image to a file
@@ -36,28 +49,30 @@ func SaveImage(img image.Image, path string) error {
 }
 
 // CleanupDebugSnapshots removes old snapshots folder and creates a fresh one
-func CleanupDebugSnapshots() {
+func (e *Engine) CleanupDebugSnapshots() {
 	// Remove entire snapshots directory if it exists
-	if _, err := os.Stat(config.SnapshotsDir); err == nil {
-		if err := os.RemoveAll(config.SnapshotsDir); err == nil {
+	if _, err := os.Stat(e.SnapshotsDir); err == nil {
+		if err := os.RemoveAll(e.SnapshotsDir); err == nil {
 			fmt.Printf("🧹 Cleaned up previous snapshots\n")
 		}
 	}
 
 	// Create fresh snapshots directory
-	if err := os.MkdirAll(config.SnapshotsDir, 0755); err != nil {
+	if err := os.MkdirAll(e.SnapshotsDir, 0755); err != nil {
 		fmt.Printf("⚠ Warning: Could not create snapshots directory: %v\n", err)
 	}
 }
 
 // DrawBackpackGrid creates a debug image with the backpack grid overlay
-func DrawBackpackGrid(cfg config.Config) error {
+func (e *Engine) DrawBackpackGrid(cfg config.Config) error {
 	// Capture the backpack area
 	width := cfg.BackpackBottomRight.X - cfg.BackpackTopLeft.X
 	height := cfg.BackpackBottomRight.Y - cfg.BackpackTopLeft.Y
 
-	bitmap := robotgo.CaptureScreen(cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y, width, height)
-	img := robotgo.ToImage(bitmap)
+	img, err := e.Backend.Capture(cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y, width, height)
+	if err != nil {
+		return fmt.Errorf("failed to capture backpack: %w", err)
+	}
 
 	// Create a new RGBA image for drawing
 	bounds := img.Bounds()
@@ -103,7 +118,7 @@ func DrawBackpackGrid(cfg config.Config) error {
 	}
 
 	// Save debug snapshot
-	debugFile := filepath.Join(config.SnapshotsDir, "backpack_grid_debug.png")
+	debugFile := filepath.Join(e.SnapshotsDir, "backpack_grid_debug.png")
 	if err := SaveImage(rgba, debugFile); err != nil {
 		return fmt.Errorf("failed to save backpack grid debug image: %w", err)
 	}
@@ -126,14 +141,19 @@ func drawString(img *image.RGBA, x, y int, label string, col color.Color) {
 }
 
 // DrawFullScreenDebugSnapshot captures the entire screen and labels all important areas
-func DrawFullScreenDebugSnapshot(cfg config.Config, itemNum int, stepName string, itemX, itemY, resultX, resultY int) error {
+func (e *Engine) DrawFullScreenDebugSnapshot(cfg config.Config, itemNum int, stepName string, itemX, itemY, resultX, resultY int) error {
 	// Get screen dimensions
-	screenWidth, screenHeight := robotgo.GetScreenSize()
+	screenWidth, screenHeight, err := e.Backend.ScreenSize()
+	if err != nil {
+		return fmt.Errorf("failed to read screen size: %w", err)
+	}
 	fmt.Printf("     Screen size: %dx%d\n", screenWidth, screenHeight)
 
 	// Capture entire screen
-	bitmap := robotgo.CaptureScreen()
-	img := robotgo.ToImage(bitmap)
+	img, err := e.Backend.Capture(0, 0, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to capture screen: %w", err)
+	}
 
 	// Get actual captured dimensions
 	bounds := img.Bounds()
@@ -264,7 +284,7 @@ func DrawFullScreenDebugSnapshot(cfg config.Config, itemNum int, stepName string
 	drawString(rgba, 20, 50, "Flow: PENDING (cyan) -> WORKBENCH (orange) -> RESULT (magenta) | TOOLTIP (light blue)", color.RGBA{200, 200, 200, 255})
 
 	// Save snapshot
-	debugFile := filepath.Join(config.SnapshotsDir, fmt.Sprintf("round%d_%s.png", itemNum, stepName))
+	debugFile := filepath.Join(e.SnapshotsDir, fmt.Sprintf("round%d_%s.png", itemNum, stepName))
 	if err := SaveImage(rgba, debugFile); err != nil {
 		return fmt.Errorf("failed to save full screen debug snapshot: %w", err)
 	}
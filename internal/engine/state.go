@@ -1,11 +1,14 @@
 package engine
 
 import (
+	"context"
 	"image"
+	"sync"
 	"sync/atomic"
-	"time"
 
+	"poe2-chaos-crafter/internal/backend"
 	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/hotkey"
 )
 
 // EventBroadcaster defines the interface for broadcasting events to WebSocket clients
@@ -17,29 +20,50 @@ type EventBroadcaster interface {
 type SessionManager interface {
 	OnSessionStart(session *CraftingSession, cfg *config.Config)
 	OnSessionEnd()
-	GetState() string
-	SetState(state string)
 }
 
 // Engine holds all runtime state that was previously in package-level globals
 type Engine struct {
-	StopRequested       atomic.Bool
-	PauseRequested      atomic.Bool
-	PauseToggleCooldown atomic.Value // stores time.Time
-	LastPauseKeyState   atomic.Bool
-	SnapshotCounter     atomic.Int32 // Sequential counter for snapshot naming
-	DebugMode           bool
-	EmptyCellReference  image.Image
-	Broadcaster         EventBroadcaster // nil in CLI mode
-	SessionManager      SessionManager   // nil in CLI mode
+	Name               string          // Name the web server knows the engine by
+	Backend            backend.Backend // Screen and input the game is played through
+	SnapshotsDir       string          // Where this engine's snapshots are saved
+	CheckpointPath     string          // Where this engine's batch checkpoint is saved
+	SkipRequested      atomic.Bool     // Skip hotkey: stop rolling the current item
+	MarkRequested      atomic.Bool     // Mark hotkey: mark the latest roll
+	SnapshotCounter    atomic.Int32    // Sequential counter for snapshot naming
+	DebugMode          bool
+	EmptyCellReference image.Image
+	Broadcaster        EventBroadcaster // nil in CLI mode
+	SessionManager     SessionManager   // nil in CLI mode
+	HotkeyBackend      hotkey.Backend   // nil = platform default on the local engine, none elsewhere
+	Local              bool             // Plays on this machine: listens for hotkeys and Ctrl+C
+	ocrCache           *ocrCache        // OCR text keyed by tooltip content hash
+	hotkeys            hotkey.Bindings  // Bindings of the running session
+	states             *StateMachine    // Single source of the lifecycle state
+
+	attentionMu  sync.Mutex
+	attentionSeq int
+	attention    *AttentionRequest // Pending needs_attention request
+	consoleOnce  sync.Once         // Starts the console answer reader in CLI mode
+
+	cancelMu sync.Mutex
+	cancel   context.CancelFunc // Cancels the running session, see SessionContext
 }
 
 // NewEngine creates a new Engine with default state
 func NewEngine(debugMode bool) *Engine {
 	e := &Engine{
-		DebugMode: debugMode,
+		Name:           config.DefaultEngine,
+		Backend:        backend.NewLocal(),
+		SnapshotsDir:   config.SnapshotsDir,
+		CheckpointPath: config.GetCheckpointPath(),
+		DebugMode:      debugMode,
+		Local:          true,
+		ocrCache:       newOCRCache(256),
 	}
-	e.PauseToggleCooldown.Store(time.Now())
+	e.states = NewStateMachine(func(change StateChangeData) {
+		e.Emit("state_change", change)
+	})
 	return e
 }
 
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// State is the lifecycle state of the engine
+type State string
+
+// Engine states
+const (
+	StateIdle      State = "idle"      // Nothing to do
+	StateCountdown State = "countdown" // Web start countdown before crafting
+	StateRunning   State = "running"   // Crafting
+	StatePaused    State = "paused"    // Crafting, waiting for resume
+	StateStopping  State = "stopping"  // Stop requested, finishing the current step
+	StateError     State = "error"     // The last run ended with an error
+)
+
+// transitions lists the states each state may move to
+var transitions = map[State][]State{
+	StateIdle:      {StateCountdown, StateRunning},
+	StateCountdown: {StateRunning, StateStopping},
+	StateRunning:   {StatePaused, StateStopping, StateIdle, StateError},
+	StatePaused:    {StateRunning, StateStopping, StateError},
+	StateStopping:  {StateIdle, StateError},
+	StateError:     {StateCountdown, StateRunning, StateIdle},
+}
+
+// TransitionError is returned for a state change the state machine does not allow
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot go from %s to %s", e.From, e.To)
+}
+
+// StateMachine holds the engine state and only allows the transitions listed above
+type StateMachine struct {
+	mu       sync.RWMutex
+	state    State
+	lastErr  string
+	onChange func(StateChangeData)
+}
+
+// NewStateMachine starts in StateIdle. onChange is called after every transition.
+func NewStateMachine(onChange func(StateChangeData)) *StateMachine {
+	return &StateMachine{state: StateIdle, onChange: onChange}
+}
+
+// State returns the current state
+func (m *StateMachine) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// LastError returns the message of the error that led to StateError
+func (m *StateMachine) LastError() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastErr
+}
+
+// Transition moves to state to, or returns a *TransitionError if that is not allowed
+func (m *StateMachine) Transition(to State) error {
+	return m.transition(to, "", nil)
+}
+
+// TransitionFrom moves to state to only if the machine is in one of the given states
+func (m *StateMachine) TransitionFrom(to State, from ...State) error {
+	return m.transition(to, "", from)
+}
+
+// Fail moves to StateError and keeps the error message for the GUI
+func (m *StateMachine) Fail(err error) error {
+	return m.transition(StateError, err.Error(), nil)
+}
+
+func (m *StateMachine) transition(to State, errMsg string, from []State) error {
+	m.mu.Lock()
+	current := m.state
+	if !allowed(current, to) || (from != nil && !containsState(from, current)) {
+		m.mu.Unlock()
+		return &TransitionError{From: current, To: to}
+	}
+	m.state = to
+	m.lastErr = errMsg
+	change := StateChangeData{State: string(to), From: string(current), Error: m.lastErr}
+	m.mu.Unlock()
+
+	if m.onChange != nil {
+		m.onChange(change)
+	}
+	return nil
+}
+
+func allowed(from, to State) bool {
+	return containsState(transitions[from], to)
+}
+
+func containsState(states []State, s State) bool {
+	for _, state := range states {
+		if state == s {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the engine's lifecycle state
+func (e *Engine) State() State {
+	return e.states.State()
+}
+
+// LastError returns the error that put the engine in StateError
+func (e *Engine) LastError() string {
+	return e.states.LastError()
+}
+
+// Transition moves the engine to another state, see StateMachine.Transition
+func (e *Engine) Transition(to State) error {
+	return e.states.Transition(to)
+}
+
+// PauseRequested reports whether the craft loop should wait for resume
+func (e *Engine) PauseRequested() bool {
+	return e.State() == StatePaused
+}
+
+// RequestStop asks a countdown or crafting run to stop and cancels its session context.
+// Returns a *TransitionError when nothing is running or a stop is already pending.
+func (e *Engine) RequestStop() error {
+	if err := e.states.Transition(StateStopping); err != nil {
+		return err
+	}
+	e.cancelMu.Lock()
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.cancelMu.Unlock()
+	return nil
+}
+
+// SessionContext derives the context of one countdown or crafting run from parent.
+// RequestStop cancels the outermost one still open, so a caller that starts Craft
+// inside its own session can tell a stop from ctx.
+func (e *Engine) SessionContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+	if e.cancel != nil {
+		return ctx, cancel
+	}
+	e.cancel = cancel
+	return ctx, func() {
+		e.cancelMu.Lock()
+		e.cancel = nil
+		e.cancelMu.Unlock()
+		cancel()
+	}
+}
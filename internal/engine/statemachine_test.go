@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStateMachineRejectsTransitions(t *testing.T) {
+	tests := []struct {
+		from []State // Path from idle to the starting state
+		to   State
+	}{
+		{nil, StatePaused},
+		{nil, StateStopping},
+		{nil, StateError},
+		{[]State{StateCountdown}, StatePaused},
+		{[]State{StateCountdown}, StateIdle},
+		{[]State{StateRunning, StateStopping}, StateRunning},
+		{[]State{StateRunning, StateStopping}, StatePaused},
+		{[]State{StateRunning, StatePaused}, StateIdle},
+		{[]State{StateRunning, StateError}, StateStopping},
+	}
+	for _, tt := range tests {
+		var changes int
+		m := NewStateMachine(func(StateChangeData) { changes++ })
+		for _, s := range tt.from {
+			if err := m.Transition(s); err != nil {
+				t.Fatalf("setting up %v: %v", tt.from, err)
+			}
+		}
+		from, before := m.State(), changes
+
+		err := m.Transition(tt.to)
+		var te *TransitionError
+		if !errors.As(err, &te) {
+			t.Errorf("%s → %s: error %v, want a *TransitionError", from, tt.to, err)
+			continue
+		}
+		if te.From != from || te.To != tt.to {
+			t.Errorf("%s → %s: TransitionError{%s, %s}", from, tt.to, te.From, te.To)
+		}
+		if m.State() != from || changes != before {
+			t.Errorf("%s → %s: rejected transition changed the state to %s", from, tt.to, m.State())
+		}
+	}
+}
+
+func TestTransitionFromChecksCurrentState(t *testing.T) {
+	m := NewStateMachine(nil)
+	if err := m.TransitionFrom(StateRunning, StateCountdown); err == nil {
+		t.Error("TransitionFrom(running, countdown) succeeded from idle")
+	}
+	if err := m.TransitionFrom(StateRunning, StateCountdown, StateIdle); err != nil {
+		t.Errorf("TransitionFrom(running, countdown, idle): %v", err)
+	}
+}
+
+func TestFailKeepsError(t *testing.T) {
+	m := NewStateMachine(nil)
+	if err := m.Fail(errors.New("boom")); err == nil {
+		t.Error("Fail() succeeded from idle")
+	}
+	m.Transition(StateRunning)
+	if err := m.Fail(errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	if m.State() != StateError || m.LastError() != "boom" {
+		t.Errorf("state %s, error %q; want error, boom", m.State(), m.LastError())
+	}
+	m.Transition(StateIdle)
+	if m.LastError() != "" {
+		t.Errorf("LastError() = %q after leaving the error state", m.LastError())
+	}
+}
+
+func TestEngineRejectsStopAndPauseWhenIdle(t *testing.T) {
+	e := NewEngine(false)
+	var te *TransitionError
+	if err := e.RequestStop(); !errors.As(err, &te) {
+		t.Errorf("RequestStop() when idle = %v, want a *TransitionError", err)
+	}
+	if state, err := e.TogglePause(); !errors.As(err, &te) || state != StateIdle {
+		t.Errorf("TogglePause() when idle = %s, %v; want idle and a *TransitionError", state, err)
+	}
+
+	e.Transition(StateRunning)
+	if err := e.RequestStop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RequestStop(); !errors.As(err, &te) {
+		t.Errorf("second RequestStop() = %v, want a *TransitionError", err)
+	}
+	if _, err := e.TogglePause(); !errors.As(err, &te) {
+		t.Errorf("TogglePause() while stopping = %v, want a *TransitionError", err)
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// Roll verification outcomes
+const (
+	RollApplied   = "applied"   // Tooltip changed and settled
+	RollUnchanged = "unchanged" // Tooltip still matches the previous roll
+	RollTimedOut  = "timed_out" // Tooltip changed but never settled
+)
+
+const verifyPollInterval = 80 * time.Millisecond
+
+// AttemptRecord is the verification result of a single currency click
+type AttemptRecord struct {
+	Attempt  int
+	Outcome  string
+	Distance int // Hash bit difference to the previous roll
+	Wait     time.Duration
+}
+
+// CaptureTooltip grabs the configured tooltip area of the screen. A failed capture
+// comes back blank, which verification treats as an unchanged tooltip.
+func (e *Engine) CaptureTooltip(cfg *config.Config) image.Image {
+	rect := cfg.TooltipRect
+	img, err := e.Backend.Capture(rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	if err != nil {
+		fmt.Printf("\n⚠ Warning: Could not capture the tooltip: %v\n", err)
+		return image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	}
+	return img
+}
+
+// VerifyRoll polls the tooltip after a click until it differs from the previous roll and
+// holds still for two captures, or the verify timeout expires. The returned image and hash
+// are only meaningful when the outcome is RollApplied.
+func (e *Engine) VerifyRoll(ctx context.Context, cfg *config.Config, prev ImageHash) (AttemptRecord, image.Image, ImageHash) {
+	threshold := cfg.UnchangedHashDistance
+	if threshold <= 0 {
+		threshold = 6
+	}
+	timeout := time.Duration(cfg.VerifyTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 1500 * time.Millisecond
+	}
+
+	start := time.Now()
+	record := AttemptRecord{Outcome: RollUnchanged}
+	var lastHash ImageHash
+	for {
+		img := e.CaptureTooltip(cfg)
+		hash := PerceptualHash(img)
+		record.Wait = time.Since(start)
+
+		if prev == nil {
+			record.Outcome = RollApplied
+			return record, img, hash
+		}
+
+		record.Distance = hash.Distance(prev)
+		if record.Distance > threshold {
+			if lastHash != nil && hash.Distance(lastHash) <= threshold {
+				record.Outcome = RollApplied
+				return record, img, hash
+			}
+			record.Outcome = RollTimedOut
+		} else {
+			record.Outcome = RollUnchanged
+		}
+		lastHash = hash
+
+		if record.Wait >= timeout || Sleep(ctx, verifyPollInterval) != nil {
+			return record, nil, nil
+		}
+	}
+}
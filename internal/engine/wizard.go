@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"image"
 	"os"
@@ -11,12 +12,12 @@ import (
 	"time"
 
 	"poe2-chaos-crafter/internal/config"
-
-	"github.com/go-vgo/robotgo"
+	"poe2-chaos-crafter/internal/modcatalog"
+	"poe2-chaos-crafter/internal/scoring"
 )
 
-// CaptureWithCountdown captures a position with a countdown
-func CaptureWithCountdown(prompt string) (int, int) {
+// CaptureWithCountdown captures the mouse position on the engine's backend after a countdown
+func (e *Engine) CaptureWithCountdown(prompt string) (int, int) {
 	fmt.Printf("\n%s", prompt)
 	fmt.Print("\nPress any key, then you have 5 seconds to position mouse... ")
 
@@ -28,7 +29,11 @@ func CaptureWithCountdown(prompt string) (int, int) {
 		time.Sleep(1 * time.Second)
 	}
 
-	x, y := robotgo.GetMousePos()
+	x, y, err := e.Backend.MousePos()
+	if err != nil {
+		fmt.Printf("\r❌ ERROR: Could not read the mouse position: %v\n", err)
+		return 0, 0
+	}
 	fmt.Printf("\r✓ Captured at (%d, %d)   \n", x, y)
 	return x, y
 }
@@ -44,10 +49,13 @@ func (e *Engine) ValidateTooltipArea(cfg *config.Config, scanner *bufio.Scanner)
 
 	fmt.Println("\n📸 Capturing and testing tooltip area...")
 	time.Sleep(500 * time.Millisecond)
-	tooltipBitmap := robotgo.CaptureScreen(x1, y1, x2-x1, y2-y1)
-	tooltipImg := robotgo.ToImage(tooltipBitmap)
+	tooltipImg, err := e.Backend.Capture(x1, y1, x2-x1, y2-y1)
+	if err != nil {
+		fmt.Printf("❌ ERROR: Could not capture the tooltip area: %v\n", err)
+		return false
+	}
 
-	tooltipSnapshotFile := filepath.Join(config.SnapshotsDir, "tooltip_area_validation.png")
+	tooltipSnapshotFile := filepath.Join(e.SnapshotsDir, "tooltip_area_validation.png")
 	SaveImage(tooltipImg, tooltipSnapshotFile)
 	fmt.Printf("\n✓ Snapshot saved: %s\n", tooltipSnapshotFile)
 
@@ -55,7 +63,7 @@ func (e *Engine) ValidateTooltipArea(cfg *config.Config, scanner *bufio.Scanner)
 	tempDir := filepath.Join(os.TempDir(), "poe2_crafter_setup")
 	os.MkdirAll(tempDir, 0755)
 
-	ocrText, err := e.RunTesseractOCR(tooltipImg, tempDir, "")
+	ocrText, err := e.RunTesseractOCR(context.Background(), tooltipImg, tempDir, "", cfg.Preprocess)
 	if err != nil {
 		fmt.Printf("\n❌ OCR Error: %v\n", err)
 		return false
@@ -123,13 +131,13 @@ func SetupWizardPreviousConfig(prevConfig config.Config, scanner *bufio.Scanner)
 }
 
 // SetupWizardSelectiveModifications handles selective modifications to existing config
-func SetupWizardSelectiveModifications(cfg config.Config, scanner *bufio.Scanner) config.Config {
+func (e *Engine) SetupWizardSelectiveModifications(cfg config.Config, scanner *bufio.Scanner) config.Config {
 	fmt.Println("\n=== SELECTIVE SETUP ===")
 
 	fmt.Print("\nUpdate chaos orb position? (y/n): ")
 	scanner.Scan()
 	if strings.ToLower(strings.TrimSpace(scanner.Text())) == "y" {
-		cfg.ChaosPos.X, cfg.ChaosPos.Y = CaptureWithCountdown(
+		cfg.ChaosPos.X, cfg.ChaosPos.Y = e.CaptureWithCountdown(
 			"Position for CHAOS ORB in stash")
 		fmt.Printf("✓ Chaos position: (%d, %d)\n", cfg.ChaosPos.X, cfg.ChaosPos.Y)
 	}
@@ -137,9 +145,9 @@ func SetupWizardSelectiveModifications(cfg config.Config, scanner *bufio.Scanner
 	fmt.Print("\nUpdate backpack grid? (y/n): ")
 	scanner.Scan()
 	if strings.ToLower(strings.TrimSpace(scanner.Text())) == "y" {
-		cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y = CaptureWithCountdown(
+		cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y = e.CaptureWithCountdown(
 			"BACKPACK TOP-LEFT corner")
-		cfg.BackpackBottomRight.X, cfg.BackpackBottomRight.Y = CaptureWithCountdown(
+		cfg.BackpackBottomRight.X, cfg.BackpackBottomRight.Y = e.CaptureWithCountdown(
 			"BACKPACK BOTTOM-RIGHT corner")
 		fmt.Printf("✓ Grid: (%d, %d) to (%d, %d)\n",
 			cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y,
@@ -147,7 +155,7 @@ func SetupWizardSelectiveModifications(cfg config.Config, scanner *bufio.Scanner
 
 		fmt.Println("\n📸 Generating grid snapshot...")
 		time.Sleep(300 * time.Millisecond)
-		if err := DrawBackpackGrid(cfg); err != nil {
+		if err := e.DrawBackpackGrid(cfg); err != nil {
 			fmt.Printf("⚠ Warning: Could not create grid snapshot: %v\n", err)
 		} else {
 			fmt.Println("✓ Grid snapshot: backpack_grid_debug.png")
@@ -159,7 +167,7 @@ func SetupWizardSelectiveModifications(cfg config.Config, scanner *bufio.Scanner
 	cfg = setupWizardUpdateLogging(cfg, scanner)
 	cfg = setupWizardUpdateItemDimensions(cfg, scanner)
 	cfg = setupWizardUpdateBatchAreas(cfg, scanner)
-	cfg = setupWizardUpdateTooltip(cfg, scanner)
+	cfg = e.setupWizardUpdateTooltip(cfg, scanner)
 
 	if cfg.UseBatchMode {
 		cfg.ItemPos = cfg.WorkbenchTopLeft
@@ -405,7 +413,7 @@ func setupWizardConfigureResultArea(cfg config.Config, scanner *bufio.Scanner) c
 	return cfg
 }
 
-func setupWizardUpdateTooltip(cfg config.Config, scanner *bufio.Scanner) config.Config {
+func (e *Engine) setupWizardUpdateTooltip(cfg config.Config, scanner *bufio.Scanner) config.Config {
 	fmt.Print("\nUpdate tooltip position? (y/n): ")
 	scanner.Scan()
 	if strings.ToLower(strings.TrimSpace(scanner.Text())) == "y" {
@@ -413,8 +421,8 @@ func setupWizardUpdateTooltip(cfg config.Config, scanner *bufio.Scanner) config.
 		fmt.Println("Position mouse over item and use countdown to capture tooltip area")
 		fmt.Println("")
 
-		x1, y1 := CaptureWithCountdown("Tooltip TOP-LEFT corner")
-		x2, y2 := CaptureWithCountdown("Tooltip BOTTOM-RIGHT corner")
+		x1, y1 := e.CaptureWithCountdown("Tooltip TOP-LEFT corner")
+		x2, y2 := e.CaptureWithCountdown("Tooltip BOTTOM-RIGHT corner")
 
 		cfg.TooltipRect = image.Rectangle{
 			Min: image.Point{X: x1, Y: y1},
@@ -445,7 +453,7 @@ func setupWizardUpdateTooltip(cfg config.Config, scanner *bufio.Scanner) config.
 }
 
 // SetupWizardFullSetup performs full setup for first-time users
-func SetupWizardFullSetup(cfg config.Config, scanner *bufio.Scanner) config.Config {
+func (e *Engine) SetupWizardFullSetup(cfg config.Config, scanner *bufio.Scanner) config.Config {
 	fmt.Println("=== QUICK SETUP ===")
 	fmt.Println()
 
@@ -456,10 +464,10 @@ func SetupWizardFullSetup(cfg config.Config, scanner *bufio.Scanner) config.Conf
 	fmt.Println("and then reference items by cell coordinates (row, col)")
 	fmt.Println()
 
-	cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y = CaptureWithCountdown(
+	cfg.BackpackTopLeft.X, cfg.BackpackTopLeft.Y = e.CaptureWithCountdown(
 		"Step 1a: Position for BACKPACK TOP-LEFT corner")
 
-	cfg.BackpackBottomRight.X, cfg.BackpackBottomRight.Y = CaptureWithCountdown(
+	cfg.BackpackBottomRight.X, cfg.BackpackBottomRight.Y = e.CaptureWithCountdown(
 		"Step 1b: Position for BACKPACK BOTTOM-RIGHT corner")
 
 	fmt.Println("\n\nStep 2: Other Positions")
@@ -467,7 +475,7 @@ func SetupWizardFullSetup(cfg config.Config, scanner *bufio.Scanner) config.Conf
 	fmt.Println("(Tip: Keep POE2 in windowed mode for easier Alt-Tab)")
 	fmt.Println()
 
-	cfg.ChaosPos.X, cfg.ChaosPos.Y = CaptureWithCountdown(
+	cfg.ChaosPos.X, cfg.ChaosPos.Y = e.CaptureWithCountdown(
 		"Step 2a: Position for CHAOS ORB in stash")
 
 	cfg = setupWizardConfigureItemDimensions(cfg, scanner)
@@ -531,8 +539,8 @@ func (e *Engine) SetupWizardConfigureTooltip(cfg config.Config, scanner *bufio.S
 	fmt.Println("--------------------")
 	fmt.Println("⚠️  IMPORTANT: Before capturing corners, hover over an item to show the tooltip!")
 
-	x1, y1 := CaptureWithCountdown("Step 3a: TOP-LEFT corner of tooltip")
-	x2, y2 := CaptureWithCountdown("Step 3b: BOTTOM-RIGHT corner of tooltip")
+	x1, y1 := e.CaptureWithCountdown("Step 3a: TOP-LEFT corner of tooltip")
+	x2, y2 := e.CaptureWithCountdown("Step 3b: BOTTOM-RIGHT corner of tooltip")
 
 	for {
 		cfg.TooltipRect = image.Rectangle{
@@ -554,10 +562,13 @@ func (e *Engine) SetupWizardConfigureTooltip(cfg config.Config, scanner *bufio.S
 
 		fmt.Println("\n📸 Capturing and testing tooltip area...")
 		time.Sleep(500 * time.Millisecond)
-		tooltipBitmap := robotgo.CaptureScreen(x1, y1, x2-x1, y2-y1)
-		tooltipImg := robotgo.ToImage(tooltipBitmap)
+		tooltipImg, err := e.Backend.Capture(x1, y1, x2-x1, y2-y1)
+		if err != nil {
+			fmt.Printf("❌ ERROR: Could not capture the tooltip area: %v\n", err)
+			break
+		}
 
-		tooltipSnapshotFile := filepath.Join(config.SnapshotsDir, "tooltip_area_setup.png")
+		tooltipSnapshotFile := filepath.Join(e.SnapshotsDir, "tooltip_area_setup.png")
 		SaveImage(tooltipImg, tooltipSnapshotFile)
 		fmt.Printf("\n✓ Snapshot saved: %s\n", tooltipSnapshotFile)
 
@@ -565,14 +576,14 @@ func (e *Engine) SetupWizardConfigureTooltip(cfg config.Config, scanner *bufio.S
 		tempDir := filepath.Join(os.TempDir(), "poe2_crafter_setup")
 		os.MkdirAll(tempDir, 0755)
 
-		ocrText, err := e.RunTesseractOCR(tooltipImg, tempDir, "")
+		ocrText, err := e.RunTesseractOCR(context.Background(), tooltipImg, tempDir, "", cfg.Preprocess)
 		if err != nil {
 			fmt.Printf("\n❌ OCR Error: %v\n", err)
 			fmt.Print("\nRetry tooltip selection? (y/n): ")
 			scanner.Scan()
 			if strings.ToLower(strings.TrimSpace(scanner.Text())) == "y" {
-				x1, y1 = CaptureWithCountdown("Re-capture TOP-LEFT corner of tooltip")
-				x2, y2 = CaptureWithCountdown("Re-capture BOTTOM-RIGHT corner of tooltip")
+				x1, y1 = e.CaptureWithCountdown("Re-capture TOP-LEFT corner of tooltip")
+				x2, y2 = e.CaptureWithCountdown("Re-capture BOTTOM-RIGHT corner of tooltip")
 				continue
 			}
 			break
@@ -605,8 +616,8 @@ func (e *Engine) SetupWizardConfigureTooltip(cfg config.Config, scanner *bufio.S
 			fmt.Print("\nRetry tooltip selection? (y/n): ")
 			scanner.Scan()
 			if strings.ToLower(strings.TrimSpace(scanner.Text())) == "y" {
-				x1, y1 = CaptureWithCountdown("Re-capture TOP-LEFT corner of tooltip")
-				x2, y2 = CaptureWithCountdown("Re-capture BOTTOM-RIGHT corner of tooltip")
+				x1, y1 = e.CaptureWithCountdown("Re-capture TOP-LEFT corner of tooltip")
+				x2, y2 = e.CaptureWithCountdown("Re-capture BOTTOM-RIGHT corner of tooltip")
 				continue
 			}
 
@@ -622,21 +633,13 @@ func (e *Engine) SetupWizardConfigureTooltip(cfg config.Config, scanner *bufio.S
 func SetupWizardConfigureModsAndOptions(cfg config.Config, scanner *bufio.Scanner) config.Config {
 	fmt.Println("\n\nStep 4: What Mods Are You Looking For?")
 	fmt.Println("---------------------------------------")
-	fmt.Println("\nFormat: <mod> <min_value>")
+	fmt.Println("\nFormat: <mod> <min_value>  (or <=max, ==value, min-max)")
 	fmt.Println("\nQuick templates:")
-	fmt.Println("  life 80         - Life")
-	fmt.Println("  mana 60         - Mana")
-	fmt.Println("  str 45          - Strength")
-	fmt.Println("  dex 45          - Dexterity")
-	fmt.Println("  int 45          - Intelligence")
-	fmt.Println("  spirit 50       - Spirit")
-	fmt.Println("  spell-level 3   - +3 to Level of all Spell Skills")
-	fmt.Println("  proj-level 3    - +3 to Level of all Projectile Skills")
-	fmt.Println("  crit-dmg 39     - 39% increased Critical Damage Bonus")
-	fmt.Println("  fire-res 30     - Fire Resistance")
-	fmt.Println("  cold-res 30     - Cold Resistance")
-	fmt.Println("  light-res 30    - Lightning Resistance")
-	fmt.Println("  chaos-res 20    - Chaos Resistance")
+	for _, mod := range modcatalog.Default().Mods() {
+		example := fmt.Sprintf("%s %s", mod.ID, modcatalog.FormatValue(mod.Example))
+		fmt.Printf("  %-15s - %s\n", example, mod.Name)
+	}
+	fmt.Printf("\nCustom mods can be added in %s\n", modcatalog.UserModsPath())
 	fmt.Println("\nEnter mods one per line (empty line to finish):")
 	fmt.Println()
 
@@ -669,6 +672,44 @@ func SetupWizardConfigureModsAndOptions(cfg config.Config, scanner *bufio.Scanne
 
 	fmt.Printf("\n✓ Total mods to search: %d\n", len(cfg.TargetMods))
 
+	fmt.Println("\nOptional score formula instead of the mods above, e.g. life + total_res*1.5 + spirit*2")
+	for {
+		fmt.Print("Score formula (or press Enter to skip): ")
+		scanner.Scan()
+		cfg.ScoreFormula = strings.TrimSpace(scanner.Text())
+		if cfg.ScoreFormula == "" {
+			break
+		}
+		if _, err := scoring.Compile(cfg.ScoreFormula); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		fmt.Print("Success at score: ")
+		scanner.Scan()
+		if n, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64); err == nil {
+			cfg.ScoreThreshold = n
+		}
+		fmt.Printf("✓ Success when %s ≥ %s\n", cfg.ScoreFormula, modcatalog.FormatValue(cfg.ScoreThreshold))
+		break
+	}
+
+	fmt.Println("\nOptional affix conditions, e.g. open-suffix, prefixes<=2")
+	for {
+		fmt.Print("Affix conditions, comma separated (or press Enter to skip): ")
+		scanner.Scan()
+		cfg.AffixConditions = nil
+		for _, part := range strings.Split(scanner.Text(), ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				cfg.AffixConditions = append(cfg.AffixConditions, part)
+			}
+		}
+		if _, err := config.ParseAffixConditions(cfg.AffixConditions); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		break
+	}
+
 	fmt.Println("\n\nStep 5: Options")
 	fmt.Println("----------------")
 
@@ -680,6 +721,13 @@ func SetupWizardConfigureModsAndOptions(cfg config.Config, scanner *bufio.Scanne
 		}
 	}
 
+	fmt.Print("Maximise mode - keep rolling for the best value instead of stopping at the first match? (y/n, default n): ")
+	scanner.Scan()
+	cfg.Mode = config.ModeTarget
+	if strings.ToLower(scanner.Text()) == "y" {
+		cfg.Mode = config.ModeMaximise
+	}
+
 	fmt.Print("Enable OCR text logging? (y/n, default n): ")
 	scanner.Scan()
 	cfg.Debug = strings.ToLower(scanner.Text()) == "y"
@@ -724,7 +772,7 @@ func (e *Engine) SetupWizard() config.Config {
 		if !needsMods {
 			fmt.Println("\n✓ Using existing configuration")
 		} else {
-			cfg = SetupWizardSelectiveModifications(cfg, scanner)
+			cfg = e.SetupWizardSelectiveModifications(cfg, scanner)
 		}
 
 		cfg.TooltipRect = image.Rectangle{
@@ -750,7 +798,7 @@ func (e *Engine) SetupWizard() config.Config {
 	needsFullSetup := cfg.ChaosPos.X == 0 && cfg.ChaosPos.Y == 0
 
 	if needsFullSetup {
-		cfg = SetupWizardFullSetup(cfg, scanner)
+		cfg = e.SetupWizardFullSetup(cfg, scanner)
 	}
 
 	cfg = e.SetupWizardConfigureTooltip(cfg, scanner)
@@ -0,0 +1,84 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// keysyms maps key names to X11 keysyms (A-Z map to the lowercase letter, 0-9 to the digit)
+var keysyms = map[string][]xproto.Keysym{
+	"F1": {0xffbe}, "F2": {0xffbf}, "F3": {0xffc0}, "F4": {0xffc1}, "F5": {0xffc2}, "F6": {0xffc3},
+	"F7": {0xffc4}, "F8": {0xffc5}, "F9": {0xffc6}, "F10": {0xffc7}, "F11": {0xffc8}, "F12": {0xffc9},
+	"Pause": {0xff13}, "Escape": {0xff1b}, "Space": {0x20}, "PageUp": {0xff55}, "PageDown": {0xff56},
+	"End": {0xff57}, "Home": {0xff50}, "Insert": {0xff63}, "Delete": {0xffff},
+	"Ctrl": {0xffe3, 0xffe4}, "Shift": {0xffe1, 0xffe2}, "Alt": {0xffe9, 0xffea},
+}
+
+// x11Backend polls the keymap of the X server, which covers every window
+type x11Backend struct {
+	conn     *xgb.Conn
+	keycodes map[xproto.Keysym][]xproto.Keycode
+}
+
+// NewBackend returns the key state backend of this platform
+func NewBackend() (Backend, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("connect to X server: %w", err)
+	}
+
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read keyboard mapping: %w", err)
+	}
+
+	keycodes := make(map[xproto.Keysym][]xproto.Keycode)
+	per := int(mapping.KeysymsPerKeycode)
+	for i := 0; i < int(count); i++ {
+		for j := 0; j < per; j++ {
+			sym := mapping.Keysyms[i*per+j]
+			if sym != 0 {
+				keycodes[sym] = append(keycodes[sym], setup.MinKeycode+xproto.Keycode(i))
+			}
+		}
+	}
+	return &x11Backend{conn: conn, keycodes: keycodes}, nil
+}
+
+func (b *x11Backend) Down(names []string) (map[string]bool, error) {
+	keymap, err := xproto.QueryKeymap(b.conn).Reply()
+	if err != nil {
+		return nil, err
+	}
+
+	down := make(map[string]bool)
+	for _, name := range names {
+		syms := keysyms[name]
+		if syms == nil && len(name) == 1 {
+			syms = []xproto.Keysym{xproto.Keysym(name[0] | 0x20)}
+			if name[0] >= '0' && name[0] <= '9' {
+				syms = []xproto.Keysym{xproto.Keysym(name[0])}
+			}
+		}
+		for _, sym := range syms {
+			for _, code := range b.keycodes[sym] {
+				if keymap.Keys[code/8]&(1<<(code%8)) != 0 {
+					down[name] = true
+				}
+			}
+		}
+	}
+	return down, nil
+}
+
+func (b *x11Backend) Close() error {
+	b.conn.Close()
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows && !linux
+
+package hotkey
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// NewBackend returns the key state backend of this platform
+func NewBackend() (Backend, error) {
+	return nil, fmt.Errorf("global hotkeys are not supported on %s", runtime.GOOS)
+}
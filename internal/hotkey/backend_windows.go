@@ -0,0 +1,49 @@
+//go:build windows
+
+package hotkey
+
+import "syscall"
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	procGetAsyncKeyState = user32.NewProc("GetAsyncKeyState")
+)
+
+// virtualKeys maps key names to Windows virtual-key codes (A-Z and 0-9 are their ASCII codes)
+var virtualKeys = map[string]int{
+	"F1": 0x70, "F2": 0x71, "F3": 0x72, "F4": 0x73, "F5": 0x74, "F6": 0x75,
+	"F7": 0x76, "F8": 0x77, "F9": 0x78, "F10": 0x79, "F11": 0x7A, "F12": 0x7B,
+	"Pause": 0x13, "Escape": 0x1B, "Space": 0x20, "PageUp": 0x21, "PageDown": 0x22,
+	"End": 0x23, "Home": 0x24, "Insert": 0x2D, "Delete": 0x2E,
+	"Ctrl": 0x11, "Shift": 0x10, "Alt": 0x12,
+}
+
+// windowsBackend polls GetAsyncKeyState, which sees keys while the game has focus
+type windowsBackend struct{}
+
+// NewBackend returns the key state backend of this platform
+func NewBackend() (Backend, error) {
+	return windowsBackend{}, nil
+}
+
+func (windowsBackend) Down(names []string) (map[string]bool, error) {
+	down := make(map[string]bool)
+	for _, name := range names {
+		vk, ok := virtualKeys[name]
+		if !ok && len(name) == 1 {
+			vk, ok = int(name[0]), true
+		}
+		if !ok {
+			continue
+		}
+		state, _, _ := procGetAsyncKeyState.Call(uintptr(vk))
+		if state&0x8000 != 0 {
+			down[name] = true
+		}
+	}
+	return down, nil
+}
+
+func (windowsBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package hotkey
+
+import "sync"
+
+// FakeBackend is a Backend whose keys are pressed from code, for tests and headless runs
+type FakeBackend struct {
+	mu     sync.Mutex
+	down   map[string]bool
+	tapped map[string]bool
+}
+
+// NewFakeBackend creates a backend with no keys held
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{down: make(map[string]bool), tapped: make(map[string]bool)}
+}
+
+// Press holds the named keys down until Release
+func (f *FakeBackend) Press(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		f.down[name] = true
+	}
+}
+
+// Release lets go of the named keys
+func (f *FakeBackend) Release(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		delete(f.down, name)
+	}
+}
+
+// Tap presses a combo for exactly one poll, so the listener sees it however fast it runs
+func (f *FakeBackend) Tap(c Combo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range c.keys() {
+		f.tapped[name] = true
+	}
+}
+
+// Down reports held and tapped keys; taps are cleared once reported
+func (f *FakeBackend) Down(names []string) (map[string]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	down := make(map[string]bool)
+	for _, name := range names {
+		if f.down[name] || f.tapped[name] {
+			down[name] = true
+		}
+	}
+	f.tapped = make(map[string]bool)
+	return down, nil
+}
+
+// Close does nothing
+func (f *FakeBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package hotkey watches global keyboard shortcuts in a background goroutine and reports
+// bound key presses as actions. Key state comes from a swappable Backend.
+package hotkey
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is what a bound key does
+type Action string
+
+// Bindable actions
+const (
+	ActionPause Action = "pause" // Pause or resume crafting
+	ActionStop  Action = "stop"  // Emergency stop
+	ActionSkip  Action = "skip"  // Stop rolling the current item and move on
+	ActionMark  Action = "mark"  // Mark the latest roll for the report
+)
+
+// Actions lists every bindable action
+var Actions = []Action{ActionPause, ActionStop, ActionSkip, ActionMark}
+
+// Modifier key names
+var modifiers = []string{"Ctrl", "Shift", "Alt"}
+
+// keyNames are the key names a binding may use, besides A-Z and 0-9
+var keyNames = []string{
+	"F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12",
+	"Pause", "Escape", "Space", "Insert", "Delete", "Home", "End", "PageUp", "PageDown",
+	"Ctrl", "Shift", "Alt",
+}
+
+// Combo is a key with optional modifiers, e.g. Ctrl+F12
+type Combo struct {
+	Key       string
+	Modifiers []string // Sorted subset of Ctrl, Shift, Alt
+}
+
+// Bindings maps each action to its key combination
+type Bindings map[Action]Combo
+
+// DefaultBindings are used for actions the profile does not bind
+func DefaultBindings() Bindings {
+	return Bindings{
+		ActionPause: {Key: "F12"},
+		ActionStop:  {Key: "F12", Modifiers: []string{"Ctrl"}},
+		ActionSkip:  {Key: "F11"},
+		ActionMark:  {Key: "F10"},
+	}
+}
+
+// ParseCombo reads a binding such as "F12", "ctrl+f12" or "Shift+Alt+P"
+func ParseCombo(s string) (Combo, error) {
+	parts := strings.Split(strings.ReplaceAll(s, " ", ""), "+")
+	var c Combo
+	for i, part := range parts {
+		name := canonicalName(part)
+		if name == "" {
+			return Combo{}, fmt.Errorf("unknown key %q in %q", part, s)
+		}
+		if i < len(parts)-1 {
+			if !isModifier(name) {
+				return Combo{}, fmt.Errorf("%q is not a modifier (use Ctrl, Shift or Alt before the key)", part)
+			}
+			c.Modifiers = append(c.Modifiers, name)
+			continue
+		}
+		c.Key = name
+	}
+	sort.Strings(c.Modifiers)
+	return c, nil
+}
+
+// ParseBindings reads profile bindings (action → combo) on top of the defaults
+func ParseBindings(config map[string]string) (Bindings, error) {
+	b := DefaultBindings()
+	for action, key := range config {
+		if !validAction(Action(action)) {
+			return nil, fmt.Errorf("unknown hotkey action %q (use pause, stop, skip or mark)", action)
+		}
+		combo, err := ParseCombo(key)
+		if err != nil {
+			return nil, err
+		}
+		b[Action(action)] = combo
+	}
+
+	seen := make(map[string]Action)
+	for _, action := range Actions {
+		name := b[action].String()
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s is bound to both %s and %s", name, other, action)
+		}
+		seen[name] = action
+	}
+	return b, nil
+}
+
+// String renders the combo in binding form, e.g. "Ctrl+F12"
+func (c Combo) String() string {
+	return strings.Join(append(append([]string{}, c.Modifiers...), c.Key), "+")
+}
+
+// keys returns every key name of the combo
+func (c Combo) keys() []string {
+	return append(append([]string{}, c.Modifiers...), c.Key)
+}
+
+// active reports whether the combo's key and modifiers are held. Other modifiers may be
+// down as well: the engine holds Shift while rolling, and its synthetic presses show up in
+// the key state like real ones. See covers for telling F12 and Ctrl+F12 apart.
+func (c Combo) active(down map[string]bool) bool {
+	for _, name := range c.keys() {
+		if !down[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// covers reports whether c is a more specific form of other: the same key with more
+// modifiers, like Ctrl+F12 for F12
+func (c Combo) covers(other Combo) bool {
+	if c.Key != other.Key || len(c.Modifiers) <= len(other.Modifiers) {
+		return false
+	}
+	for _, m := range other.Modifiers {
+		if !contains(c.Modifiers, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalName maps a key name in any case to its canonical spelling
+func canonicalName(s string) string {
+	switch strings.ToLower(s) {
+	case "control", "ctl":
+		return "Ctrl"
+	case "esc":
+		return "Escape"
+	case "del":
+		return "Delete"
+	case "ins":
+		return "Insert"
+	case "pgup":
+		return "PageUp"
+	case "pgdn":
+		return "PageDown"
+	}
+	if len(s) == 1 {
+		c := strings.ToUpper(s)[0]
+		if c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			return string(c)
+		}
+	}
+	for _, name := range keyNames {
+		if strings.EqualFold(s, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func isModifier(name string) bool {
+	return contains(modifiers, name)
+}
+
+func validAction(a Action) bool {
+	for _, action := range Actions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package hotkey
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCombo(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"F12", "F12"},
+		{"f12", "F12"},
+		{"ctrl+f12", "Ctrl+F12"},
+		{"Shift + Alt + p", "Alt+Shift+P"},
+		{"Control+Esc", "Ctrl+Escape"},
+		{"pgdn", "PageDown"},
+		{"7", "7"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCombo(tt.in)
+		if err != nil {
+			t.Errorf("ParseCombo(%q): %v", tt.in, err)
+			continue
+		}
+		if c.String() != tt.want {
+			t.Errorf("ParseCombo(%q) = %s, want %s", tt.in, c, tt.want)
+		}
+	}
+
+	for _, in := range []string{"F13", "Ctrl+", "Hyper+F1", "F1+F2", "", "é"} {
+		if c, err := ParseCombo(in); err == nil {
+			t.Errorf("ParseCombo(%q) = %s, want an error", in, c)
+		}
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	b, err := ParseBindings(map[string]string{"pause": "Pause", "mark": "ctrl+m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Action]string{ActionPause: "Pause", ActionStop: "Ctrl+F12", ActionSkip: "F11", ActionMark: "Ctrl+M"}
+	for action, combo := range want {
+		if b[action].String() != combo {
+			t.Errorf("%s is bound to %s, want %s", action, b[action], combo)
+		}
+	}
+
+	invalid := map[string]map[string]string{
+		"unknown action": {"jump": "F1"},
+		"invalid key":    {"pause": "F99"},
+		"bound twice":    {"skip": "F12"},
+		"same combo":     {"mark": "Ctrl+F12"},
+	}
+	for name, config := range invalid {
+		if _, err := ParseBindings(config); err == nil {
+			t.Errorf("%s: ParseBindings(%v) did not fail", name, config)
+		}
+	}
+}
+
+// closeCounter counts Close calls on a FakeBackend
+type closeCounter struct {
+	*FakeBackend
+	closed atomic.Int32
+}
+
+func (c *closeCounter) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+// startListener runs a listener with the default bindings and returns its actions
+func startListener(t *testing.T) (*closeCounter, *Listener, chan Action) {
+	t.Helper()
+	backend := &closeCounter{FakeBackend: NewFakeBackend()}
+	l := NewListener(backend, DefaultBindings())
+	actions := make(chan Action, 16)
+	l.Start(func(a Action) { actions <- a })
+	return backend, l, actions
+}
+
+func expectAction(t *testing.T, actions chan Action, want Action) {
+	t.Helper()
+	select {
+	case got := <-actions:
+		if got != want {
+			t.Fatalf("got action %s, want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no %s action within a second", want)
+	}
+}
+
+func expectNoAction(t *testing.T, actions chan Action) {
+	t.Helper()
+	select {
+	case got := <-actions:
+		t.Fatalf("unexpected action %s", got)
+	case <-time.After(5 * pollInterval):
+	}
+}
+
+func TestListenerFiresOncePerPress(t *testing.T) {
+	backend, l, actions := startListener(t)
+	defer l.Stop()
+
+	backend.Press("F12")
+	expectAction(t, actions, ActionPause)
+	expectNoAction(t, actions) // Still held: no repeat
+
+	backend.Release("F12")
+	time.Sleep(3 * pollInterval)
+	backend.Press("F12")
+	expectAction(t, actions, ActionPause)
+	backend.Release("F12")
+}
+
+func TestListenerTellsModifiersApart(t *testing.T) {
+	backend, l, actions := startListener(t)
+	defer l.Stop()
+
+	backend.Tap(Combo{Key: "F12", Modifiers: []string{"Ctrl"}})
+	expectAction(t, actions, ActionStop)
+	expectNoAction(t, actions) // Ctrl+F12 is not also a pause
+
+	backend.Press("Ctrl", "F12")
+	expectAction(t, actions, ActionStop)
+	backend.Release("Ctrl")
+	expectNoAction(t, actions) // F12 was already down, so letting go of Ctrl is no new press
+	backend.Release("F12")
+}
+
+// The engine holds Shift down for the whole roll loop
+func TestListenerFiresWithShiftHeld(t *testing.T) {
+	backend, l, actions := startListener(t)
+	defer l.Stop()
+	backend.Press("Shift")
+
+	backend.Tap(Combo{Key: "F12"})
+	expectAction(t, actions, ActionPause)
+	backend.Tap(Combo{Key: "F12", Modifiers: []string{"Ctrl"}})
+	expectAction(t, actions, ActionStop)
+	backend.Tap(Combo{Key: "F11"})
+	expectAction(t, actions, ActionSkip)
+	expectNoAction(t, actions)
+}
+
+func TestListenerStop(t *testing.T) {
+	backend, l, actions := startListener(t)
+
+	stopped := make(chan struct{})
+	go func() {
+		l.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+	if n := backend.closed.Load(); n != 1 {
+		t.Errorf("backend closed %d times, want once", n)
+	}
+
+	backend.Press("F12")
+	expectNoAction(t, actions)
+}
@@ -0,0 +1,103 @@
+package hotkey
+
+import (
+	"fmt"
+	"time"
+)
+
+// Backend reads the current state of keyboard keys
+type Backend interface {
+	// Down reports which of the named keys are held down
+	Down(names []string) (map[string]bool, error)
+	Close() error
+}
+
+// pollInterval is how often the listener reads the key state
+const pollInterval = 30 * time.Millisecond
+
+// Listener polls a backend in its own goroutine and reports bound key presses
+type Listener struct {
+	backend  Backend
+	bindings Bindings
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewListener creates a listener for the given bindings
+func NewListener(backend Backend, bindings Bindings) *Listener {
+	return &Listener{
+		backend:  backend,
+		bindings: bindings,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins polling. handle is called from the listener goroutine once per key press.
+func (l *Listener) Start(handle func(Action)) {
+	go l.run(handle)
+}
+
+// Stop ends polling and closes the backend
+func (l *Listener) Stop() {
+	close(l.stop)
+	<-l.done
+	l.backend.Close()
+}
+
+func (l *Listener) run(handle func(Action)) {
+	defer close(l.done)
+
+	names := append([]string{}, modifiers...)
+	for _, combo := range l.bindings {
+		for _, name := range combo.keys() {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	held := make(map[Action]bool)
+	failing := false
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		down, err := l.backend.Down(names)
+		if err != nil {
+			if !failing {
+				fmt.Printf("\n⚠ Warning: Could not read hotkeys: %v\n", err)
+				failing = true
+			}
+			continue
+		}
+		failing = false
+
+		active := make(map[Action]bool)
+		for action, combo := range l.bindings {
+			active[action] = combo.active(down)
+		}
+		for _, action := range Actions {
+			combo, ok := l.bindings[action]
+			if !ok {
+				continue
+			}
+			// With Ctrl+F12 held only the Ctrl+F12 binding fires, not the F12 one as well
+			fires := active[action] && !held[action]
+			for other, c := range l.bindings {
+				if fires && active[other] && c.covers(combo) {
+					fires = false
+				}
+			}
+			if fires {
+				handle(action)
+			}
+			held[action] = active[action]
+		}
+	}
+}
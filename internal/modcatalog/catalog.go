@@ -0,0 +1,397 @@
+// Package modcatalog holds the mod definitions shared by the OCR tracker, the target
+// matcher, the web templates endpoint and the CLI wizard.
+package modcatalog
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed data/mods.json data/bases.json data/lang/*.json
+var dataFiles embed.FS
+
+// Kind describes how a mod's value reads
+type Kind string
+
+const (
+	Flat    Kind = "flat"    // +80 to maximum Life
+	Percent Kind = "percent" // 30% to Fire Resistance
+	Level   Kind = "level"   // +3 to Level of all Spell Skills
+)
+
+// DefaultLanguage is used when a language code is empty or unknown
+const DefaultLanguage = "en"
+
+// Affix groups, taken from the "prefix"/"suffix" tag of a mod
+const (
+	Prefix = "prefix"
+	Suffix = "suffix"
+)
+
+// Affix limits of a rare item
+const (
+	MaxPrefixes = 3
+	MaxSuffixes = 3
+)
+
+// Localized is a mod as it appears in one game client language
+type Localized struct {
+	Name        string `json:"name"`        // Display name in the game language
+	Pattern     string `json:"pattern"`     // Regex with a capture group per value, see ExpandPattern for {int}/{dec}
+	Description string `json:"description"` // Target description format, e.g. "Life %d+"
+
+	re    *regexp.Regexp
+	names []string
+}
+
+// Regexp returns the compiled pattern with template shorthands expanded
+func (l *Localized) Regexp() *regexp.Regexp {
+	return l.re
+}
+
+// ValueNames returns the names of the values captured by the pattern
+func (l *Localized) ValueNames() []string {
+	return l.names
+}
+
+// Mod is one catalog entry
+type Mod struct {
+	ID        string                `json:"id"`   // Input keyword, e.g. "life"
+	Name      string                `json:"name"` // Statistics name, the same in every language
+	Kind      Kind                  `json:"kind"`
+	Tags      []string              `json:"tags"` // "prefix"/"suffix" and the item classes it rolls on
+	Example   float64               `json:"example"`
+	Derive    string                `json:"derive,omitempty"` // Multi-value mods: "avg", "sum", "min" or "max" of the values
+	Languages map[string]*Localized `json:"languages,omitempty"`
+}
+
+// Localized returns the mod text for a language, falling back to English
+func (m *Mod) Localized(lang string) *Localized {
+	if l, ok := m.Languages[lang]; ok {
+		return l
+	}
+	return m.Languages[DefaultLanguage]
+}
+
+// Group returns Prefix or Suffix, or "" when the mod is tagged as neither
+func (m *Mod) Group() string {
+	switch {
+	case m.HasTag(Prefix):
+		return Prefix
+	case m.HasTag(Suffix):
+		return Suffix
+	}
+	return ""
+}
+
+// HasTag reports whether the mod carries the given tag
+func (m *Mod) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Language holds OCR settings for one game client language
+type Language struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`                // Native language name
+	Tesseract string `json:"tesseract"`           // Tesseract traineddata name
+	Whitelist string `json:"whitelist,omitempty"` // Optional tessedit_char_whitelist
+	Labels    Labels `json:"labels"`
+}
+
+// Labels are the tooltip header captions in one game language
+type Labels struct {
+	ItemClass string            `json:"itemClass"` // "Item Class"
+	Rarity    string            `json:"rarity"`    // "Rarity"
+	ItemLevel string            `json:"itemLevel"` // "Item Level"
+	Rarities  map[string]string `json:"rarities"`  // Localized rarity name -> normal/magic/rare/unique
+	Flags     map[string]string `json:"flags"`     // Localized tooltip line -> corrupted/mirrored/unmodifiable/fractured
+}
+
+// baseKeyword maps a word of a base type name to its item class
+type baseKeyword struct {
+	re    *regexp.Regexp
+	class string
+	size  int
+}
+
+// Match is a mod line recognized in OCR text
+type Match struct {
+	Mod    *Mod
+	Value  float64            // Single value, or the derived value of a multi-value mod
+	Values map[string]float64 // Every captured value by name
+	Start  int                // Byte offsets of the matched text
+	End    int
+}
+
+// Catalog is a set of mods and the languages they are translated into
+type Catalog struct {
+	mods      []*Mod
+	byID      map[string]*Mod
+	languages map[string]*Language
+	bases     []baseKeyword // Longest keyword first
+}
+
+// langFile is the on-disk layout of data/lang/<code>.json
+type langFile struct {
+	Language
+	Mods map[string]*Localized `json:"mods"`
+}
+
+// LoadBuiltin loads the catalog embedded in the binary
+func LoadBuiltin() (*Catalog, error) {
+	c := &Catalog{byID: make(map[string]*Mod), languages: make(map[string]*Language)}
+
+	data, err := dataFiles.ReadFile("data/mods.json")
+	if err != nil {
+		return nil, err
+	}
+	var mods []*Mod
+	if err := json.Unmarshal(data, &mods); err != nil {
+		return nil, fmt.Errorf("mods.json: %w", err)
+	}
+	for _, mod := range mods {
+		mod.Languages = make(map[string]*Localized)
+		c.add(mod)
+	}
+
+	entries, err := dataFiles.ReadDir("data/lang")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := dataFiles.ReadFile(path.Join("data/lang", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var lf langFile
+		if err := json.Unmarshal(data, &lf); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		lang := lf.Language
+		c.languages[lang.Code] = &lang
+		for id, loc := range lf.Mods {
+			mod, ok := c.byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown mod %q", entry.Name(), id)
+			}
+			mod.Languages[lang.Code] = loc
+		}
+	}
+
+	data, err = dataFiles.ReadFile("data/bases.json")
+	if err != nil {
+		return nil, err
+	}
+	var bases map[string][]string
+	if err := json.Unmarshal(data, &bases); err != nil {
+		return nil, fmt.Errorf("bases.json: %w", err)
+	}
+	for class, keywords := range bases {
+		for _, kw := range keywords {
+			re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(kw))
+			c.bases = append(c.bases, baseKeyword{re: re, class: class, size: len(kw)})
+		}
+	}
+	sort.Slice(c.bases, func(i, j int) bool {
+		if c.bases[i].size != c.bases[j].size {
+			return c.bases[i].size > c.bases[j].size
+		}
+		return c.bases[i].class < c.bases[j].class
+	})
+
+	if err := c.compile(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UserModsPath returns the path of the optional user mod file
+func UserModsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".poe2_crafter_mods.json")
+}
+
+// LoadUserMods adds mods from a JSON file (a list of mods with their languages).
+// Entries with an existing id replace the built-in definition. The file is checked as a
+// whole: when any entry is invalid, none of them are added.
+func (c *Catalog) LoadUserMods(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var mods []*Mod
+	if err := json.Unmarshal(data, &mods); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	for _, mod := range mods {
+		if mod.ID == "" || len(mod.Languages) == 0 {
+			return fmt.Errorf("%s: mod needs an id and at least one language", filename)
+		}
+		if mod.Name == "" {
+			mod.Name = mod.ID
+		}
+		if mod.Kind == "" {
+			mod.Kind = Flat
+		}
+		if err := compileMod(mod); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	for _, mod := range mods {
+		c.add(mod)
+	}
+	return nil
+}
+
+func (c *Catalog) add(mod *Mod) {
+	if _, exists := c.byID[mod.ID]; exists {
+		for i, m := range c.mods {
+			if m.ID == mod.ID {
+				c.mods[i] = mod
+			}
+		}
+	} else {
+		c.mods = append(c.mods, mod)
+	}
+	c.byID[mod.ID] = mod
+}
+
+func (c *Catalog) compile() error {
+	for _, mod := range c.mods {
+		if err := compileMod(mod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileMod compiles the patterns of every language of mod that is not compiled yet
+func compileMod(mod *Mod) error {
+	for code, loc := range mod.Languages {
+		if loc == nil {
+			return fmt.Errorf("mod %q (%s): no pattern", mod.ID, code)
+		}
+		if loc.re != nil {
+			continue
+		}
+		re, err := regexp.Compile(ExpandPattern(loc.Pattern))
+		if err != nil {
+			return fmt.Errorf("mod %q (%s): %w", mod.ID, code, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("mod %q (%s): pattern needs a capture group for the value", mod.ID, code)
+		}
+		loc.re = re
+		loc.names = ValueNames(re)
+	}
+	return nil
+}
+
+// Mods returns all mods in catalog order
+func (c *Catalog) Mods() []*Mod {
+	return c.mods
+}
+
+// Mod returns a mod by id, or nil if unknown
+func (c *Catalog) Mod(id string) *Mod {
+	return c.byID[id]
+}
+
+// Language returns the settings for a language code, falling back to English
+func (c *Catalog) Language(code string) *Language {
+	if lang, ok := c.languages[code]; ok {
+		return lang
+	}
+	return c.languages[DefaultLanguage]
+}
+
+// ItemClass guesses the item class ("ring", "body_armour", ...) from an English base type
+// or "Item Class" value. Returns "" when no keyword matches.
+func (c *Catalog) ItemClass(base string) string {
+	base = strings.TrimSpace(base)
+	for _, kw := range c.bases {
+		if kw.re.MatchString(base) {
+			return kw.class
+		}
+	}
+	return ""
+}
+
+// Languages returns all supported languages, English first
+func (c *Catalog) Languages() []*Language {
+	var langs []*Language
+	for _, lang := range c.languages {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if (langs[i].Code == DefaultLanguage) != (langs[j].Code == DefaultLanguage) {
+			return langs[i].Code == DefaultLanguage
+		}
+		return langs[i].Code < langs[j].Code
+	})
+	return langs
+}
+
+// Parse extracts every catalog mod and its value from OCR text
+func (c *Catalog) Parse(text string, lang string) []Match {
+	var matches []Match
+	for _, mod := range c.mods {
+		loc := mod.Localized(lang)
+		if loc == nil {
+			continue
+		}
+		for _, idx := range loc.re.FindAllStringSubmatchIndex(text, -1) {
+			m := make([]string, len(idx)/2)
+			for i := range m {
+				if idx[2*i] >= 0 {
+					m[i] = text[idx[2*i]:idx[2*i+1]]
+				}
+			}
+			values, ok := ExtractValues(loc.names, m)
+			if !ok {
+				continue
+			}
+			matches = append(matches, Match{
+				Mod:    mod,
+				Value:  Derive(mod.Derive, loc.names, values),
+				Values: values,
+				Start:  idx[0],
+				End:    idx[1],
+			})
+		}
+	}
+	return matches
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the built-in catalog merged with the user mod file, loaded once
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := LoadBuiltin()
+		if err != nil {
+			panic(err)
+		}
+		if err := c.LoadUserMods(UserModsPath()); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("⚠ Could not load custom mods: %v\n", err)
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
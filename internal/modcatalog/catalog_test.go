@@ -0,0 +1,175 @@
+package modcatalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeUserMods(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mods.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadUserMods(t *testing.T) {
+	c, err := LoadBuiltin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeUserMods(t, `[
+		{"id": "foo", "languages": {"en": {"name": "Foo", "pattern": "(\\d+) foo"}}},
+		{"id": "life", "name": "Life", "tags": ["prefix"], "languages": {"en": {"pattern": "(\\d+) life"}}}
+	]`)
+	if err := c.LoadUserMods(path); err != nil {
+		t.Fatal(err)
+	}
+
+	foo := c.Mod("foo")
+	if foo == nil || foo.Name != "foo" || foo.Kind != Flat {
+		t.Fatalf("Mod(foo) = %+v, want a flat mod named foo", foo)
+	}
+	matches := c.Parse("12 foo\n85 life", "en")
+	got := make(map[string]float64)
+	for _, m := range matches {
+		got[m.Mod.ID] = m.Value
+	}
+	if got["foo"] != 12 || got["life"] != 85 {
+		t.Errorf("Parse() = %v, want foo 12 and life 85", got)
+	}
+}
+
+func TestLoadUserModsRejectsWholeFile(t *testing.T) {
+	tests := map[string]string{
+		"bad regex": `[
+			{"id": "foo", "languages": {"en": {"pattern": "(\\d+) foo"}}},
+			{"id": "bar", "languages": {"en": {"pattern": "([ bad"}}}
+		]`,
+		"no capture group": `[
+			{"id": "foo", "languages": {"en": {"pattern": "(\\d+) foo"}}},
+			{"id": "bar", "languages": {"en": {"pattern": "bar"}}}
+		]`,
+		"no language": `[
+			{"id": "foo", "languages": {"en": {"pattern": "(\\d+) foo"}}},
+			{"id": "bar"}
+		]`,
+		"null language": `[
+			{"id": "foo", "languages": {"en": {"pattern": "(\\d+) foo"}}},
+			{"id": "bar", "languages": {"en": null}}
+		]`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := LoadBuiltin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			mods := len(c.Mods())
+			if err := c.LoadUserMods(writeUserMods(t, content)); err == nil {
+				t.Fatal("LoadUserMods() accepted an invalid file")
+			}
+			if c.Mod("foo") != nil || len(c.Mods()) != mods {
+				t.Error("mods before the invalid entry were added")
+			}
+			// The catalog must stay usable
+			if matches := c.Parse("12 foo\n+85 to maximum Life", "en"); len(matches) != 1 {
+				t.Errorf("Parse() found %d mods, want only Life", len(matches))
+			}
+		})
+	}
+}
+
+func TestBuiltinLanguagesTranslateEveryMod(t *testing.T) {
+	c, err := LoadBuiltin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lang := range c.Languages() {
+		for _, mod := range c.Mods() {
+			if _, ok := mod.Languages[lang.Code]; !ok {
+				t.Errorf("%s.json has no translation of %q", lang.Code, mod.ID)
+			}
+		}
+	}
+}
+
+func TestTranslatedMultiValueMods(t *testing.T) {
+	tests := map[string][]string{
+		"fr": {
+			"Ajoute 5 à 11 dégâts physiques",
+			"Ajoute 5 à 11 dégâts de feu",
+			"Ajoute 5 à 11 dégâts de froid",
+			"Ajoute 5 à 11 dégâts de foudre",
+			"25% d'augmentation de l'Armure\n+30 à la Vie maximale",
+			"7,5% des dégâts d'attaque physiques sont drainés sous forme de Vie",
+			"Régénère 10,5 points de Vie par seconde",
+		},
+		"ja": {
+			"物理ダメージを5から11追加する",
+			"火ダメージを5から11追加する",
+			"冷気ダメージを5から11追加する",
+			"雷ダメージを5から11追加する",
+			"アーマーが25%増加\n最大ライフ +30",
+			"物理アタックダメージの7.5%をライフとしてリーチする",
+			"毎秒ライフを10.5再生する",
+		},
+		"ko": {
+			"물리 피해 5~11 추가",
+			"화염 피해 5~11 추가",
+			"냉기 피해 5~11 추가",
+			"번개 피해 5~11 추가",
+			"방어도 25% 증가\n최대 생명력 +30",
+			"물리 공격 피해의 7.5%를 생명력으로 흡수",
+			"초당 생명력 10.5 재생",
+		},
+		"pt-BR": {
+			"Adiciona 5 a 11 de Dano Físico",
+			"Adiciona 5 a 11 de Dano de Fogo",
+			"Adiciona 5 a 11 de Dano de Frio",
+			"Adiciona 5 a 11 de Dano de Raio",
+			"25% de aumento de Armadura\n+30 de Vida máxima",
+			"7,5% do Dano de Ataque Físico é drenado como Vida",
+			"Regenera 10,5 de Vida por segundo",
+		},
+		"ru": {
+			"Добавляет от 5 до 11 физического урона",
+			"Добавляет от 5 до 11 урона от огня",
+			"Добавляет от 5 до 11 урона от холода",
+			"Добавляет от 5 до 11 урона от молнии",
+			"25% увеличение брони\n+30 к максимуму здоровья",
+			"7,5% физического урона от атак похищается в виде здоровья",
+			"Регенерация 10,5 здоровья в секунду",
+		},
+	}
+	want := []struct {
+		id    string
+		value float64
+	}{
+		{"phys-dmg", 8}, {"fire-dmg", 8}, {"cold-dmg", 8}, {"light-dmg", 8},
+		{"armour-life", 25}, {"leech-life", 7.5}, {"life-regen", 10.5},
+	}
+
+	c, err := LoadBuiltin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for lang, lines := range tests {
+		for i, line := range lines {
+			found := false
+			for _, m := range c.Parse(line, lang) {
+				if m.Mod.ID == want[i].id {
+					found = true
+					if m.Value != want[i].value {
+						t.Errorf("%s %q: %s = %v, want %v", lang, line, m.Mod.ID, m.Value, want[i].value)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%s %q: %s not found", lang, line, want[i].id)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package modcatalog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patternMacro matches the template shorthands {int}, {dec}, {int:name} and {dec:name}
+var patternMacro = regexp.MustCompile(`\{(int|dec)(?::([A-Za-z_][A-Za-z0-9_]*))?\}`)
+
+// ExpandPattern replaces template shorthands with capture groups: {int} captures a whole
+// number, {dec} a number with an optional decimal part ("0.5" or "0,5"). A :name suffix
+// names the value, e.g. {dec:min}. Plain regexes are returned unchanged.
+func ExpandPattern(pattern string) string {
+	return patternMacro.ReplaceAllStringFunc(pattern, func(m string) string {
+		parts := patternMacro.FindStringSubmatch(m)
+		group := `\d+`
+		if parts[1] == "dec" {
+			group = `\d+(?:[.,]\d+)?`
+		}
+		if parts[2] != "" {
+			return "(?P<" + parts[2] + ">" + group + ")"
+		}
+		return "(" + group + ")"
+	})
+}
+
+// ValueNames returns the value names of a pattern's capture groups. Named groups such as
+// (?P<min>\d+) keep their name; unnamed groups become "value", "value2", ...
+func ValueNames(re *regexp.Regexp) []string {
+	var names []string
+	for i, name := range re.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		if name == "" {
+			name = "value"
+			if i > 1 {
+				name = fmt.Sprintf("value%d", i)
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// ParseValue reads a captured number, accepting a comma as decimal separator
+func ParseValue(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+}
+
+// FormatValue prints a value without trailing zeros (80, 0.5, 1.25)
+func FormatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ExtractValues converts a submatch into named values. Optional groups that did not
+// participate are skipped; ok is false when no group holds a number.
+func ExtractValues(names []string, match []string) (map[string]float64, bool) {
+	values := make(map[string]float64)
+	for i, name := range names {
+		if i+1 >= len(match) || match[i+1] == "" {
+			continue
+		}
+		v, err := ParseValue(match[i+1])
+		if err != nil {
+			return nil, false
+		}
+		values[name] = v
+	}
+	return values, len(values) > 0
+}
+
+// Derive combines a mod's values into the single number compared against thresholds.
+// method is "avg", "sum", "min" or "max"; anything else returns the first value.
+func Derive(method string, names []string, values map[string]float64) float64 {
+	var present []float64
+	for _, name := range names {
+		if v, ok := values[name]; ok {
+			present = append(present, v)
+		}
+	}
+	if len(present) == 0 {
+		return 0
+	}
+
+	result := present[0]
+	switch method {
+	case "avg", "sum":
+		sum := 0.0
+		for _, v := range present {
+			sum += v
+		}
+		result = sum
+		if method == "avg" {
+			result = sum / float64(len(present))
+		}
+	case "min":
+		for _, v := range present {
+			result = min(result, v)
+		}
+	case "max":
+		for _, v := range present {
+			result = max(result, v)
+		}
+	}
+	return result
+}
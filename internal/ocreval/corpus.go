@@ -0,0 +1,164 @@
+package ocreval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"poe2-chaos-crafter/internal/engine"
+)
+
+// Expectation is the hand-checked content of a corpus tooltip
+type Expectation struct {
+	Mods    []engine.ParsedMod `json:"mods"`              // Mods TrackMods should find (name + value)
+	Targets []TargetCase       `json:"targets,omitempty"` // Optional CheckAnyMod cases
+	Text    string             `json:"text,omitempty"`    // What the tooltip says, as read by a human
+	Notes   string             `json:"notes,omitempty"`
+}
+
+// TargetCase checks CheckAnyMod against a mod input such as "life 80"
+type TargetCase struct {
+	Input string  `json:"input"`
+	Match bool    `json:"match"`
+	Value float64 `json:"value,omitempty"` // Expected matched value (0 = don't check)
+}
+
+// Sample is one JSON expectation in the corpus, usually with a PNG screenshot
+type Sample struct {
+	Name      string
+	Language  string
+	ImagePath string // Empty for transcript-only samples
+	Expected  Expectation
+}
+
+var sampleNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// LoadCorpus reads all samples below root. The layout is root/<lang>/<name>.json, with the
+// screenshot in <name>.png. A sample without a screenshot needs the tooltip text instead.
+// When langs is non-empty only those language folders are read.
+func LoadCorpus(root string, langs ...string) ([]Sample, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+
+	var samples []Sample
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		lang := entry.Name()
+		if len(langs) > 0 && !contains(langs, lang) {
+			continue
+		}
+
+		names := make(map[string]bool)
+		for _, ext := range []string{"*.png", "*.json"} {
+			files, err := filepath.Glob(filepath.Join(root, lang, ext))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				names[strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))] = true
+			}
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			data, err := os.ReadFile(filepath.Join(root, lang, name+".json"))
+			if err != nil {
+				return nil, fmt.Errorf("sample %s/%s has no expectation file: %w", lang, name, err)
+			}
+
+			var exp Expectation
+			if err := json.Unmarshal(data, &exp); err != nil {
+				return nil, fmt.Errorf("sample %s/%s: invalid JSON: %w", lang, name, err)
+			}
+
+			imagePath := filepath.Join(root, lang, name+".png")
+			if _, err := os.Stat(imagePath); err != nil {
+				if exp.Text == "" {
+					return nil, fmt.Errorf("sample %s/%s has neither a screenshot nor a text", lang, name)
+				}
+				imagePath = ""
+			}
+
+			samples = append(samples, Sample{
+				Name:      name,
+				Language:  lang,
+				ImagePath: imagePath,
+				Expected:  exp,
+			})
+		}
+	}
+	return samples, nil
+}
+
+// PromoteImage copies a captured tooltip into the corpus and writes its expectation file.
+// The expectation is usually pre-filled from the live OCR result and reviewed by hand afterwards.
+func PromoteImage(root, lang, name, srcPath string, exp Expectation) (string, error) {
+	if lang == "" {
+		lang = "en"
+	}
+	if !sampleNameRe.MatchString(name) || !sampleNameRe.MatchString(lang) {
+		return "", fmt.Errorf("invalid sample name %q", name)
+	}
+
+	dir := filepath.Join(root, lang)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dstPath := filepath.Join(dir, name+".png")
+	if _, err := os.Stat(dstPath); err == nil {
+		return "", fmt.Errorf("sample %s/%s already exists", lang, name)
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open tooltip: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+
+	if exp.Mods == nil {
+		exp.Mods = []engine.ParsedMod{}
+	}
+	data, err := json.MarshalIndent(exp, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), data, 0644); err != nil {
+		return "", err
+	}
+	return dstPath, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
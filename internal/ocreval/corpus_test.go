@@ -0,0 +1,117 @@
+package ocreval
+
+import (
+	"context"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+)
+
+const corpusDir = "../../testdata/tooltips"
+
+// Recall the corpus must keep. Texts are typed by hand, so every mod has to be found.
+const (
+	minTextRecall = 1.0
+	minOCRRecall  = 0.9
+)
+
+func loadTestCorpus(t *testing.T) []Sample {
+	t.Helper()
+	samples, err := LoadCorpus(corpusDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	langs := make(map[string]int)
+	for _, s := range samples {
+		langs[s.Language]++
+	}
+	for _, lang := range []string{"en", "zh-CN"} {
+		if langs[lang] == 0 {
+			t.Errorf("corpus has no %s sample", lang)
+		}
+	}
+	return samples
+}
+
+func checkResult(t *testing.T, result Result, minRecall float64) {
+	t.Helper()
+	for _, e := range result.OCRErrors {
+		t.Errorf("OCR error: %s", e)
+	}
+	for _, c := range result.Confusions {
+		t.Logf("%s: %s %s (expected %v, got %v)", c.Sample, c.Kind, c.Mod, c.Expected, c.Got)
+	}
+	for _, f := range result.TargetFailures {
+		t.Errorf("%s: target %q matched=%v value=%v, want matched=%v value=%v",
+			f.Sample, f.Input, f.GotMatch, f.GotValue, f.WantMatch, f.WantValue)
+	}
+	if _, recall := result.Totals(); recall < minRecall {
+		t.Errorf("recall %.1f%% is below %.1f%%", recall*100, minRecall*100)
+	}
+}
+
+func TestCorpusTexts(t *testing.T) {
+	samples := loadTestCorpus(t)
+	result := Evaluate(samples, nil)
+	if result.Samples != len(samples) {
+		t.Fatalf("evaluated %d of %d samples", result.Samples, len(samples))
+	}
+	checkResult(t, result, minTextRecall)
+}
+
+func TestCorpusOCR(t *testing.T) {
+	if _, err := exec.LookPath("tesseract"); err != nil {
+		t.Skip("tesseract is not installed")
+	}
+	samples := loadTestCorpus(t)
+	eng := engine.NewEngine(false)
+	tempDir := t.TempDir()
+	result := Evaluate(samples, func(img image.Image, lang string) (string, error) {
+		return eng.RunTesseractOCR(context.Background(), img, tempDir, lang, config.PreprocessConfig{})
+	})
+	if result.Samples == 0 {
+		t.Skip("no sample has a screenshot")
+	}
+	checkResult(t, result, minOCRRecall)
+}
+
+func TestEvaluateCountsMisreads(t *testing.T) {
+	samples := []Sample{{
+		Name:     "ring",
+		Language: "en",
+		Expected: Expectation{
+			Mods: []engine.ParsedMod{{Name: "Life", Value: 85}, {Name: "Mana", Value: 42}},
+			Text: "+88 to maximum Life\n+30 to Spirit",
+		},
+	}}
+	result := Evaluate(samples, nil)
+
+	precision, recall := result.Totals()
+	if recall != 0 || precision != 0 {
+		t.Errorf("Totals() = %v, %v; want 0, 0", precision, recall)
+	}
+	kinds := make(map[string]string)
+	for _, c := range result.Confusions {
+		kinds[c.Mod] = c.Kind
+	}
+	want := map[string]string{"Life": "wrong_value", "Mana": "missing", "Spirit": "extra"}
+	for mod, kind := range want {
+		if kinds[mod] != kind {
+			t.Errorf("confusion for %s = %q, want %q", mod, kinds[mod], kind)
+		}
+	}
+}
+
+func TestLoadCorpusNeedsScreenshotOrText(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "en"), 0755)
+	os.WriteFile(filepath.Join(root, "en", "empty.json"), []byte(`{"mods": []}`), 0644)
+	if _, err := LoadCorpus(root); err == nil {
+		t.Error("LoadCorpus() accepted a sample without a screenshot or text")
+	}
+}
@@ -0,0 +1,280 @@
+package ocreval
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// OCRFunc reads the text of a tooltip image in the given game language
+type OCRFunc func(img image.Image, lang string) (string, error)
+
+// ModScore holds per-mod precision and recall over the corpus
+type ModScore struct {
+	Name      string
+	TP        int // Found with the expected value
+	FP        int // Found but not expected, or with a wrong value
+	FN        int // Expected but missing, or read with a wrong value
+	Precision float64
+	Recall    float64
+}
+
+// Confusion describes one mismatch between expected and parsed mods
+type Confusion struct {
+	Sample   string
+	Kind     string // "missing", "extra" or "wrong_value"
+	Mod      string
+	Expected float64
+	Got      float64
+}
+
+// TargetFailure describes a CheckAnyMod case that gave the wrong answer
+type TargetFailure struct {
+	Sample    string
+	Input     string
+	WantMatch bool
+	GotMatch  bool
+	WantValue float64
+	GotValue  float64
+}
+
+// Result is the outcome of evaluating the whole corpus
+type Result struct {
+	Samples        int
+	Skipped        int // Transcript-only samples, when running OCR
+	OCRErrors      []string
+	Mods           []ModScore
+	Confusions     []Confusion
+	TargetCases    int
+	TargetCorrect  int
+	TargetFailures []TargetFailure
+}
+
+// Evaluate runs OCR, mod parsing and target matching over every sample. With a nil ocr the
+// samples' texts are parsed instead, which checks parsing and matching without tesseract.
+func Evaluate(samples []Sample, ocr OCRFunc) Result {
+	var result Result
+	scores := make(map[string]*ModScore)
+	score := func(name string) *ModScore {
+		if s, ok := scores[name]; ok {
+			return s
+		}
+		s := &ModScore{Name: name}
+		scores[name] = s
+		return s
+	}
+
+	for _, sample := range samples {
+		label := sample.Language + "/" + sample.Name
+		text, err := sampleText(sample, ocr)
+		if err == errNoScreenshot {
+			result.Skipped++
+			continue
+		}
+		result.Samples++
+		if err != nil {
+			result.OCRErrors = append(result.OCRErrors, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		// Group expected and parsed values by mod name
+		expected := make(map[string][]float64)
+		for _, mod := range sample.Expected.Mods {
+			expected[mod.Name] = append(expected[mod.Name], mod.Value)
+		}
+		got := make(map[string][]float64)
+		for _, mod := range engine.ParseMods(text, sample.Language) {
+			got[mod.Name] = append(got[mod.Name], mod.Value)
+		}
+
+		names := make(map[string]bool)
+		for name := range expected {
+			names[name] = true
+		}
+		for name := range got {
+			names[name] = true
+		}
+
+		for name := range names {
+			s := score(name)
+			want, have := removeCommon(expected[name], got[name])
+			s.TP += len(expected[name]) - len(want)
+
+			// Pair leftovers as misreads, then report what remains as missing or extra
+			for len(want) > 0 && len(have) > 0 {
+				result.Confusions = append(result.Confusions, Confusion{label, "wrong_value", name, want[0], have[0]})
+				s.FN++
+				s.FP++
+				want, have = want[1:], have[1:]
+			}
+			for _, v := range want {
+				result.Confusions = append(result.Confusions, Confusion{label, "missing", name, v, 0})
+				s.FN++
+			}
+			for _, v := range have {
+				result.Confusions = append(result.Confusions, Confusion{label, "extra", name, 0, v})
+				s.FP++
+			}
+		}
+
+		for _, tc := range sample.Expected.Targets {
+			mod := config.ParseModInput(tc.Input, sample.Language)
+			if mod.Pattern == "" {
+				continue
+			}
+			result.TargetCases++
+			matched, _, value := engine.CheckAnyMod(text, []config.ModRequirement{mod})
+			if matched == tc.Match && (!tc.Match || tc.Value == 0 || sameValue(tc.Value, value)) {
+				result.TargetCorrect++
+				continue
+			}
+			result.TargetFailures = append(result.TargetFailures, TargetFailure{
+				Sample:    label,
+				Input:     tc.Input,
+				WantMatch: tc.Match,
+				GotMatch:  matched,
+				WantValue: tc.Value,
+				GotValue:  value,
+			})
+		}
+	}
+
+	for _, s := range scores {
+		if s.TP+s.FP > 0 {
+			s.Precision = float64(s.TP) / float64(s.TP+s.FP)
+		}
+		if s.TP+s.FN > 0 {
+			s.Recall = float64(s.TP) / float64(s.TP+s.FN)
+		}
+		result.Mods = append(result.Mods, *s)
+	}
+	sort.Slice(result.Mods, func(i, j int) bool { return result.Mods[i].Name < result.Mods[j].Name })
+
+	return result
+}
+
+var errNoScreenshot = errors.New("no screenshot")
+
+// sampleText returns the text of a sample, read by ocr or, when ocr is nil, its transcript
+func sampleText(sample Sample, ocr OCRFunc) (string, error) {
+	if ocr == nil {
+		if sample.Expected.Text == "" {
+			return "", fmt.Errorf("no text to parse")
+		}
+		return sample.Expected.Text, nil
+	}
+	if sample.ImagePath == "" {
+		return "", errNoScreenshot
+	}
+	img, err := loadPNG(sample.ImagePath)
+	if err != nil {
+		return "", err
+	}
+	return ocr(img, sample.Language)
+}
+
+// Totals returns micro-averaged precision and recall across all mods
+func (r Result) Totals() (precision, recall float64) {
+	tp, fp, fn := 0, 0, 0
+	for _, s := range r.Mods {
+		tp += s.TP
+		fp += s.FP
+		fn += s.FN
+	}
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+	return precision, recall
+}
+
+// Print writes a human-readable report
+func (r Result) Print(w io.Writer) {
+	fmt.Fprintf(w, "OCR EVALUATION (%d samples)\n", r.Samples)
+	if r.Skipped > 0 {
+		fmt.Fprintf(w, "Skipped %d samples without a screenshot\n", r.Skipped)
+	}
+	fmt.Fprintln(w, "─────────────────────────────────────────────────")
+	fmt.Fprintf(w, "%-24s %5s %5s %5s %9s %8s\n", "Mod", "TP", "FP", "FN", "Precision", "Recall")
+	for _, s := range r.Mods {
+		fmt.Fprintf(w, "%-24s %5d %5d %5d %8.1f%% %7.1f%%\n",
+			s.Name, s.TP, s.FP, s.FN, s.Precision*100, s.Recall*100)
+	}
+	precision, recall := r.Totals()
+	fmt.Fprintf(w, "%-24s %23s %8.1f%% %7.1f%%\n", "TOTAL", "", precision*100, recall*100)
+
+	if r.TargetCases > 0 {
+		fmt.Fprintf(w, "\nTarget matching: %d/%d correct\n", r.TargetCorrect, r.TargetCases)
+		for _, f := range r.TargetFailures {
+			fmt.Fprintf(w, "  ✗ %s: %q want match=%v value=%s, got match=%v value=%s\n",
+				f.Sample, f.Input, f.WantMatch, modcatalog.FormatValue(f.WantValue), f.GotMatch, modcatalog.FormatValue(f.GotValue))
+		}
+	}
+
+	if len(r.Confusions) > 0 {
+		fmt.Fprintln(w, "\nConfusion cases:")
+		for _, c := range r.Confusions {
+			switch c.Kind {
+			case "wrong_value":
+				fmt.Fprintf(w, "  %s: %s read as %s, expected %s\n", c.Sample, c.Mod, modcatalog.FormatValue(c.Got), modcatalog.FormatValue(c.Expected))
+			case "missing":
+				fmt.Fprintf(w, "  %s: %s %s not found\n", c.Sample, c.Mod, modcatalog.FormatValue(c.Expected))
+			default:
+				fmt.Fprintf(w, "  %s: unexpected %s %s\n", c.Sample, c.Mod, modcatalog.FormatValue(c.Got))
+			}
+		}
+	}
+
+	if len(r.OCRErrors) > 0 {
+		fmt.Fprintln(w, "\nOCR errors:")
+		for _, e := range r.OCRErrors {
+			fmt.Fprintf(w, "  %s\n", strings.TrimSpace(e))
+		}
+	}
+}
+
+// removeCommon drops values present in both lists and returns the leftovers
+func removeCommon(want, have []float64) ([]float64, []float64) {
+	remaining := append([]float64(nil), have...)
+	var missing []float64
+	for _, v := range want {
+		found := false
+		for i, h := range remaining {
+			if sameValue(h, v) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, v)
+		}
+	}
+	return missing, remaining
+}
+
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// sameValue compares OCR values, ignoring float rounding noise
+func sameValue(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
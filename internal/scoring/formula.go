@@ -0,0 +1,294 @@
+// Package scoring evaluates stat-weight formulas such as "life*1 + total_res*1.5 + spirit*2"
+// against the mods recognized on a roll.
+package scoring
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula is a parsed scoring expression
+type Formula struct {
+	src  string
+	root node
+	vars []string
+}
+
+// Parse reads an expression of numbers, variables, + - * /, parentheses and the functions
+// min(a, b, ...) and max(a, b, ...). Variables are mod ids with "-" written as "_"
+// (fire_res), named values of multi-value mods (fire_dmg.max) or aggregates (total_res).
+func Parse(src string) (*Formula, error) {
+	p := &parser{src: src}
+	p.next()
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+
+	seen := make(map[string]bool)
+	var vars []string
+	root.walk(func(n node) {
+		if v, ok := n.(varNode); ok && !seen[string(v)] {
+			seen[string(v)] = true
+			vars = append(vars, string(v))
+		}
+	})
+	sort.Strings(vars)
+	return &Formula{src: src, root: root, vars: vars}, nil
+}
+
+// Eval computes the score. Missing variables count as 0.
+func (f *Formula) Eval(vars map[string]float64) float64 {
+	return f.root.eval(vars)
+}
+
+// Variables returns the variable names used by the formula
+func (f *Formula) Variables() []string {
+	return f.vars
+}
+
+// String returns the formula source
+func (f *Formula) String() string {
+	return f.src
+}
+
+// AST nodes
+
+type node interface {
+	eval(vars map[string]float64) float64
+	walk(fn func(node))
+}
+
+type numNode float64
+
+func (n numNode) eval(map[string]float64) float64 { return float64(n) }
+func (n numNode) walk(fn func(node))              { fn(n) }
+
+type varNode string
+
+func (n varNode) eval(vars map[string]float64) float64 { return vars[string(n)] }
+func (n varNode) walk(fn func(node))                   { fn(n) }
+
+type negNode struct{ x node }
+
+func (n negNode) eval(vars map[string]float64) float64 { return -n.x.eval(vars) }
+func (n negNode) walk(fn func(node))                   { fn(n); n.x.walk(fn) }
+
+type binNode struct {
+	op   byte
+	l, r node
+}
+
+func (n binNode) eval(vars map[string]float64) float64 {
+	l, r := n.l.eval(vars), n.r.eval(vars)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	}
+}
+
+func (n binNode) walk(fn func(node)) { fn(n); n.l.walk(fn); n.r.walk(fn) }
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n callNode) eval(vars map[string]float64) float64 {
+	result := n.args[0].eval(vars)
+	for _, arg := range n.args[1:] {
+		v := arg.eval(vars)
+		if n.name == "min" {
+			result = min(result, v)
+		} else {
+			result = max(result, v)
+		}
+	}
+	return result
+}
+
+func (n callNode) walk(fn func(node)) {
+	fn(n)
+	for _, arg := range n.args {
+		arg.walk(fn)
+	}
+}
+
+// Tokenizer and recursive descent parser
+
+const (
+	tokEOF = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("formula column %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = token{kind: tokNum, text: p.src[start:p.pos], pos: start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && isIdentChar(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: strings.ToLower(p.src[start:p.pos]), pos: start}
+	default:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || unicode.IsLetter(rune(c))
+}
+
+// expr := term (("+" | "-") term)*
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op, left, right}
+	}
+	return left, nil
+}
+
+// term := unary (("*" | "/") unary)*
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && (p.tok.text == "*" || p.tok.text == "/") {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op, left, right}
+	}
+	return left, nil
+}
+
+// unary := "-" unary | primary
+func (p *parser) unary() (node, error) {
+	if p.tok.kind == tokOp && p.tok.text == "-" {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x}, nil
+	}
+	return p.primary()
+}
+
+// primary := number | ident | ident "(" expr ("," expr)* ")" | "(" expr ")"
+func (p *parser) primary() (node, error) {
+	switch p.tok.kind {
+	case tokNum:
+		v, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.tok.text)
+		}
+		p.next()
+		return numNode(v), nil
+
+	case tokIdent:
+		name, pos := p.tok.text, p.tok.pos
+		p.next()
+		if p.tok.kind != tokOp || p.tok.text != "(" {
+			return varNode(name), nil
+		}
+		if name != "min" && name != "max" {
+			return nil, fmt.Errorf("formula column %d: unknown function %q", pos+1, name)
+		}
+		p.next()
+		var args []node
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.tok.kind == tokOp && p.tok.text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+		if p.tok.kind != tokOp || p.tok.text != ")" {
+			return nil, p.errorf("expected \")\"")
+		}
+		p.next()
+		return callNode{name, args}, nil
+
+	case tokOp:
+		if p.tok.text == "(" {
+			p.next()
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokOp || p.tok.text != ")" {
+				return nil, p.errorf("expected \")\"")
+			}
+			p.next()
+			return x, nil
+		}
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return nil, p.errorf("unexpected end of formula")
+}
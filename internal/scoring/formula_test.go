@@ -0,0 +1,76 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"life": 80, "fire_res": 30, "spirit": 20, "fire_dmg.max": 12}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 3 / 2", 2},
+		{"2 * 3 + 4 * 5", 26},
+		{"life*1 + fire_res*1.5 + spirit*2", 165},
+		{"-life + 100", 20},
+		{"--life", 80},
+		{"2 * -3", -6},
+		{"-(1 + 2) * 2", -6},
+		{"max(life)", 80},
+		{"min(life, fire_res)", 30},
+		{"max(life, fire_res * 3, spirit)", 90},
+		{"min(max(1, 2), 3) + 1", 3},
+		{"fire_dmg.max * 2", 24},
+		{"LIFE", 80},
+		{"mana", 0},     // Missing variables count as 0
+		{"life / 0", 0}, // So does a division by zero
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		if got := f.Eval(vars); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"life +", "column 7: unexpected end of formula"},
+		{"life * * 2", "column 8: unexpected \"*\""},
+		{"(life + 2", "column 10: expected \")\""},
+		{"max(life, 2", "column 12: expected \")\""},
+		{"life 2", "column 6: unexpected \"2\""},
+		{"avg(life)", "column 1: unknown function \"avg\""},
+		{"life + sqrt(2)", "column 8: unknown function \"sqrt\""},
+		{"1..2", "column 1: bad number \"1..2\""},
+		{"life $ 2", "column 6: unexpected \"$\""},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want an error with %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestFormulaVariables(t *testing.T) {
+	f, err := Parse("max(life, spirit) + life * fire_dmg.max - 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(f.Variables(), " "); got != "fire_dmg.max life spirit" {
+		t.Errorf("Variables() = %s, want fire_dmg.max life spirit", got)
+	}
+}
@@ -0,0 +1,79 @@
+package scoring
+
+import (
+	"fmt"
+	"strings"
+
+	"poe2-chaos-crafter/internal/modcatalog"
+)
+
+// Aggregates are derived variables available in every formula, as sums of mod variables
+var Aggregates = map[string][]string{
+	"total_res":     {"fire_res", "cold_res", "light_res", "chaos_res"},
+	"total_ele_res": {"fire_res", "cold_res", "light_res"},
+	"total_attr":    {"str", "dex", "int"},
+}
+
+// Value is one recognized mod line fed into a formula
+type Value struct {
+	ID     string             // Catalog id, e.g. "fire-res"
+	Value  float64            // Single or derived value
+	Values map[string]float64 // Named values of multi-value mods
+}
+
+// VarName converts a mod id to the identifier used in formulas ("fire-res" → "fire_res")
+func VarName(id string) string {
+	return strings.ReplaceAll(strings.ToLower(id), "-", "_")
+}
+
+// Variables builds the formula inputs for a roll. A mod that appears more than once is
+// summed; named values are available as id.name.
+func Variables(mods []Value) map[string]float64 {
+	vars := make(map[string]float64)
+	for _, mod := range mods {
+		name := VarName(mod.ID)
+		vars[name] += mod.Value
+		for valueName, v := range mod.Values {
+			vars[name+"."+valueName] += v
+		}
+	}
+	for aggregate, parts := range Aggregates {
+		for _, part := range parts {
+			vars[aggregate] += vars[part]
+		}
+	}
+	return vars
+}
+
+// Known returns every variable a formula may use with the given catalog
+func Known(c *modcatalog.Catalog) map[string]bool {
+	known := make(map[string]bool)
+	for _, mod := range c.Mods() {
+		name := VarName(mod.ID)
+		known[name] = true
+		if loc := mod.Localized(modcatalog.DefaultLanguage); loc != nil && len(loc.ValueNames()) > 1 {
+			for _, valueName := range loc.ValueNames() {
+				known[name+"."+valueName] = true
+			}
+		}
+	}
+	for aggregate := range Aggregates {
+		known[aggregate] = true
+	}
+	return known
+}
+
+// Compile parses a formula and rejects variables the default catalog does not know
+func Compile(src string) (*Formula, error) {
+	f, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	known := Known(modcatalog.Default())
+	for _, v := range f.Variables() {
+		if !known[v] {
+			return nil, fmt.Errorf("unknown variable %q (use mod ids with _ instead of -, e.g. fire_res)", v)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,57 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariables(t *testing.T) {
+	vars := Variables([]Value{
+		{ID: "life", Value: 40},
+		{ID: "life", Value: 30},
+		{ID: "fire-res", Value: 20},
+		{ID: "cold-res", Value: 15},
+		{ID: "chaos-res", Value: 10},
+		{ID: "int", Value: 12},
+		{ID: "fire-dmg", Value: 15, Values: map[string]float64{"min": 10, "max": 20}},
+		{ID: "fire-dmg", Value: 5, Values: map[string]float64{"min": 4, "max": 6}},
+	})
+
+	want := map[string]float64{
+		"life":          70,
+		"fire_res":      20,
+		"total_res":     45,
+		"total_ele_res": 35,
+		"total_attr":    12,
+		"fire_dmg":      20,
+		"fire_dmg.min":  14,
+		"fire_dmg.max":  26,
+		"light_res":     0,
+	}
+	for name, v := range want {
+		if vars[name] != v {
+			t.Errorf("%s = %v, want %v", name, vars[name], v)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for _, src := range []string{"life + total_res * 1.5", "fire_dmg.max", "max(spirit, mana)"} {
+		if _, err := Compile(src); err != nil {
+			t.Errorf("Compile(%q): %v", src, err)
+		}
+	}
+
+	tests := map[string]string{
+		"life + lfie":  `unknown variable "lfie"`,
+		"fire-res":     `unknown variable "fire"`, // "-" is a minus, not part of the id
+		"life.max":     `unknown variable "life.max"`,
+		"total_res + ": "unexpected end of formula",
+	}
+	for src, want := range tests {
+		_, err := Compile(src)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Compile(%q) = %v, want an error with %q", src, err, want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// authCookie carries the token in browsers that signed in or paired
+const authCookie = "poe2crafter_token"
+
+// pairingTTL is how long a pairing link can be used
+const pairingTTL = 5 * time.Minute
+
+// An address that fails to sign in maxLoginFailures times in a row is locked out for loginLockout
+const (
+	maxLoginFailures = 5
+	loginLockout     = 15 * time.Minute
+)
+
+// openPaths are API paths served without authentication so a browser can sign in
+var openPaths = map[string]bool{
+	"/api/auth/status": true,
+	"/api/auth/login":  true,
+}
+
+// access enforces the server settings on every request
+type access struct {
+	settings config.ServerSettings
+	networks []*net.IPNet
+	origins  map[string]bool
+
+	mu       sync.Mutex
+	pairings map[string]time.Time      // One-time pairing code → expiry
+	failures map[string]*loginFailures // Client IP → failed sign-ins
+}
+
+// loginFailures counts the failed sign-ins of one address since its last success
+type loginFailures struct {
+	count  int
+	last   time.Time // Latest failure; counts older than loginLockout are forgotten
+	locked time.Time // Sign-ins are refused until then
+}
+
+func newAccess(settings config.ServerSettings) *access {
+	a := &access{
+		settings: settings,
+		origins:  make(map[string]bool),
+		pairings: make(map[string]time.Time),
+		failures: make(map[string]*loginFailures),
+	}
+	for _, cidr := range settings.AllowedCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			a.networks = append(a.networks, network)
+		}
+	}
+	for _, origin := range settings.AllowedOrigins {
+		a.origins[strings.ToLower(origin)] = true
+	}
+	return a
+}
+
+// wrap rejects clients outside the allowed networks, browser requests from other origins
+// and unauthenticated API and WebSocket requests. Static files stay open so the dashboard
+// can show its sign-in form.
+func (a *access) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.networkAllowed(r) {
+			fmt.Printf("[Auth] Rejected %s: not in AllowedCIDRs\n", r.RemoteAddr)
+			http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		if !a.originAllowed(r) {
+			fmt.Printf("[Auth] Rejected %s: origin %s not allowed\n", r.RemoteAddr, r.Header.Get("Origin"))
+			http.Error(w, `{"error":"origin not allowed"}`, http.StatusForbidden)
+			return
+		}
+		protected := r.URL.Path == "/ws" || strings.HasPrefix(r.URL.Path, "/api/")
+		if protected && !openPaths[r.URL.Path] && !a.authenticated(r) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// networkAllowed reports whether the client's address is in AllowedCIDRs. This machine is
+// always allowed.
+func (a *access) networkAllowed(r *http.Request) bool {
+	ip := remoteIP(r)
+	if len(a.networks) == 0 || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+	for _, network := range a.networks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// originAllowed accepts requests without an Origin (non-browser clients), same-origin
+// requests and the origins in AllowedOrigins
+func (a *access) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host) || a.origins[strings.ToLower(origin)]
+}
+
+// authenticated accepts the token as a bearer header or cookie. Browsers on this machine
+// need neither: whoever sits there already controls the mouse. The Host check keeps web
+// pages that resolve their own domain to 127.0.0.1 from riding on that.
+func (a *access) authenticated(r *http.Request) bool {
+	if ip := remoteIP(r); ip != nil && ip.IsLoopback() && isLoopbackHost(r.Host) {
+		return true
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && a.isToken(token) {
+		return true
+	}
+	cookie, err := r.Cookie(authCookie)
+	return err == nil && a.isToken(cookie.Value)
+}
+
+func (a *access) isToken(s string) bool {
+	// Without a configured token nothing matches, not even an empty bearer or cookie
+	if a.settings.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(s), []byte(a.settings.Token)) == 1
+}
+
+// setCookie signs the browser in for a year
+func (a *access) setCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookie,
+		Value:    a.settings.Token,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// newPairing returns a one-time code for /pair?code=
+func (a *access) newPairing() (string, error) {
+	code, err := config.NewSecret()
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	for c, expiry := range a.pairings {
+		if now.After(expiry) {
+			delete(a.pairings, c)
+		}
+	}
+	a.pairings[code] = now.Add(pairingTTL)
+	return code, nil
+}
+
+// usePairing consumes a pairing code, which works once and only until it expires
+func (a *access) usePairing(code string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	expiry, ok := a.pairings[code]
+	delete(a.pairings, code)
+	return ok && time.Now().Before(expiry)
+}
+
+// pairingURL returns a link that signs in the device that opens it. Links asked for on
+// this machine point at the LAN address, since they are meant for phones.
+func (a *access) pairingURL(r *http.Request, lanIP string) (string, error) {
+	code, err := a.newPairing()
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if isLoopbackHost(host) && lanIP != "" {
+		_, port, _ := net.SplitHostPort(host)
+		host = net.JoinHostPort(lanIP, port)
+	}
+	return fmt.Sprintf("%s://%s/pair?code=%s", scheme, host, code), nil
+}
+
+// handleAuthStatus tells the dashboard whether it has to sign in first
+func (a *access) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{
+		"authenticated": a.authenticated(r),
+		"password":      a.settings.Password != "",
+		"pairing":       !a.settings.LocalOnly,
+	})
+}
+
+// handleAuthLogin signs a browser in with the password or the token
+func (a *access) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	client := clientKey(r)
+	if until, locked := a.lockedOut(client); locked {
+		fmt.Printf("[Auth] Refused sign-in from %s: locked out until %s\n", r.RemoteAddr, until.Format("15:04:05"))
+		w.Header().Set("Retry-After", fmt.Sprint(int(time.Until(until).Seconds())+1))
+		http.Error(w, `{"error":"too many failed sign-ins, try again later"}`, http.StatusTooManyRequests)
+		return
+	}
+
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+
+	password := a.settings.Password != "" &&
+		subtle.ConstantTimeCompare([]byte(req.Secret), []byte(a.settings.Password)) == 1
+	if !password && !a.isToken(req.Secret) {
+		fmt.Printf("[Auth] Failed sign-in from %s\n", r.RemoteAddr)
+		if a.loginFailed(client) {
+			fmt.Printf("[Auth] Locked out %s for %s after %d failed sign-ins\n", r.RemoteAddr, loginLockout, maxLoginFailures)
+		}
+		http.Error(w, `{"error":"wrong password or token"}`, http.StatusUnauthorized)
+		return
+	}
+
+	a.loginSucceeded(client)
+	a.setCookie(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// lockedOut reports whether client has to wait before signing in again, and until when
+func (a *access) lockedOut(client string) (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.failures[client]
+	if !ok || !time.Now().Before(f.locked) {
+		return time.Time{}, false
+	}
+	return f.locked, true
+}
+
+// loginFailed counts a failed sign-in and returns true when it locks client out
+func (a *access) loginFailed(client string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	for c, f := range a.failures {
+		if now.Sub(f.last) > loginLockout && now.After(f.locked) {
+			delete(a.failures, c)
+		}
+	}
+
+	f, ok := a.failures[client]
+	if !ok {
+		f = &loginFailures{}
+		a.failures[client] = f
+	}
+	f.count++
+	f.last = now
+	if f.count < maxLoginFailures {
+		return false
+	}
+	f.count, f.locked = 0, now.Add(loginLockout)
+	return true
+}
+
+// loginSucceeded forgets the failed sign-ins of client
+func (a *access) loginSucceeded(client string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.failures, client)
+}
+
+// handleAuthPair creates a pairing link and its QR code for a phone to scan
+func (a *access) handleAuthPair(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+	if a.settings.LocalOnly {
+		http.Error(w, `{"error":"the server only listens on localhost"}`, http.StatusBadRequest)
+		return
+	}
+
+	link, err := a.pairingURL(r, getLANIP())
+	if err != nil {
+		http.Error(w, `{"error":"failed to create pairing link"}`, http.StatusInternalServerError)
+		return
+	}
+	png, err := qrcode.Encode(link, qrcode.Medium, 256)
+	if err != nil {
+		http.Error(w, `{"error":"failed to encode QR code"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"url":     link,
+		"qr":      "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+		"expires": time.Now().Add(pairingTTL).UnixMilli(),
+	})
+}
+
+// handlePair signs in the device that opened a pairing link and sends it to the dashboard
+func (a *access) handlePair(w http.ResponseWriter, r *http.Request) {
+	if a.usePairing(r.URL.Query().Get("code")) {
+		fmt.Printf("[Auth] Paired %s\n", r.RemoteAddr)
+		a.setCookie(w, r)
+	} else {
+		fmt.Printf("[Auth] Rejected pairing from %s: link expired or already used\n", r.RemoteAddr)
+	}
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// printPairing prints a pairing link below base, e.g. "https://192.168.1.5:8080", and its
+// QR code to the console for the first phone
+func (a *access) printPairing(base string) {
+	code, err := a.newPairing()
+	if err != nil {
+		return
+	}
+	link := fmt.Sprintf("%s/pair?code=%s", base, code)
+	q, err := qrcode.New(link, qrcode.Low)
+	if err != nil {
+		return
+	}
+	fmt.Printf("  Pair a phone (one use, %s): %s\n", pairingTTL, link)
+	fmt.Println(q.ToSmallString(false))
+}
+
+// remoteIP returns the client's IP address, or nil
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// clientKey identifies the client for the sign-in lockout: its IP address, or the whole
+// remote address if that has none
+func clientKey(r *http.Request) string {
+	if ip := remoteIP(r); ip != nil {
+		return ip.String()
+	}
+	return r.RemoteAddr
+}
+
+// isLoopbackHost reports whether a Host header names this machine
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+func newTestAccess() *access {
+	return newAccess(config.ServerSettings{
+		Token:          "secret-token",
+		Password:       "hunter2",
+		AllowedCIDRs:   []string{"192.168.1.0/24"},
+		AllowedOrigins: []string{"http://crafter.lan:8080"},
+	})
+}
+
+// request builds a request from remoteAddr to host
+func request(method, path, remoteAddr, host string) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	r.RemoteAddr = remoteAddr
+	r.Host = host
+	return r
+}
+
+func serve(a *access, r *http.Request) int {
+	w := httptest.NewRecorder()
+	a.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestAccessWrap(t *testing.T) {
+	a := newTestAccess()
+	const lan = "192.168.1.5:8080"
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		header map[string]string
+		want   int
+	}{
+		{"outside AllowedCIDRs", request("GET", "/", "10.0.0.7:5000", lan), nil, http.StatusForbidden},
+		{"outside AllowedCIDRs with the token", request("GET", "/api/state", "10.0.0.7:5000", lan),
+			map[string]string{"Authorization": "Bearer secret-token"}, http.StatusForbidden},
+		{"static file", request("GET", "/", "192.168.1.20:5000", lan), nil, http.StatusOK},
+		{"sign-in status", request("GET", "/api/auth/status", "192.168.1.20:5000", lan), nil, http.StatusOK},
+		{"API without the token", request("GET", "/api/state", "192.168.1.20:5000", lan), nil, http.StatusUnauthorized},
+		{"WebSocket without the token", request("GET", "/ws", "192.168.1.20:5000", lan), nil, http.StatusUnauthorized},
+		{"wrong token", request("GET", "/api/state", "192.168.1.20:5000", lan),
+			map[string]string{"Authorization": "Bearer secret-tokeN"}, http.StatusUnauthorized},
+		{"bearer token", request("GET", "/api/state", "192.168.1.20:5000", lan),
+			map[string]string{"Authorization": "Bearer secret-token"}, http.StatusOK},
+		{"cookie", request("GET", "/api/state", "192.168.1.20:5000", lan),
+			map[string]string{"Cookie": authCookie + "=secret-token"}, http.StatusOK},
+		{"other origin", request("POST", "/api/craft/stop", "192.168.1.20:5000", lan),
+			map[string]string{"Origin": "http://evil.example", "Authorization": "Bearer secret-token"}, http.StatusForbidden},
+		{"same origin", request("POST", "/api/craft/stop", "192.168.1.20:5000", lan),
+			map[string]string{"Origin": "http://" + lan, "Authorization": "Bearer secret-token"}, http.StatusOK},
+		{"AllowedOrigins", request("POST", "/api/craft/stop", "192.168.1.20:5000", lan),
+			map[string]string{"Origin": "http://crafter.lan:8080", "Authorization": "Bearer secret-token"}, http.StatusOK},
+		{"this machine", request("GET", "/api/state", "127.0.0.1:5000", "localhost:8080"), nil, http.StatusOK},
+		{"this machine over IPv6", request("GET", "/api/state", "[::1]:5000", "[::1]:8080"), nil, http.StatusOK},
+		{"loopback with a spoofed Host", request("GET", "/api/state", "127.0.0.1:5000", "evil.example:8080"), nil, http.StatusUnauthorized},
+		{"loopback Host from another machine", request("GET", "/api/state", "192.168.1.20:5000", "localhost:8080"), nil, http.StatusUnauthorized},
+		{"page on a rebound domain", request("POST", "/api/craft/stop", "127.0.0.1:5000", "evil.example:8080"),
+			map[string]string{"Origin": "http://evil.example:8080"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		for k, v := range tt.header {
+			tt.req.Header.Set(k, v)
+		}
+		if got := serve(a, tt.req); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyTokenMatchesNothing(t *testing.T) {
+	a := newAccess(config.ServerSettings{})
+	const lan = "192.168.1.5:8080"
+
+	for name, header := range map[string]map[string]string{
+		"no credentials": nil,
+		"empty bearer":   {"Authorization": "Bearer "},
+		"empty cookie":   {"Cookie": authCookie + "="},
+	} {
+		r := request("GET", "/api/state", "192.168.1.20:5000", lan)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		if got := serve(a, r); got != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want %d", name, got, http.StatusUnauthorized)
+		}
+	}
+	if a.isToken("") {
+		t.Error("an empty secret matched the empty token")
+	}
+}
+
+// pair opens a pairing link and returns the cookie it set, if any
+func pair(a *access, code string) *http.Cookie {
+	w := httptest.NewRecorder()
+	a.handlePair(w, request("GET", "/pair?code="+code, "192.168.1.30:5000", "192.168.1.5:8080"))
+	for _, c := range w.Result().Cookies() {
+		if c.Name == authCookie {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestPairingCodes(t *testing.T) {
+	a := newTestAccess()
+	code, err := a.newPairing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookie := pair(a, code)
+	if cookie == nil {
+		t.Fatal("a fresh pairing code did not sign the device in")
+	}
+	r := request("GET", "/api/state", "192.168.1.30:5000", "192.168.1.5:8080")
+	r.AddCookie(cookie)
+	if got := serve(a, r); got != http.StatusOK {
+		t.Errorf("paired device got status %d, want 200", got)
+	}
+
+	if pair(a, code) != nil {
+		t.Error("a pairing code worked twice")
+	}
+	if pair(a, "not-a-code") != nil || pair(a, "") != nil {
+		t.Error("an unknown pairing code signed the device in")
+	}
+
+	expired, err := a.newPairing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.pairings[expired] = time.Now().Add(-time.Second)
+	if pair(a, expired) != nil {
+		t.Error("an expired pairing code signed the device in")
+	}
+}
+
+// login posts secret to the sign-in handler from remoteAddr and returns the status
+func login(a *access, remoteAddr, secret string) int {
+	r := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(`{"secret":"`+secret+`"}`))
+	r.RemoteAddr, r.Host = remoteAddr, "192.168.1.5:8080"
+	w := httptest.NewRecorder()
+	a.handleAuthLogin(w, r)
+	return w.Code
+}
+
+func TestLoginLockout(t *testing.T) {
+	a := newTestAccess()
+	const phone, laptop = "192.168.1.30:5000", "192.168.1.31:5000"
+
+	// A success in between starts the count again
+	for i := 0; i < maxLoginFailures-1; i++ {
+		login(a, phone, "guess")
+	}
+	if got := login(a, phone, "hunter2"); got != http.StatusOK {
+		t.Fatalf("password after %d failures: status %d, want 200", maxLoginFailures-1, got)
+	}
+
+	for i := 0; i < maxLoginFailures; i++ {
+		if got := login(a, phone, "guess"); got != http.StatusUnauthorized {
+			t.Fatalf("failure %d: status %d, want 401", i+1, got)
+		}
+	}
+	if got := login(a, phone, "hunter2"); got != http.StatusTooManyRequests {
+		t.Errorf("password while locked out: status %d, want 429", got)
+	}
+	if got := login(a, "192.168.1.30:6000", "secret-token"); got != http.StatusTooManyRequests {
+		t.Errorf("token from another port of the locked out address: status %d, want 429", got)
+	}
+	if got := login(a, laptop, "secret-token"); got != http.StatusOK {
+		t.Errorf("another address: status %d, want 200", got)
+	}
+
+	a.failures["192.168.1.30"].locked = time.Now().Add(-time.Second)
+	if got := login(a, phone, "hunter2"); got != http.StatusOK {
+		t.Errorf("password after the lockout: status %d, want 200", got)
+	}
+}
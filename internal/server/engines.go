@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"poe2-chaos-crafter/internal/backend"
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+)
+
+// managedEngine is one engine of the server, with the hub and queue scoped to it
+type managedEngine struct {
+	eng     *engine.Engine
+	hub     *WSHub
+	queue   *JobQueue
+	profile string // Profile Start and Resume use (empty = the current config)
+	backend string // Backend kind, for the engine list
+}
+
+// engineSet holds the server's engines in the order the dashboard lists them
+type engineSet struct {
+	list   []*managedEngine
+	byName map[string]*managedEngine
+}
+
+// newEngineSet wires local, the engine of this machine, and every engine listed in the
+// engines file to a hub and queue of its own
+func newEngineSet(local *engine.Engine) *engineSet {
+	set := &engineSet{byName: make(map[string]*managedEngine)}
+	set.add(local, "", backend.KindLocal)
+
+	targets, err := config.LoadEngineTargets()
+	if err != nil {
+		fmt.Printf("⚠ WARNING: Ignoring %s: %v\n", config.GetEnginesPath(), err)
+	}
+	for _, target := range targets {
+		b, err := backend.Open(backend.Target{
+			Kind:        target.Backend,
+			Address:     target.Address,
+			Token:       target.Token,
+			Fingerprint: target.Fingerprint,
+		})
+		if err != nil {
+			fmt.Printf("⚠ WARNING: Engine %s disabled: %v\n", target.Name, err)
+			continue
+		}
+		eng := engine.NewEngine(local.DebugMode)
+		eng.Name = target.Name
+		eng.Backend = b
+		// The keyboard and Ctrl+C of this machine belong to the local engine
+		eng.Local = false
+		eng.SnapshotsDir = filepath.Join(config.SnapshotsDir, target.Name)
+		eng.CheckpointPath = config.EnginePath(config.GetCheckpointPath(), target.Name)
+
+		kind := target.Backend
+		if kind == "" {
+			kind = backend.KindLocal
+		}
+		set.add(eng, target.Profile, kind)
+	}
+	return set
+}
+
+func (s *engineSet) add(eng *engine.Engine, profile, kind string) {
+	hub := NewWSHub(eng)
+	eng.Broadcaster = hub
+	eng.SessionManager = hub
+	go hub.Run()
+
+	queue := NewJobQueue(eng, hub, config.EnginePath(config.GetQueuePath(), eng.Name))
+	go queue.Run()
+
+	m := &managedEngine{eng: eng, hub: hub, queue: queue, profile: profile, backend: kind}
+	s.list = append(s.list, m)
+	s.byName[eng.Name] = m
+}
+
+// handle wraps an engine-scoped handler. The engine is chosen with ?engine=<name>;
+// without it requests go to the engine of this machine.
+func (s *engineSet) handle(h func(w http.ResponseWriter, r *http.Request, m *managedEngine)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("engine")
+		if name == "" {
+			name = config.DefaultEngine
+		}
+		m, ok := s.byName[name]
+		if !ok {
+			msg, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("unknown engine %q", name)})
+			http.Error(w, string(msg), http.StatusNotFound)
+			return
+		}
+		h(w, r, m)
+	}
+}
+
+// names lists the engines for the startup banner
+func (s *engineSet) names() []string {
+	names := make([]string, len(s.list))
+	for i, m := range s.list {
+		names[i] = m.eng.Name
+	}
+	return names
+}
+
+// handleEngines lists the engines with their backend and state
+func handleEngines(w http.ResponseWriter, r *http.Request, engines *engineSet) {
+	if r.Method != "GET" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	list := []map[string]interface{}{}
+	for _, m := range engines.list {
+		list = append(list, map[string]interface{}{
+			"name":    m.eng.Name,
+			"backend": m.backend,
+			"profile": m.profile,
+			"state":   m.eng.State(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
@@ -19,6 +19,12 @@ type WSClient struct {
 	send chan []byte
 }
 
+// StateSource is where the hub reads the crafting state from
+type StateSource interface {
+	State() engine.State
+	LastError() string
+}
+
 // WSHub manages WebSocket connections and broadcasts messages
 type WSHub struct {
 	clients    map[*WSClient]bool
@@ -27,8 +33,8 @@ type WSHub struct {
 	unregister chan *WSClient
 	mu         sync.RWMutex
 
-	// Crafting state tracked by the hub
-	state          string                  // "idle", "running", "paused", "stopped"
+	// Crafting progress tracked by the hub
+	states         StateSource             // Engine state machine
 	activeSession  *engine.CraftingSession // pointer to active session (nil when idle)
 	activeConfig   *config.Config          // pointer to active config (nil when idle)
 	currentItem    int
@@ -37,14 +43,14 @@ type WSHub struct {
 	lastOCRText    string
 }
 
-// NewWSHub creates a new WebSocket hub
-func NewWSHub() *WSHub {
+// NewWSHub creates a new WebSocket hub that reports the state of states
+func NewWSHub(states StateSource) *WSHub {
 	return &WSHub{
 		clients:    make(map[*WSClient]bool),
 		broadcast:  make(chan []byte, 256),
 		register:   make(chan *WSClient),
 		unregister: make(chan *WSClient),
-		state:      "idle",
+		states:     states,
 	}
 }
 
@@ -105,7 +111,6 @@ func (h *WSHub) OnSessionStart(session *engine.CraftingSession, cfg *config.Conf
 	h.mu.Lock()
 	h.activeSession = session
 	h.activeConfig = cfg
-	h.state = "running"
 	h.mu.Unlock()
 }
 
@@ -114,22 +119,14 @@ func (h *WSHub) OnSessionEnd() {
 	h.mu.Lock()
 	h.activeSession = nil
 	h.activeConfig = nil
-	h.state = "idle"
 	h.mu.Unlock()
 }
 
-// GetState implements engine.SessionManager
-func (h *WSHub) GetState() string {
+// ActiveSession returns the session being crafted, or nil
+func (h *WSHub) ActiveSession() *engine.CraftingSession {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return h.state
-}
-
-// SetState implements engine.SessionManager
-func (h *WSHub) SetState(state string) {
-	h.mu.Lock()
-	h.state = state
-	h.mu.Unlock()
+	return h.activeSession
 }
 
 // updateState updates the hub's tracked state based on events
@@ -138,10 +135,6 @@ func (h *WSHub) updateState(msgType string, data interface{}) {
 	defer h.mu.Unlock()
 
 	switch msgType {
-	case "state_change":
-		if d, ok := data.(engine.StateChangeData); ok {
-			h.state = d.State
-		}
 	case "roll_attempted":
 		if d, ok := data.(engine.RollAttemptedData); ok {
 			h.currentAttempt = d.AttemptNum
@@ -163,13 +156,14 @@ func (h *WSHub) sendCurrentState(client *WSClient) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	stateMsg, _ := engine.MarshalWSMessage("state_change", engine.StateChangeData{State: h.state})
+	state := h.states.State()
+	stateMsg, _ := engine.MarshalWSMessage("state_change", engine.StateChangeData{State: string(state), Error: h.states.LastError()})
 	select {
 	case client.send <- stateMsg:
 	default:
 	}
 
-	if h.state == "running" && h.activeSession != nil {
+	if (state == engine.StateRunning || state == engine.StatePaused) && h.activeSession != nil {
 		duration := time.Since(h.activeSession.StartTime)
 		rollsPerMin := 0.0
 		if duration.Minutes() > 0 {
@@ -207,7 +201,8 @@ func (h *WSHub) GetStatusJSON() ([]byte, error) {
 	defer h.mu.RUnlock()
 
 	status := map[string]interface{}{
-		"state":       h.state,
+		"state":       h.states.State(),
+		"error":       h.states.LastError(),
 		"currentItem": h.currentItem,
 		"attempt":     h.currentAttempt,
 		"maxAttempts": h.maxAttempts,
@@ -0,0 +1,456 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobStopped   = "stopped" // Stopped by the user, which also pauses the queue
+	JobCancelled = "cancelled"
+)
+
+// errJobNotFound is returned for an unknown job ID
+var errJobNotFound = errors.New("job not found")
+
+// Job is one run of a saved profile in the queue
+type Job struct {
+	ID         int        `json:"id"`
+	Profile    string     `json:"profile"`
+	MaxItems   int        `json:"maxItems,omitempty"`  // Overrides the profile's item limit (0 = profile's)
+	OrbBudget  int        `json:"orbBudget,omitempty"` // Overrides the profile's orb budget (0 = profile's)
+	StartAt    *time.Time `json:"startAt,omitempty"`   // Earliest start (nil = right after the previous job)
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Items      int        `json:"items"`
+	Rolls      int        `json:"rolls"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// QueueProgressData is sent as queue_progress whenever a job or the queue changes
+type QueueProgressData struct {
+	Jobs   []Job `json:"jobs"`
+	Paused bool  `json:"paused"`
+}
+
+// queueFile is the saved form of the queue
+type queueFile struct {
+	NextID int    `json:"nextId"`
+	Paused bool   `json:"paused"`
+	Jobs   []*Job `json:"jobs"`
+}
+
+// JobQueue runs saved profiles back to back through Engine.Craft and keeps its jobs
+// in a file, so a queue survives a restart of the server
+type JobQueue struct {
+	mu            sync.Mutex
+	eng           *engine.Engine
+	hub           *WSHub
+	path          string
+	jobs          []*Job
+	nextID        int
+	paused        bool
+	running       *Job
+	cancelRunning bool // The running job was cancelled rather than stopped
+}
+
+// NewJobQueue loads the queue saved at path. A job that was running when the server
+// went down is queued again.
+func NewJobQueue(eng *engine.Engine, hub *WSHub, path string) *JobQueue {
+	q := &JobQueue{eng: eng, hub: hub, path: path, nextID: 1}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("⚠ Warning: Could not read job queue: %v\n", err)
+		}
+		return q
+	}
+	var saved queueFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		fmt.Printf("⚠ Warning: Could not read job queue: %v\n", err)
+		return q
+	}
+
+	q.jobs, q.paused = saved.Jobs, saved.Paused
+	if saved.NextID > q.nextID {
+		q.nextID = saved.NextID
+	}
+	for _, job := range q.jobs {
+		if job.Status == JobRunning {
+			job.Status, job.StartedAt, job.Items, job.Rolls = JobQueued, nil, 0, 0
+		}
+		if job.ID >= q.nextID {
+			q.nextID = job.ID + 1
+		}
+	}
+	return q
+}
+
+// Run starts queued jobs whenever the engine is free, until the server exits
+func (q *JobQueue) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.tick()
+	}
+}
+
+// List returns a copy of the jobs in queue order
+func (q *JobQueue) List() QueueProgressData {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.snapshot()
+}
+
+// Enqueue adds a job for a saved profile to the end of the queue
+func (q *JobQueue) Enqueue(job Job) (Job, error) {
+	if _, err := config.LoadProfile(job.Profile); err != nil {
+		return Job{}, err
+	}
+	if job.MaxItems < 0 || job.OrbBudget < 0 {
+		return Job{}, fmt.Errorf("item limit and orb budget cannot be negative")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	added := &Job{
+		ID:        q.nextID,
+		Profile:   job.Profile,
+		MaxItems:  job.MaxItems,
+		OrbBudget: job.OrbBudget,
+		StartAt:   job.StartAt,
+		Status:    JobQueued,
+		CreatedAt: time.Now(),
+	}
+	q.nextID++
+	q.jobs = append(q.jobs, added)
+	q.changed()
+	return *added, nil
+}
+
+// Reorder puts the jobs in the order of ids, which must list every job exactly once
+func (q *JobQueue) Reorder(ids []int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(ids) != len(q.jobs) {
+		return fmt.Errorf("expected %d job IDs, got %d", len(q.jobs), len(ids))
+	}
+
+	ordered := make([]*Job, 0, len(ids))
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		job := q.find(id)
+		if job == nil || seen[id] {
+			return fmt.Errorf("job %d: %w", id, errJobNotFound)
+		}
+		seen[id] = true
+		ordered = append(ordered, job)
+	}
+	q.jobs = ordered
+	q.changed()
+	return nil
+}
+
+// Cancel drops a queued job or stops the running one. A finished job is removed from the list.
+func (q *JobQueue) Cancel(id int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.find(id)
+	if job == nil {
+		return fmt.Errorf("job %d: %w", id, errJobNotFound)
+	}
+
+	switch job.Status {
+	case JobQueued:
+		q.finish(job, JobCancelled, "")
+	case JobRunning:
+		// runJob marks the job once Craft has returned
+		q.cancelRunning = true
+		q.eng.RequestStop()
+		return nil
+	default:
+		for i, j := range q.jobs {
+			if j == job {
+				q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+				break
+			}
+		}
+	}
+	q.changed()
+	return nil
+}
+
+// SetPaused stops or allows starting further jobs. The running job is not affected.
+func (q *JobQueue) SetPaused(paused bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.paused = paused
+	q.changed()
+}
+
+// tick starts the next job when the queue and the engine are both free
+func (q *JobQueue) tick() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.paused || q.running != nil {
+		return
+	}
+	if state := q.eng.State(); state != engine.StateIdle && state != engine.StateError {
+		return
+	}
+
+	var job *Job
+	for _, j := range q.jobs {
+		if j.Status == JobQueued {
+			job = j
+			break
+		}
+	}
+	if job == nil || (job.StartAt != nil && time.Now().Before(*job.StartAt)) {
+		return
+	}
+
+	cfg, err := config.LoadProfile(job.Profile)
+	if err != nil {
+		fmt.Printf("❌ ERROR: Queue job #%d: %v\n", job.ID, err)
+		q.finish(job, JobFailed, err.Error())
+		q.changed()
+		return
+	}
+	cfg.ApplyCraftDefaults()
+	if job.MaxItems > 0 {
+		cfg.MaxItems = job.MaxItems
+	}
+	if job.OrbBudget > 0 {
+		cfg.OrbBudget = job.OrbBudget
+	}
+
+	if err := launchSession(q.eng, func(ctx context.Context) { q.runJob(ctx, job, cfg) }); err != nil {
+		// A session started from the dashboard got in first, try again later
+		return
+	}
+	now := time.Now()
+	job.Status, job.StartedAt = JobRunning, &now
+	q.running = job
+	q.changed()
+}
+
+// runJob crafts one job after its countdown and records how it ended
+func (q *JobQueue) runJob(ctx context.Context, job *Job, cfg config.Config) {
+	if ctx.Err() == nil {
+		fmt.Printf("\n📋 Queue job #%d: %s\n", job.ID, job.Profile)
+		stopTracking := q.trackProgress(job)
+		q.eng.Craft(ctx, cfg)
+		stopTracking()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch {
+	case q.cancelRunning:
+		q.finish(job, JobCancelled, "")
+	case ctx.Err() != nil:
+		// Whoever stopped the job probably wants the next one to wait as well
+		q.finish(job, JobStopped, "")
+		q.paused = true
+	case q.eng.State() == engine.StateError:
+		q.finish(job, JobFailed, q.eng.LastError())
+	default:
+		q.finish(job, JobDone, "")
+	}
+	q.changed()
+}
+
+// trackProgress copies the item and roll counts of the live session into job every second,
+// until the returned function is called. It reads them through Progress, since the engine
+// keeps writing them meanwhile.
+func (q *JobQueue) trackProgress(job *Job) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		// The hub forgets the session when Craft returns, so keep it for the last update
+		var session *engine.CraftingSession
+		update := func() {
+			if active := q.hub.ActiveSession(); active != nil {
+				session = active
+			}
+			if session == nil {
+				return
+			}
+			items, rolls := session.Progress()
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if items != job.Items || rolls != job.Rolls {
+				job.Items, job.Rolls = items, rolls
+				q.changed()
+			}
+		}
+
+		for {
+			select {
+			case <-ticker.C:
+				update()
+			case <-done:
+				update()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// finish gives a job its final status
+func (q *JobQueue) finish(job *Job, status, errMsg string) {
+	now := time.Now()
+	job.Status, job.Error, job.FinishedAt = status, errMsg, &now
+	if job == q.running {
+		q.running, q.cancelRunning = nil, false
+	}
+}
+
+// find returns the job with the given ID, or nil
+func (q *JobQueue) find(id int) *Job {
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// snapshot copies the queue for JSON
+func (q *JobQueue) snapshot() QueueProgressData {
+	jobs := make([]Job, len(q.jobs))
+	for i, job := range q.jobs {
+		jobs[i] = *job
+	}
+	return QueueProgressData{Jobs: jobs, Paused: q.paused}
+}
+
+// changed saves the queue and sends queue_progress. Called with q.mu held.
+func (q *JobQueue) changed() {
+	data, err := json.MarshalIndent(queueFile{NextID: q.nextID, Paused: q.paused, Jobs: q.jobs}, "", "  ")
+	if err == nil {
+		tmp := q.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, q.path)
+		}
+	}
+	if err != nil {
+		fmt.Printf("⚠ Warning: Could not save job queue: %v\n", err)
+	}
+	q.hub.Broadcast("queue_progress", q.snapshot())
+}
+
+// handleQueue lists the jobs (GET) or adds one (POST)
+func handleQueue(w http.ResponseWriter, r *http.Request, q *JobQueue) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case "GET":
+		json.NewEncoder(w).Encode(q.List())
+	case "POST":
+		var job Job
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			http.Error(w, `{"error":"invalid job"}`, http.StatusBadRequest)
+			return
+		}
+		added, err := q.Enqueue(job)
+		if err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(msg), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(added)
+	default:
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+	}
+}
+
+func handleQueueReorder(w http.ResponseWriter, r *http.Request, q *JobQueue) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		IDs []int `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	if err := q.Reorder(req.IDs); err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q.List())
+}
+
+func handleQueueCancel(w http.ResponseWriter, r *http.Request, q *JobQueue) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	if err := q.Cancel(req.ID); err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(msg), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q.List())
+}
+
+func handleQueuePause(w http.ResponseWriter, r *http.Request, q *JobQueue) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Paused bool `json:"paused"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	q.SetPaused(req.Paused)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q.List())
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+	"poe2-chaos-crafter/internal/engine"
+)
+
+// newTestQueue returns a queue saved in a temp dir, with profiles "rings" and "amulets"
+func newTestQueue(t *testing.T) (*JobQueue, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, name := range []string{"rings", "amulets"} {
+		if err := config.SaveProfile(name, config.Config{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(home, "queue.json")
+	return openQueue(path), path
+}
+
+func openQueue(path string) *JobQueue {
+	eng := engine.NewEngine(false)
+	return NewJobQueue(eng, NewWSHub(eng), path)
+}
+
+func enqueue(t *testing.T, q *JobQueue, profile string) Job {
+	t.Helper()
+	job, err := q.Enqueue(Job{Profile: profile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func jobIDs(q *JobQueue) []int {
+	var ids []int
+	for _, job := range q.List().Jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestJobQueuePersists(t *testing.T) {
+	q, path := newTestQueue(t)
+	enqueue(t, q, "rings")
+	enqueue(t, q, "amulets")
+	q.SetPaused(true)
+	if _, err := q.Enqueue(Job{Profile: "belts"}); err == nil {
+		t.Error("Enqueue() accepted a profile that does not exist")
+	}
+	if _, err := q.Enqueue(Job{Profile: "rings", MaxItems: -1}); err == nil {
+		t.Error("Enqueue() accepted a negative item limit")
+	}
+
+	reopened := openQueue(path)
+	list := reopened.List()
+	if !list.Paused || len(list.Jobs) != 2 || list.Jobs[0].Profile != "rings" || list.Jobs[1].Profile != "amulets" {
+		t.Fatalf("reopened queue = %+v, want rings and amulets, paused", list)
+	}
+	if job := enqueue(t, reopened, "rings"); job.ID != 3 {
+		t.Errorf("next job ID after a restart = %d, want 3", job.ID)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("the temp file was left behind")
+	}
+}
+
+func TestJobQueueReorder(t *testing.T) {
+	q, path := newTestQueue(t)
+	for _, profile := range []string{"rings", "amulets", "rings"} {
+		enqueue(t, q, profile)
+	}
+
+	invalid := map[string][]int{
+		"missing job":   {1, 2},
+		"extra job":     {1, 2, 3, 4},
+		"unknown job":   {1, 2, 9},
+		"duplicate job": {1, 2, 2},
+	}
+	for name, ids := range invalid {
+		if err := q.Reorder(ids); err == nil {
+			t.Errorf("%s: Reorder(%v) did not fail", name, ids)
+		}
+		if got := jobIDs(q); !sameIDs(got, []int{1, 2, 3}) {
+			t.Fatalf("%s: a rejected Reorder changed the order to %v", name, got)
+		}
+	}
+
+	if err := q.Reorder([]int{3, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := jobIDs(openQueue(path)); !sameIDs(got, []int{3, 1, 2}) {
+		t.Errorf("saved order = %v, want [3 1 2]", got)
+	}
+}
+
+func TestJobQueueCancel(t *testing.T) {
+	q, _ := newTestQueue(t)
+	queued := enqueue(t, q, "rings")
+	running := enqueue(t, q, "amulets")
+
+	if err := q.Cancel(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job := q.find(queued.ID); job == nil || job.Status != JobCancelled || job.FinishedAt == nil {
+		t.Fatalf("cancelled queued job = %+v, want it kept as cancelled", job)
+	}
+	// A second cancel removes the finished job
+	if err := q.Cancel(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+	if q.find(queued.ID) != nil {
+		t.Error("the finished job is still listed")
+	}
+	if err := q.Cancel(42); !errors.Is(err, errJobNotFound) {
+		t.Errorf("Cancel(42) = %v, want errJobNotFound", err)
+	}
+
+	// Stand in for tick: the job's session is crafting
+	ctx, cancel := q.eng.SessionContext(context.Background())
+	defer cancel()
+	if err := q.eng.Transition(engine.StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	job := q.find(running.ID)
+	now := time.Now()
+	job.Status, job.StartedAt = JobRunning, &now
+	q.running = job
+
+	if err := q.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() == nil || q.eng.State() != engine.StateStopping {
+		t.Fatalf("Cancel() of the running job left the session %s", q.eng.State())
+	}
+	if job.Status != JobRunning {
+		t.Errorf("status right after Cancel() = %s, want running until Craft returns", job.Status)
+	}
+
+	q.runJob(ctx, job, config.Config{})
+	if job.Status != JobCancelled || q.running != nil {
+		t.Errorf("status after the run = %s, running %v; want cancelled and nothing running", job.Status, q.running)
+	}
+	if q.List().Paused {
+		t.Error("cancelling the running job paused the queue")
+	}
+}
+
+func TestJobQueueRequeuesRunningJobOnRestart(t *testing.T) {
+	q, path := newTestQueue(t)
+	first := enqueue(t, q, "rings")
+	second := enqueue(t, q, "amulets")
+
+	now := time.Now()
+	q.mu.Lock()
+	job := q.find(first.ID)
+	job.Status, job.StartedAt, job.Items, job.Rolls = JobRunning, &now, 2, 40
+	q.changed()
+	q.mu.Unlock()
+
+	reopened := openQueue(path)
+	list := reopened.List()
+	if len(list.Jobs) != 2 {
+		t.Fatalf("reopened queue has %d jobs, want 2", len(list.Jobs))
+	}
+	got := list.Jobs[0]
+	if got.ID != first.ID || got.Status != JobQueued || got.StartedAt != nil || got.Items != 0 || got.Rolls != 0 {
+		t.Errorf("interrupted job after a restart = %+v, want it queued from scratch", got)
+	}
+	if list.Jobs[1].ID != second.ID || list.Jobs[1].Status != JobQueued {
+		t.Errorf("second job = %+v, want it still queued", list.Jobs[1])
+	}
+	if reopened.running != nil {
+		t.Error("the reopened queue thinks a job is running")
+	}
+}
+
+func TestTrackProgressCopiesSessionCounts(t *testing.T) {
+	q, _ := newTestQueue(t)
+	job := enqueue(t, q, "rings")
+	session := &engine.CraftingSession{TotalRolls: 7, RoundResults: make([]engine.RoundResult, 2)}
+	q.hub.OnSessionStart(session, &config.Config{})
+
+	stop := q.trackProgress(q.jobs[0])
+	stop()
+	q.hub.OnSessionEnd()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if got := q.jobs[0]; got.ID != job.ID || got.Items != 2 || got.Rolls != 7 {
+		t.Errorf("job %d tracked %d items and %d rolls, want 2 and 7", got.ID, got.Items, got.Rolls)
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"embed"
 	"encoding/base64"
 	"encoding/json"
@@ -20,8 +21,9 @@ import (
 
 	"poe2-chaos-crafter/internal/config"
 	"poe2-chaos-crafter/internal/engine"
+	"poe2-chaos-crafter/internal/modcatalog"
+	"poe2-chaos-crafter/internal/ocreval"
 
-	"github.com/go-vgo/robotgo"
 	"github.com/gorilla/websocket"
 	"golang.org/x/image/draw"
 )
@@ -30,17 +32,55 @@ import (
 var webFS embed.FS
 
 var upgrader = websocket.Upgrader{
+	// access.wrap has already checked the origin against the allow-list
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
 }
 
-// StartWebServer starts the web GUI server
-func StartWebServer(port int, eng *engine.Engine) {
-	hub := NewWSHub()
-	eng.Broadcaster = hub
-	eng.SessionManager = hub
-	go hub.Run()
+// Options override the server settings file for one run, e.g. from command-line flags
+type Options struct {
+	Bind      string // Address to listen on (empty = settings)
+	Port      int    // Port to listen on (0 = settings)
+	LocalOnly bool   // Listen on 127.0.0.1 only even if the settings do not
+	Password  string // Sign-in password (empty = settings)
+	TLS       bool   // Serve HTTPS even if the settings do not
+	CertFile  string // PEM certificate for HTTPS, implies TLS (empty = settings)
+	KeyFile   string // PEM private key of CertFile
+}
+
+// apply lays the options over the settings file
+func (o Options) apply(s *config.ServerSettings) {
+	if o.Bind != "" {
+		s.Bind = o.Bind
+	}
+	if o.Port != 0 {
+		s.Port = o.Port
+	}
+	if o.Password != "" {
+		s.Password = o.Password
+	}
+	s.LocalOnly = s.LocalOnly || o.LocalOnly
+	if o.CertFile != "" || o.KeyFile != "" {
+		s.CertFile, s.KeyFile = o.CertFile, o.KeyFile
+		s.TLS = true
+	}
+	s.TLS = s.TLS || o.TLS
+}
+
+// StartWebServer starts the web GUI server for eng and the extra engines in the engines file
+func StartWebServer(opts Options, eng *engine.Engine) {
+	settings, err := config.LoadServerSettings()
+	if err == nil {
+		opts.apply(&settings)
+		err = settings.Validate()
+	}
+	if err != nil {
+		fmt.Printf("❌ Could not load %s: %v\n", config.GetServerSettingsPath(), err)
+		os.Exit(1)
+	}
+	acc := newAccess(settings)
+	engines := newEngineSet(eng)
 
 	mux := http.NewServeMux()
 
@@ -50,57 +90,134 @@ func StartWebServer(port int, eng *engine.Engine) {
 	mux.Handle("/", fileServer)
 
 	// WebSocket endpoint
-	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		handleWebSocket(w, r, hub)
-	})
+	mux.HandleFunc("/ws", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleWebSocket(w, r, m.hub)
+	}))
+
+	// Sign-in and pairing, see access
+	mux.HandleFunc("/api/auth/status", acc.handleAuthStatus)
+	mux.HandleFunc("/api/auth/login", acc.handleAuthLogin)
+	mux.HandleFunc("/api/auth/pair", acc.handleAuthPair)
+	mux.HandleFunc("/pair", acc.handlePair)
 
 	// REST API
 	mux.HandleFunc("/api/config", handleConfig)
 	mux.HandleFunc("/api/config/reload", handleConfigReload)
-	mux.HandleFunc("/api/craft/start", func(w http.ResponseWriter, r *http.Request) {
-		handleCraftStart(w, r, eng, hub)
-	})
-	mux.HandleFunc("/api/craft/stop", func(w http.ResponseWriter, r *http.Request) {
-		handleCraftStop(w, r, eng)
-	})
-	mux.HandleFunc("/api/craft/pause", func(w http.ResponseWriter, r *http.Request) {
-		handleCraftPause(w, r, eng)
-	})
-	mux.HandleFunc("/api/craft/status", func(w http.ResponseWriter, r *http.Request) {
-		handleCraftStatus(w, r, hub)
-	})
-	mux.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
-		handleSession(w, r, hub)
-	})
-	mux.HandleFunc("/api/wizard/capture", func(w http.ResponseWriter, r *http.Request) {
-		handleWizardCapture(w, r, eng)
-	})
-	mux.HandleFunc("/api/wizard/validate-tooltip", func(w http.ResponseWriter, r *http.Request) {
-		handleWizardValidateTooltip(w, r, eng)
+	mux.HandleFunc("/api/profiles", handleProfiles)
+	mux.HandleFunc("/api/engines", func(w http.ResponseWriter, r *http.Request) {
+		handleEngines(w, r, engines)
 	})
+
+	// Engine-scoped API, see engineSet.handle
+	mux.HandleFunc("/api/queue", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleQueue(w, r, m.queue)
+	}))
+	mux.HandleFunc("/api/queue/reorder", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleQueueReorder(w, r, m.queue)
+	}))
+	mux.HandleFunc("/api/queue/cancel", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleQueueCancel(w, r, m.queue)
+	}))
+	mux.HandleFunc("/api/queue/pause", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleQueuePause(w, r, m.queue)
+	}))
+	mux.HandleFunc("/api/craft/start", engines.handle(handleCraftStart))
+	mux.HandleFunc("/api/craft/resume", engines.handle(handleCraftResume))
+	mux.HandleFunc("/api/craft/stop", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCraftStop(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/craft/pause", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCraftPause(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/craft/attention", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCraftAttention(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/craft/status", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCraftStatus(w, r, m.hub)
+	}))
+	mux.HandleFunc("/api/session", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleSession(w, r, m.hub)
+	}))
+	mux.HandleFunc("/api/wizard/capture", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleWizardCapture(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/wizard/validate-tooltip", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleWizardValidateTooltip(w, r, m.eng)
+	}))
 	mux.HandleFunc("/api/wizard/parse-mod", handleWizardParseMod)
-	mux.HandleFunc("/api/snapshot/current-tooltip", handleCurrentTooltip)
-	mux.HandleFunc("/api/snapshot/screen", func(w http.ResponseWriter, r *http.Request) {
-		handleScreenCapture(w, r, hub)
-	})
+	mux.HandleFunc("/api/snapshot/current-tooltip", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCurrentTooltip(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/snapshot/attention", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleAttentionSnapshot(w, r, m.eng)
+	}))
+	mux.HandleFunc("/api/snapshot/screen", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleScreenCapture(w, r, m.eng)
+	}))
 	mux.HandleFunc("/api/mod-templates", handleModTemplates)
+	mux.HandleFunc("/api/corpus/promote", engines.handle(func(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+		handleCorpusPromote(w, r, m.hub, m.eng)
+	}))
+
+	port := fmt.Sprint(settings.Port)
+	if settings.Port == 0 {
+		port = "8080"
+	}
+	bind := settings.Bind
+	if settings.LocalOnly {
+		bind = "127.0.0.1"
+	}
+
+	// Addresses the banner and pairing links point at
+	localHost, lanIP := "localhost", getLANIP()
+	if bind != "" && isLoopbackHost(bind) {
+		lanIP = ""
+	} else if bind != "" {
+		localHost, lanIP = "", bind
+	}
 
-	lanIP := getLANIP()
+	scheme := "http"
+	var cert tls.Certificate
+	if settings.TLS {
+		hosts := []string{"localhost", "127.0.0.1"}
+		if lanIP != "" {
+			hosts = append(hosts, lanIP)
+		}
+		if cert, err = loadCertificate(settings, hosts); err != nil {
+			fmt.Printf("❌ Could not load the HTTPS certificate: %v\n", err)
+			os.Exit(1)
+		}
+		scheme = "https"
+	}
 
 	fmt.Println("\n╔═══════════════════════════════════════════════╗")
 	fmt.Println("║          POE2 Chaos Crafter - Web GUI         ║")
 	fmt.Println("╚═══════════════════════════════════════════════╝")
-	fmt.Printf("\n  Local:   http://localhost:%d\n", port)
+	fmt.Println()
+	if localHost != "" {
+		fmt.Printf("  Local:   %s://%s\n", scheme, net.JoinHostPort(localHost, port))
+	}
 	if lanIP != "" {
-		fmt.Printf("  Network: http://%s:%d\n", lanIP, port)
+		fmt.Printf("  Network: %s://%s\n", scheme, net.JoinHostPort(lanIP, port))
+	}
+	if settings.TLS {
+		fmt.Printf("  Cert:    SHA-256 %s\n", CertFingerprint(cert))
+	}
+	if len(engines.list) > 1 {
+		fmt.Printf("  Engines: %s\n", strings.Join(engines.names(), ", "))
+	}
+	fmt.Printf("  Sign-in: token and password in %s\n", config.GetServerSettingsPath())
+	if lanIP != "" {
+		fmt.Println()
+		acc.printPairing(fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(lanIP, port)))
 	}
 	fmt.Println("\n  Open in any browser (PC, phone, tablet)")
 	fmt.Println("  Press Ctrl+C to stop the server")
 	fmt.Println()
 
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      mux,
+		Addr:         net.JoinHostPort(bind, port),
+		Handler:      acc.wrap(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 	}
@@ -116,7 +233,13 @@ func StartWebServer(port int, eng *engine.Engine) {
 		server.Shutdown(ctx)
 	}()
 
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+	if settings.TLS {
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
 		fmt.Printf("Server error: %v\n", err)
 		os.Exit(1)
 	}
@@ -159,6 +282,11 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, `{"error":"invalid config"}`, http.StatusBadRequest)
 			return
 		}
+		if err := cfg.Validate(); err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(msg), http.StatusBadRequest)
+			return
+		}
 		if err := config.SaveConfig(cfg); err != nil {
 			http.Error(w, `{"error":"failed to save"}`, http.StatusInternalServerError)
 			return
@@ -186,61 +314,145 @@ func handleConfigReload(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(cfg)
 }
 
-func handleCraftStart(w http.ResponseWriter, r *http.Request, eng *engine.Engine, hub *WSHub) {
+// handleProfiles lists the saved profiles (GET) or saves the current config as one (POST)
+func handleProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		names, err := config.ListProfiles()
+		if err != nil {
+			http.Error(w, `{"error":"failed to list profiles"}`, http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(names)
+
+	case "POST":
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+			return
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			http.Error(w, `{"error":"no config found, run wizard first"}`, http.StatusBadRequest)
+			return
+		}
+		if err := config.SaveProfile(req.Name, cfg); err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(msg), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "saved"})
+
+	default:
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+	}
+}
+
+func handleCraftStart(w http.ResponseWriter, r *http.Request, m *managedEngine) {
 	if r.Method != "POST" {
 		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		return
 	}
 
-	if hub.GetState() == "running" {
-		http.Error(w, `{"error":"already running"}`, http.StatusConflict)
+	cfg, err := config.LoadCraftConfig(m.profile)
+	if err != nil {
+		http.Error(w, `{"error":"no config found, run wizard first"}`, http.StatusBadRequest)
 		return
 	}
 
-	cfg, err := config.LoadConfig()
+	startSession(w, m.eng, func(ctx context.Context) {
+		m.eng.Craft(ctx, cfg)
+	})
+}
+
+// handleCraftResume reports the interrupted session on GET and continues it on POST
+func handleCraftResume(w http.ResponseWriter, r *http.Request, m *managedEngine) {
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	cp, err := m.eng.LoadCheckpoint()
 	if err != nil {
-		http.Error(w, `{"error":"no config found, run wizard first"}`, http.StatusBadRequest)
+		msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(msg), http.StatusInternalServerError)
 		return
 	}
 
-	cfg.TooltipRect.Min.X = cfg.ItemPos.X + cfg.TooltipOffset.X
-	cfg.TooltipRect.Min.Y = cfg.ItemPos.Y + cfg.TooltipOffset.Y
-	cfg.TooltipRect.Max.X = cfg.TooltipRect.Min.X + cfg.TooltipSize.X
-	cfg.TooltipRect.Max.Y = cfg.TooltipRect.Min.Y + cfg.TooltipSize.Y
+	if r.Method == "GET" {
+		status := map[string]interface{}{"available": cp != nil}
+		if cp != nil {
+			status["summary"] = cp.Summary()
+			status["savedAt"] = cp.SavedAt
+			status["items"] = len(cp.Session.RoundResults)
+			status["rolls"] = cp.Session.TotalRolls
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+		return
+	}
 
-	cfg.UseBatchMode = true
-	if cfg.ItemWidth == 0 {
-		cfg.ItemWidth = 1
+	if cp == nil {
+		http.Error(w, `{"error":"no interrupted session to resume"}`, http.StatusNotFound)
+		return
 	}
-	if cfg.ItemHeight == 0 {
-		cfg.ItemHeight = 1
+	cfg, err := config.LoadCraftConfig(m.profile)
+	if err != nil {
+		http.Error(w, `{"error":"no config found, run wizard first"}`, http.StatusBadRequest)
+		return
 	}
-	if cfg.ChaosPerRound == 0 {
-		cfg.ChaosPerRound = 10
+
+	startSession(w, m.eng, func(ctx context.Context) {
+		m.eng.Resume(ctx, cfg, cp)
+	})
+}
+
+// startSession counts down and then calls run, unless the session is stopped first
+func startSession(w http.ResponseWriter, eng *engine.Engine, run func(ctx context.Context)) {
+	err := launchSession(eng, func(ctx context.Context) {
+		if ctx.Err() == nil {
+			run(ctx)
+		}
+	})
+	if err != nil {
+		writeStateError(w, err)
+		return
 	}
 
-	eng.StopRequested.Store(false)
-	eng.PauseRequested.Store(false)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
+}
 
+// launchSession starts the countdown in the background and calls run when it ends.
+// ctx is already cancelled if the session was stopped during the countdown.
+func launchSession(eng *engine.Engine, run func(ctx context.Context)) error {
+	if err := eng.Transition(engine.StateCountdown); err != nil {
+		return err
+	}
+
+	// Each session gets its own context, so a stop cancels the countdown as well as the run
+	ctx, cancel := eng.SessionContext(context.Background())
 	go func() {
-		eng.Emit("state_change", engine.StateChangeData{State: "countdown"})
-		for i := 5; i > 0; i-- {
+		defer cancel()
+		for i := 5; i > 0 && ctx.Err() == nil; i-- {
 			eng.Emit("craft_countdown", engine.CraftCountdownData{SecondsLeft: i})
 			fmt.Printf("\rStarting in %d... ", i)
-			time.Sleep(1 * time.Second)
-			if eng.StopRequested.Load() {
-				eng.Emit("state_change", engine.StateChangeData{State: "idle"})
-				return
-			}
+			engine.Sleep(ctx, time.Second)
+		}
+		if ctx.Err() == nil {
+			fmt.Println("\rStarting crafting!   ")
+		}
+		run(ctx)
+		// A stop during the countdown never reaches Craft, which otherwise returns to idle itself
+		if eng.State() == engine.StateStopping {
+			eng.Transition(engine.StateIdle)
 		}
-		fmt.Println("\rStarting crafting!   ")
-		eng.Emit("state_change", engine.StateChangeData{State: "running"})
-		eng.Craft(cfg)
-		eng.Emit("state_change", engine.StateChangeData{State: "idle"})
 	}()
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
+	return nil
 }
 
 func handleCraftStop(w http.ResponseWriter, r *http.Request, eng *engine.Engine) {
@@ -249,8 +461,10 @@ func handleCraftStop(w http.ResponseWriter, r *http.Request, eng *engine.Engine)
 		return
 	}
 
-	eng.StopRequested.Store(true)
-	eng.Emit("state_change", engine.StateChangeData{State: "stopped"})
+	if err := eng.RequestStop(); err != nil {
+		writeStateError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "stopping"})
@@ -262,17 +476,46 @@ func handleCraftPause(w http.ResponseWriter, r *http.Request, eng *engine.Engine
 		return
 	}
 
-	current := eng.PauseRequested.Load()
-	eng.PauseRequested.Store(!current)
-
-	state := "running"
-	if !current {
-		state = "paused"
+	state, err := eng.TogglePause()
+	if err != nil {
+		writeStateError(w, err)
+		return
 	}
-	eng.Emit("state_change", engine.StateChangeData{State: state})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": state})
+	json.NewEncoder(w).Encode(map[string]string{"status": string(state)})
+}
+
+// writeStateError answers a request the engine state does not allow with 409 Conflict
+func writeStateError(w http.ResponseWriter, err error) {
+	msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+	http.Error(w, string(msg), http.StatusConflict)
+}
+
+// handleCraftAttention returns the pending needs_attention request (GET) or answers it (POST)
+func handleCraftAttention(w http.ResponseWriter, r *http.Request, eng *engine.Engine) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{"pending": eng.PendingAttention()})
+	case "POST":
+		var req struct {
+			ID     int    `json:"id"`
+			Answer string `json:"answer"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+			return
+		}
+		if err := eng.AnswerAttention(req.ID, req.Answer); err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(msg), http.StatusConflict)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "answered"})
+	default:
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+	}
 }
 
 func handleCraftStatus(w http.ResponseWriter, r *http.Request, hub *WSHub) {
@@ -336,7 +579,11 @@ func handleWizardCapture(w http.ResponseWriter, r *http.Request, eng *engine.Eng
 			time.Sleep(1 * time.Second)
 		}
 
-		x, y := robotgo.GetMousePos()
+		x, y, err := eng.Backend.MousePos()
+		if err != nil {
+			fmt.Printf("⚠ Warning: Could not read the mouse position: %v\n", err)
+			return
+		}
 		eng.Emit("capture_result", engine.CaptureResultData{
 			Field: req.Field,
 			X:     x,
@@ -373,17 +620,27 @@ func handleWizardValidateTooltip(w http.ResponseWriter, r *http.Request, eng *en
 		return
 	}
 
-	bitmap := robotgo.CaptureScreen(req.X1, req.Y1, width, height)
-	img := robotgo.ToImage(bitmap)
+	img, err := eng.Backend.Capture(req.X1, req.Y1, width, height)
+	if err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "capture failed: " + err.Error()})
+		http.Error(w, string(msg), http.StatusBadGateway)
+		return
+	}
 
-	os.MkdirAll(config.SnapshotsDir, 0755)
-	tooltipFile := filepath.Join(config.SnapshotsDir, "tooltip_area_validation.png")
+	os.MkdirAll(eng.SnapshotsDir, 0755)
+	tooltipFile := filepath.Join(eng.SnapshotsDir, "tooltip_area_validation.png")
 	engine.SaveImage(img, tooltipFile)
 
 	tempDir := filepath.Join(os.TempDir(), "poe2_crafter_setup")
 	os.MkdirAll(tempDir, 0755)
 
-	ocrText, err := eng.RunTesseractOCR(img, tempDir, req.GameLanguage)
+	// Use the saved preprocessing profile when one exists
+	var pre config.PreprocessConfig
+	if cfg, err := config.LoadConfig(); err == nil {
+		pre = cfg.Preprocess
+	}
+
+	ocrText, err := eng.RunTesseractOCR(r.Context(), img, tempDir, req.GameLanguage, pre)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -441,13 +698,13 @@ func handleWizardParseMod(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(mod)
 }
 
-func handleCurrentTooltip(w http.ResponseWriter, r *http.Request) {
+func handleCurrentTooltip(w http.ResponseWriter, r *http.Request, eng *engine.Engine) {
 	if r.Method != "GET" {
 		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		return
 	}
 
-	filePath := filepath.Join(config.SnapshotsDir, "current_tooltip.png")
+	filePath := filepath.Join(eng.SnapshotsDir, "current_tooltip.png")
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		http.Error(w, `{"error":"no tooltip yet"}`, http.StatusNotFound)
 		return
@@ -458,20 +715,85 @@ func handleCurrentTooltip(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filePath)
 }
 
-func handleScreenCapture(w http.ResponseWriter, r *http.Request, hub *WSHub) {
+func handleAttentionSnapshot(w http.ResponseWriter, r *http.Request, eng *engine.Engine) {
+	if r.Method != "GET" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	filePath := filepath.Join(eng.SnapshotsDir, engine.AttentionFile)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		http.Error(w, `{"error":"no snapshot"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, filePath)
+}
+
+func handleCorpusPromote(w http.ResponseWriter, r *http.Request, hub *WSHub, eng *engine.Engine) {
+	if r.Method != "POST" {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name         string `json:"name"`
+		GameLanguage string `json:"gameLanguage"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		req.Name = "tooltip_" + time.Now().Format("20060102_150405")
+	}
+
+	// Pre-fill expectations from the last OCR result; they should be reviewed by hand
+	hub.mu.RLock()
+	ocrText := hub.lastOCRText
+	hub.mu.RUnlock()
+	exp := ocreval.Expectation{
+		Mods:  engine.ParseMods(ocrText, req.GameLanguage),
+		Text:  ocrText,
+		Notes: "Auto-filled from live OCR, please verify",
+	}
+
+	srcPath := filepath.Join(eng.SnapshotsDir, "current_tooltip.png")
+	path, err := ocreval.PromoteImage(config.CorpusDir, req.GameLanguage, req.Name, srcPath, exp)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "saved",
+		"path":   path,
+		"mods":   exp.Mods,
+	})
+}
+
+func handleScreenCapture(w http.ResponseWriter, r *http.Request, eng *engine.Engine) {
 	if r.Method != "GET" {
 		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
 		return
 	}
 
-	state := hub.GetState()
-	if state != "running" && state != "paused" && state != "countdown" {
+	state := eng.State()
+	if state != engine.StateRunning && state != engine.StatePaused && state != engine.StateCountdown {
 		http.Error(w, `{"error":"crafting not active"}`, http.StatusServiceUnavailable)
 		return
 	}
 
-	bitmap := robotgo.CaptureScreen()
-	img := robotgo.ToImage(bitmap)
+	img, err := eng.Backend.Capture(0, 0, 0, 0)
+	if err != nil {
+		http.Error(w, `{"error":"capture failed"}`, http.StatusBadGateway)
+		return
+	}
 
 	small := downsampleImage(img, 4)
 
@@ -508,26 +830,21 @@ func handleModTemplates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	templates := []map[string]string{
-		{"key": "life", "name": "Life", "name_zh": "生命", "example": "life 80"},
-		{"key": "mana", "name": "Mana", "name_zh": "魔力", "example": "mana 60"},
-		{"key": "str", "name": "Strength", "name_zh": "力量", "example": "str 45"},
-		{"key": "dex", "name": "Dexterity", "name_zh": "敏捷", "example": "dex 45"},
-		{"key": "int", "name": "Intelligence", "name_zh": "智慧", "example": "int 45"},
-		{"key": "spirit", "name": "Spirit", "name_zh": "精魂", "example": "spirit 50"},
-		{"key": "spell-level", "name": "Spell Skills Level", "name_zh": "法术技能等级", "example": "spell-level 3"},
-		{"key": "proj-level", "name": "Projectile Skills Level", "name_zh": "投射物技能等级", "example": "proj-level 3"},
-		{"key": "crit-dmg", "name": "Critical Damage Bonus", "name_zh": "暴击伤害加成", "example": "crit-dmg 39"},
-		{"key": "fire-res", "name": "Fire Resistance", "name_zh": "火焰抗性", "example": "fire-res 30"},
-		{"key": "cold-res", "name": "Cold Resistance", "name_zh": "冰冷抗性", "example": "cold-res 30"},
-		{"key": "light-res", "name": "Lightning Resistance", "name_zh": "闪电抗性", "example": "light-res 30"},
-		{"key": "chaos-res", "name": "Chaos Resistance", "name_zh": "混沌抗性", "example": "chaos-res 20"},
-		{"key": "armor", "name": "Armour", "name_zh": "护甲", "example": "armor 100"},
-		{"key": "evasion", "name": "Evasion", "name_zh": "闪避", "example": "evasion 100"},
-		{"key": "es", "name": "Energy Shield", "name_zh": "能量护盾", "example": "es 50"},
-		{"key": "movespeed", "name": "Movement Speed", "name_zh": "移动速度", "example": "movespeed 20"},
-		{"key": "attackspeed", "name": "Attack Speed", "name_zh": "攻击速度", "example": "attackspeed 10"},
-		{"key": "castspeed", "name": "Cast Speed", "name_zh": "施放速度", "example": "castspeed 10"},
+	lang := r.URL.Query().Get("lang")
+	templates := []map[string]interface{}{}
+	for _, mod := range modcatalog.Default().Mods() {
+		loc := mod.Localized(lang)
+		if loc == nil {
+			continue
+		}
+		templates = append(templates, map[string]interface{}{
+			"key":     mod.ID,
+			"name":    loc.Name,
+			"tracker": mod.Name,
+			"kind":    mod.Kind,
+			"tags":    mod.Tags,
+			"example": fmt.Sprintf("%s %s", mod.ID, modcatalog.FormatValue(mod.Example)),
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
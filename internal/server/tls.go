@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"poe2-chaos-crafter/internal/config"
+)
+
+// selfSignedValidity stays under the 398 days phones accept for server certificates
+const selfSignedValidity = 397 * 24 * time.Hour
+
+// loadCertificate returns the certificate to serve HTTPS with: the user's CertFile and
+// KeyFile, or the self-signed certificate kept next to the config
+func loadCertificate(settings config.ServerSettings, hosts []string) (tls.Certificate, error) {
+	certPath, keyPath := config.GetSelfSignedCertPaths()
+	return LoadCertificate(settings.CertFile, settings.KeyFile, certPath, keyPath, hosts)
+}
+
+// LoadCertificate returns certFile and keyFile, or when those are empty the self-signed
+// certificate at certPath and keyPath. The self-signed one is made again when it is about
+// to expire or does not cover one of hosts.
+func LoadCertificate(certFile, keyFile, certPath, keyPath string, hosts []string) (tls.Certificate, error) {
+	if certFile != "" {
+		return tls.LoadX509KeyPair(certFile, keyFile)
+	}
+
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil && coversHosts(cert, hosts) {
+		return cert, nil
+	}
+
+	fmt.Printf("🔐 Creating a self-signed certificate for %s\n", strings.Join(hosts, ", "))
+	if err := createSelfSigned(certPath, keyPath, hosts); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+// coversHosts reports whether cert is valid for another day for every host
+func coversHosts(cert tls.Certificate, hosts []string) bool {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil || time.Now().Add(24*time.Hour).After(leaf.NotAfter) {
+		return false
+	}
+	for _, host := range hosts {
+		if leaf.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// createSelfSigned writes a new ECDSA certificate and key for hosts (names or IP addresses)
+func createSelfSigned(certPath, keyPath string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "POE2 Chaos Crafter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(selfSignedValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// CertFingerprint returns the SHA-256 fingerprint browsers show for cert, e.g. "3A:F0:..."
+func CertFingerprint(cert tls.Certificate) string {
+	sum := sha256.Sum256(cert.Certificate[0])
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
@@ -15,11 +15,16 @@ const i18n = {
         'status.item': 'Item:',
         'status.roll': 'Roll:',
         'status.totalRolls': 'Total Rolls:',
+        'status.unchanged': 'Unchanged / Timed Out:',
+        'status.best': 'Best Roll:',
+        'status.score': 'Score / Needed:',
+        'status.affixes': 'Prefixes / Suffixes:',
         'status.speed': 'Speed:',
         'status.duration': 'Duration:',
         'btn.start': 'Start',
         'btn.pause': 'Pause',
         'btn.resume': 'Resume',
+        'btn.resumeSession': 'Resume Session',
         'btn.stop': 'Stop',
         'btn.refresh': 'Refresh',
         'btn.back': 'Back',
@@ -28,6 +33,7 @@ const i18n = {
         'btn.add': 'Add',
         'btn.edit': 'Edit',
         'btn.cancel': 'Cancel',
+        'btn.saveToCorpus': 'Save to Corpus',
         'panel.snapshot': 'Live Game Snapshot',
         'panel.ocrText': 'Parsed Mod Text',
         'panel.tooltip': 'Tooltip',
@@ -50,7 +56,8 @@ const i18n = {
         'state.starting': 'Starting...',
         'state.running': 'Running',
         'state.paused': 'Paused',
-        'state.stopped': 'Stopped',
+        'state.stopping': 'Stopping...',
+        'state.error': 'Error',
         'state.startingIn': 'Starting in {n}...',
         'wiz.step1.title': 'Step 1: Configuration',
         'wiz.step1.desc': 'Load existing config or start fresh?',
@@ -82,11 +89,11 @@ const i18n = {
         'wiz.validateOCR': 'Validate OCR',
         'wiz.step7.title': 'Step 7: Target Mods',
         'wiz.step7.desc': 'Select which mods to search for. Format:',
-        'wiz.step7.format': 'mod_name min_value',
+        'wiz.step7.format': 'mod_name min_value (or <=max, ==value, min-max)',
         'wiz.quickTemplate': '-- Quick Template --',
         'wiz.minValue': 'Min value',
         'wiz.addCustom': 'Add Custom',
-        'wiz.customPlaceholder': 'e.g. life 80, fire-res 30',
+        'wiz.customPlaceholder': 'e.g. life 80, life <=40, fire-res 30-35',
         'wiz.step8.title': 'Step 8: Options & Review',
         'wiz.chaosPerRound': 'Chaos Orbs per Round:',
         'wiz.ocrDebug': 'Enable OCR debug logging',
@@ -105,6 +112,17 @@ const i18n = {
         'cfg.bpBottomRight': 'Backpack Bottom-Right',
         'cfg.item': 'Item',
         'cfg.itemSize': 'Item Size',
+        'cfg.expectedBase': 'Expected Base',
+        'cfg.expectedClass': 'Expected Class',
+        'cfg.itemLevel': 'Item Level',
+        'cfg.onMismatch': 'On Mismatch',
+        'cfg.any': 'any',
+        'opt.mismatchSkip': 'Skip item',
+        'opt.mismatchAbort': 'Abort run',
+        'cfg.nonRare': 'Non-Rare Items',
+        'cfg.currencyPos': 'Orb Positions',
+        'opt.nonRareSkip': 'Skip item',
+        'opt.nonRareUpgrade': 'Upgrade to rare',
         'cfg.batchCrafting': 'Batch Crafting',
         'cfg.workbench': 'Workbench',
         'cfg.pendingArea': 'Pending Area',
@@ -121,15 +139,99 @@ const i18n = {
         'cfg.saveSnapshots': 'Save All Snapshots',
         'cfg.enabled': 'Enabled',
         'cfg.disabled': 'Disabled',
+        'cfg.preprocess': 'OCR Preprocessing',
+        'cfg.colorMasks': 'Text Colour Masks',
+        'cfg.allText': 'All text',
+        'cfg.threshold': 'Threshold',
+        'cfg.resample': 'Resampling',
+        'cfg.deskew': 'Deskew',
+        'cfg.verifyTimeout': 'Roll Verify Timeout (ms)',
+        'cfg.maxUnapplied': 'Max Unapplied Clicks',
+        'cfg.reclickOnNoChange': 'Re-click on No Change',
+        'cfg.mode': 'Session Mode',
+        'cfg.scoreFormula': 'Score Formula',
+        'cfg.scoreThreshold': 'Success at Score',
+        'cfg.scoreHint': 'Optional, replaces the mods above. e.g. life + total_res*1.5 + spirit*2',
+        'cfg.affixConditions': 'Affix Conditions',
+        'cfg.affixHint': 'Comma separated, e.g. open-suffix, prefixes<=2',
+        'cfg.modeTarget': 'Stop at first match',
+        'cfg.modeMaximise': 'Maximise (keep best roll)',
+        'cfg.stopChance': 'Maximise: stop when chance to improve below (%)',
+        'cfg.hotkeys': 'Hotkeys',
+        'hotkey.pause': 'Pause / resume',
+        'hotkey.stop': 'Emergency stop',
+        'hotkey.skip': 'Skip item',
+        'hotkey.mark': 'Mark roll',
+        'toast.rollMarked': 'Marked roll',
         'lang.ui': 'UI',
         'lang.game': 'Game',
+        'engine.label': 'Engine',
+        'auth.title': 'Sign In',
+        'auth.desc': 'This crafter is protected. Enter the token from the server\'s settings file, or scan a pairing QR code on the PC.',
+        'auth.descPassword': 'This crafter is protected. Enter its password, or scan a pairing QR code on the PC.',
+        'auth.secret': 'Password or token',
+        'auth.signIn': 'Sign In',
+        'auth.failed': 'Wrong password or token',
+        'auth.lockedOut': 'Too many failed sign-ins. Try again in 15 minutes.',
+        'pair.button': 'Pair Phone',
+        'pair.title': 'Pair a Phone',
+        'pair.desc': 'Scan the code with the phone\'s camera, or open the link on it. It works once, until {time}.',
+        'toast.pairFailed': 'Failed to create a pairing link',
         'cfg.gameLanguage': 'Game Language',
         'toast.gameLangChanged': 'Game language changed. Re-add target mods if needed.',
         'toast.targetFound': 'Target found: {mod} = {value}!',
         'toast.sessionEnded': 'Crafting session ended',
         'toast.startFailed': 'Failed to start crafting',
+        'toast.resumeFailed': 'Failed to resume the session',
+        'panel.queue': 'Job Queue',
+        'queue.profile': 'Profile',
+        'queue.maxItems': 'Items (0 = profile)',
+        'queue.orbBudget': 'Orb budget (0 = profile)',
+        'queue.startAt': 'Start at (empty = after previous)',
+        'queue.profileName': 'Save current config as profile',
+        'queue.profileNamePlaceholder': 'e.g. Sapphire rings',
+        'queue.limit': 'Limit',
+        'queue.start': 'Start',
+        'queue.status': 'Status',
+        'queue.progress': 'Progress',
+        'queue.afterPrevious': 'After previous',
+        'queue.noLimit': 'Profile',
+        'queue.items': '{n} items',
+        'queue.orbs': '{n} orbs',
+        'queue.progressText': '{items} items · {rolls} rolls',
+        'queue.noProfiles': 'No saved profiles',
+        'queue.paused': 'Queue paused',
+        'job.queued': 'Queued',
+        'job.running': 'Running',
+        'job.done': 'Done',
+        'job.failed': 'Failed',
+        'job.stopped': 'Stopped',
+        'job.cancelled': 'Cancelled',
+        'btn.enqueue': 'Add to Queue',
+        'btn.saveProfile': 'Save Profile',
+        'btn.pauseQueue': 'Pause Queue',
+        'btn.resumeQueue': 'Resume Queue',
+        'btn.moveUp': 'Move up',
+        'btn.moveDown': 'Move down',
+        'btn.cancelJob': 'Cancel',
+        'btn.removeJob': 'Remove',
+        'empty.noJobs': 'No jobs queued',
+        'toast.profileSaved': 'Profile saved',
+        'toast.jobQueued': 'Job added to the queue',
+        'toast.queueFailed': 'Queue request failed',
         'toast.pauseFailed': 'Failed to toggle pause',
         'toast.stopFailed': 'Failed to stop crafting',
+        'attention.title': 'Needs Attention',
+        'attention.retry': 'Retry',
+        'attention.skip': 'Skip Item',
+        'attention.abort': 'Abort',
+        'attention.timeout': 'No answer by {time}: {answer}',
+        'toast.attention': 'The crafter needs your attention',
+        'cfg.attentionTimeout': 'Attention Timeout',
+        'cfg.onAttentionTimeout': 'When Nobody Answers',
+        'opt.retry': 'Retry',
+        'opt.skip': 'Skip item',
+        'opt.abort': 'Abort run',
         'toast.configLoaded': 'Config loaded',
         'toast.freshConfig': 'Starting fresh config',
         'toast.captureFailed': 'Capture failed',
@@ -141,16 +243,20 @@ const i18n = {
         'toast.modParseFailed': 'Failed to parse mod',
         'toast.configSaved': 'Configuration saved!',
         'toast.saveFailed': 'Failed to save config',
+        'toast.itemSkipped': 'Item skipped',
         'toast.captureCorners': 'Capture tooltip corners first',
         'toast.validationFailed': 'Validation failed',
         'toast.configLoadError': 'Error loading config.',
         'toast.noConfig': 'No existing config found.',
         'toast.configLoadSuccess': 'Config loaded! Navigate steps to review/edit.',
         'toast.saveError': 'Save error',
+        'toast.corpusSaved': 'Saved to corpus: {path} — review the expected mods JSON',
+        'toast.corpusFailed': 'Could not save to corpus',
         'ocr.detected': 'OCR detected {n} line(s) of text.',
         'ocr.noText': 'No text detected. Try recapturing the tooltip area.',
         'round.success': 'SUCCESS',
         'round.noMatch': 'No match',
+        'round.skipped': 'Skipped',
         'cells': 'cells',
     },
     'zh-CN': {
@@ -165,11 +271,16 @@ const i18n = {
         'status.item': '物品：',
         'status.roll': '次数：',
         'status.totalRolls': '总次数：',
+        'status.unchanged': '未变化 / 超时：',
+        'status.best': '最佳结果：',
+        'status.score': '评分 / 目标：',
+        'status.affixes': '前缀 / 后缀：',
         'status.speed': '速度：',
         'status.duration': '耗时：',
         'btn.start': '开始',
         'btn.pause': '暂停',
         'btn.resume': '继续',
+        'btn.resumeSession': '继续上次会话',
         'btn.stop': '停止',
         'btn.refresh': '刷新',
         'btn.back': '上一步',
@@ -178,6 +289,7 @@ const i18n = {
         'btn.add': '添加',
         'btn.edit': '编辑',
         'btn.cancel': '取消',
+        'btn.saveToCorpus': '保存到语料库',
         'panel.snapshot': '游戏实时截图',
         'panel.ocrText': '识别的词缀文字',
         'panel.tooltip': '提示框',
@@ -200,7 +312,8 @@ const i18n = {
         'state.starting': '启动中...',
         'state.running': '运行中',
         'state.paused': '已暂停',
-        'state.stopped': '已停止',
+        'state.stopping': '正在停止...',
+        'state.error': '错误',
         'state.startingIn': '{n}秒后开始...',
         'wiz.step1.title': '第1步：配置',
         'wiz.step1.desc': '加载现有配置还是重新开始？',
@@ -232,11 +345,11 @@ const i18n = {
         'wiz.validateOCR': '验证OCR',
         'wiz.step7.title': '第7步：目标词缀',
         'wiz.step7.desc': '选择要搜索的词缀，格式：',
-        'wiz.step7.format': '词缀名 最小值',
+        'wiz.step7.format': '词缀名 最小值（或 <=最大值、==值、最小-最大）',
         'wiz.quickTemplate': '-- 快速模板 --',
         'wiz.minValue': '最小值',
         'wiz.addCustom': '自定义添加',
-        'wiz.customPlaceholder': '如 life 80, fire-res 30',
+        'wiz.customPlaceholder': '如 life 80, life <=40, fire-res 30-35',
         'wiz.step8.title': '第8步：选项与检查',
         'wiz.chaosPerRound': '每轮混沌石数量：',
         'wiz.ocrDebug': '启用OCR调试日志',
@@ -255,6 +368,17 @@ const i18n = {
         'cfg.bpBottomRight': '背包右下角',
         'cfg.item': '物品',
         'cfg.itemSize': '物品尺寸',
+        'cfg.expectedBase': '预期基底',
+        'cfg.expectedClass': '预期类别',
+        'cfg.itemLevel': '物品等级',
+        'cfg.onMismatch': '不匹配时',
+        'cfg.any': '任意',
+        'opt.mismatchSkip': '跳过物品',
+        'opt.mismatchAbort': '中止运行',
+        'cfg.nonRare': '非稀有物品',
+        'cfg.currencyPos': '通货位置',
+        'opt.nonRareSkip': '跳过物品',
+        'opt.nonRareUpgrade': '升级为稀有',
         'cfg.batchCrafting': '批量制作',
         'cfg.workbench': '工作台',
         'cfg.pendingArea': '待处理区域',
@@ -271,15 +395,99 @@ const i18n = {
         'cfg.saveSnapshots': '保存所有快照',
         'cfg.enabled': '已启用',
         'cfg.disabled': '已禁用',
+        'cfg.preprocess': 'OCR预处理',
+        'cfg.colorMasks': '文字颜色遮罩',
+        'cfg.allText': '全部文字',
+        'cfg.threshold': '二值化',
+        'cfg.resample': '缩放滤波',
+        'cfg.deskew': '倾斜校正',
+        'cfg.verifyTimeout': '改造确认超时（毫秒）',
+        'cfg.maxUnapplied': '最大连续未生效点击',
+        'cfg.reclickOnNoChange': '未变化时重新点击',
+        'cfg.mode': '会话模式',
+        'cfg.scoreFormula': '评分公式',
+        'cfg.scoreThreshold': '成功所需评分',
+        'cfg.scoreHint': '可选，设置后取代上方词缀。例如 life + total_res*1.5 + spirit*2',
+        'cfg.affixConditions': '词缀位条件',
+        'cfg.affixHint': '逗号分隔，例如 open-suffix, prefixes<=2',
+        'cfg.modeTarget': '首次命中即停止',
+        'cfg.modeMaximise': '最大化（保留最佳结果）',
+        'cfg.stopChance': '最大化：提升概率低于此值时停止（%）',
+        'cfg.hotkeys': '快捷键',
+        'hotkey.pause': '暂停 / 继续',
+        'hotkey.stop': '紧急停止',
+        'hotkey.skip': '跳过物品',
+        'hotkey.mark': '标记结果',
+        'toast.rollMarked': '已标记结果',
         'lang.ui': '界面',
         'lang.game': '游戏',
+        'engine.label': '引擎',
+        'auth.title': '登录',
+        'auth.desc': '此制作器已受保护。请输入服务器设置文件中的令牌，或在电脑上扫描配对二维码。',
+        'auth.descPassword': '此制作器已受保护。请输入密码，或在电脑上扫描配对二维码。',
+        'auth.secret': '密码或令牌',
+        'auth.signIn': '登录',
+        'auth.failed': '密码或令牌错误',
+        'auth.lockedOut': '登录失败次数过多，请 15 分钟后再试。',
+        'pair.button': '配对手机',
+        'pair.title': '配对手机',
+        'pair.desc': '用手机相机扫描二维码，或在手机上打开链接。仅可使用一次，{time} 前有效。',
+        'toast.pairFailed': '创建配对链接失败',
         'cfg.gameLanguage': '游戏语言',
         'toast.gameLangChanged': '游戏语言已更改，请重新添加目标词缀。',
         'toast.targetFound': '找到目标：{mod} = {value}！',
         'toast.sessionEnded': '制作会话已结束',
         'toast.startFailed': '启动制作失败',
+        'toast.resumeFailed': '继续会话失败',
+        'panel.queue': '任务队列',
+        'queue.profile': '配置方案',
+        'queue.maxItems': '物品数 (0 = 按方案)',
+        'queue.orbBudget': '混沌石预算 (0 = 按方案)',
+        'queue.startAt': '开始时间 (留空 = 上一个任务之后)',
+        'queue.profileName': '将当前配置保存为方案',
+        'queue.profileNamePlaceholder': '例如 蓝宝石戒指',
+        'queue.limit': '限制',
+        'queue.start': '开始',
+        'queue.status': '状态',
+        'queue.progress': '进度',
+        'queue.afterPrevious': '上一个任务之后',
+        'queue.noLimit': '按方案',
+        'queue.items': '{n} 件物品',
+        'queue.orbs': '{n} 个混沌石',
+        'queue.progressText': '{items} 件物品 · {rolls} 次洗练',
+        'queue.noProfiles': '没有已保存的方案',
+        'queue.paused': '队列已暂停',
+        'job.queued': '排队中',
+        'job.running': '运行中',
+        'job.done': '已完成',
+        'job.failed': '失败',
+        'job.stopped': '已停止',
+        'job.cancelled': '已取消',
+        'btn.enqueue': '加入队列',
+        'btn.saveProfile': '保存方案',
+        'btn.pauseQueue': '暂停队列',
+        'btn.resumeQueue': '恢复队列',
+        'btn.moveUp': '上移',
+        'btn.moveDown': '下移',
+        'btn.cancelJob': '取消',
+        'btn.removeJob': '移除',
+        'empty.noJobs': '队列为空',
+        'toast.profileSaved': '方案已保存',
+        'toast.jobQueued': '任务已加入队列',
+        'toast.queueFailed': '队列请求失败',
         'toast.pauseFailed': '切换暂停失败',
         'toast.stopFailed': '停止制作失败',
+        'attention.title': '需要处理',
+        'attention.retry': '重试',
+        'attention.skip': '跳过物品',
+        'attention.abort': '中止',
+        'attention.timeout': '{time} 前无应答：{answer}',
+        'toast.attention': '制作器需要你的处理',
+        'cfg.attentionTimeout': '等待应答时间',
+        'cfg.onAttentionTimeout': '无人应答时',
+        'opt.retry': '重试',
+        'opt.skip': '跳过物品',
+        'opt.abort': '中止运行',
         'toast.configLoaded': '配置已加载',
         'toast.freshConfig': '开始新配置',
         'toast.captureFailed': '捕获失败',
@@ -291,16 +499,20 @@ const i18n = {
         'toast.modParseFailed': '解析词缀失败',
         'toast.configSaved': '配置已保存！',
         'toast.saveFailed': '保存配置失败',
+        'toast.itemSkipped': '已跳过物品',
         'toast.captureCorners': '请先捕获提示框角落',
         'toast.validationFailed': '验证失败',
         'toast.configLoadError': '加载配置出错。',
         'toast.noConfig': '未找到现有配置。',
         'toast.configLoadSuccess': '配置已加载！浏览步骤以检查/编辑。',
         'toast.saveError': '保存出错',
+        'toast.corpusSaved': '已保存到语料库：{path}，请核对预期词缀JSON',
+        'toast.corpusFailed': '保存到语料库失败',
         'ocr.detected': 'OCR检测到 {n} 行文字。',
         'ocr.noText': '未检测到文字，请重新捕获提示框区域。',
         'round.success': '成功',
         'round.noMatch': '未匹配',
+        'round.skipped': '已跳过',
         'cells': '格',
     }
 };
@@ -371,6 +583,11 @@ function setLanguage(lang) {
     }
 }
 
+function gameLanguageName(code) {
+    const opt = document.querySelector(`#game-lang-select option[value="${code || 'en'}"]`);
+    return opt ? opt.textContent : 'English';
+}
+
 function setGameLanguage(lang) {
     gameLang = lang;
     localStorage.setItem('poe2crafter-game-lang', lang);
@@ -383,6 +600,117 @@ function setGameLanguage(lang) {
     showToast(t('toast.gameLangChanged'), 'info');
 }
 
+// ===== Sign-in and Pairing =====
+let signedIn = null;
+
+// checkAuth resolves once the browser may use the API, showing the sign-in form if needed
+async function checkAuth() {
+    let status;
+    try {
+        status = await fetch('/api/auth/status').then(r => r.json());
+    } catch (e) {
+        console.error('Auth status error:', e);
+        return;
+    }
+    document.getElementById('pair-btn').classList.toggle('hidden', !status.pairing);
+    if (status.authenticated) return;
+
+    document.getElementById('auth-desc').textContent = t(status.password ? 'auth.descPassword' : 'auth.desc');
+    document.getElementById('auth-modal').classList.add('active');
+    document.getElementById('auth-secret').focus();
+    await new Promise(resolve => { signedIn = resolve; });
+}
+
+async function signIn() {
+    const input = document.getElementById('auth-secret');
+    const error = document.getElementById('auth-error');
+    error.classList.add('hidden');
+    try {
+        const resp = await fetch('/api/auth/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ secret: input.value }),
+        });
+        if (!resp.ok) throw new Error(resp.status);
+    } catch (e) {
+        error.textContent = t(e.message === '429' ? 'auth.lockedOut' : 'auth.failed');
+        error.classList.remove('hidden');
+        input.select();
+        return;
+    }
+    input.value = '';
+    document.getElementById('auth-modal').classList.remove('active');
+    if (signedIn) signedIn();
+}
+
+async function openPairModal() {
+    try {
+        const resp = await fetch('/api/auth/pair', { method: 'POST' });
+        const data = await resp.json();
+        if (!resp.ok) throw new Error(data.error);
+        document.getElementById('pair-qr').src = data.qr;
+        const link = document.getElementById('pair-link');
+        link.href = data.url;
+        link.textContent = data.url;
+        document.getElementById('pair-desc').textContent =
+            t('pair.desc', { time: new Date(data.expires).toLocaleTimeString() });
+        document.getElementById('pair-modal').classList.add('active');
+    } catch (e) {
+        showToast(t('toast.pairFailed'), 'error');
+    }
+}
+
+function closePairModal() {
+    document.getElementById('pair-modal').classList.remove('active');
+}
+
+// ===== Engines =====
+let currentEngine = localStorage.getItem('poe2crafter-engine') || '';
+
+// engineURL scopes an API path to the engine picked in the header
+function engineURL(path) {
+    if (!currentEngine) return path;
+    return path + (path.includes('?') ? '&' : '?') + 'engine=' + encodeURIComponent(currentEngine);
+}
+
+async function loadEngines() {
+    try {
+        const engines = await fetch('/api/engines').then(r => r.json());
+        const select = document.getElementById('engine-select');
+        select.innerHTML = '';
+        for (const engine of engines) {
+            const opt = document.createElement('option');
+            opt.value = engine.name;
+            opt.textContent = `${engine.name} (${engine.backend})`;
+            select.appendChild(opt);
+        }
+        if (!engines.some(engine => engine.name === currentEngine)) {
+            currentEngine = engines.length > 0 ? engines[0].name : '';
+        }
+        select.value = currentEngine;
+        document.getElementById('engine-group').classList.toggle('hidden', engines.length < 2);
+    } catch (e) {
+        console.error('Engine load error:', e);
+    }
+}
+
+// Switches the dashboard to another engine and reconnects its WebSocket
+function setEngine(name) {
+    currentEngine = name;
+    localStorage.setItem('poe2crafter-engine', name);
+    resetDashboard();
+    hideAttention();
+    if (wsReconnectTimer) {
+        clearTimeout(wsReconnectTimer);
+        wsReconnectTimer = null;
+    }
+    if (ws) {
+        ws.onclose = null;
+        ws.close();
+    }
+    connectWebSocket();
+}
+
 // ===== WebSocket Connection =====
 let ws = null;
 let wsReconnectTimer = null;
@@ -391,13 +719,16 @@ let durationTimer = null;
 
 function connectWebSocket() {
     const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
-    const url = `${protocol}//${location.host}/ws`;
+    const url = `${protocol}//${location.host}${engineURL('/ws')}`;
 
     ws = new WebSocket(url);
 
     ws.onopen = () => {
         document.getElementById('ws-status').textContent = t('connected');
         document.getElementById('ws-status').className = 'ws-connected';
+        loadPendingAttention();
+        loadCheckpoint();
+        loadQueue();
         if (wsReconnectTimer) {
             clearTimeout(wsReconnectTimer);
             wsReconnectTimer = null;
@@ -427,11 +758,14 @@ function connectWebSocket() {
 function handleWSMessage(msg) {
     switch (msg.type) {
         case 'state_change':
-            updateCraftState(msg.data.state);
+            updateCraftState(msg.data.state, msg.data.error);
             break;
         case 'roll_attempted':
             updateRollInfo(msg.data);
             break;
+        case 'roll_verified':
+            updateRollVerified(msg.data);
+            break;
         case 'tooltip_captured':
             refreshTooltipImage();
             refreshSnapshot();
@@ -442,9 +776,30 @@ function handleWSMessage(msg) {
         case 'target_found':
             handleTargetFound(msg.data);
             break;
+        case 'best_roll':
+            updateBestRoll(msg.data);
+            break;
+        case 'roll_scored':
+            updateRollScored(msg.data);
+            break;
         case 'item_started':
             updateItemStarted(msg.data);
             break;
+        case 'needs_attention':
+            showAttention(msg.data);
+            break;
+        case 'attention_resolved':
+            hideAttention(msg.data.id);
+            break;
+        case 'roll_marked':
+            showToast(`${t('toast.rollMarked')} #${msg.data.rollNumber}`, 'info');
+            break;
+        case 'item_inspected':
+            updateItemInspected(msg.data);
+            break;
+        case 'item_skipped':
+            handleItemSkipped(msg.data);
+            break;
         case 'item_completed':
             updateItemCompleted(msg.data);
             break;
@@ -457,6 +812,9 @@ function handleWSMessage(msg) {
         case 'craft_countdown':
             updateCraftCountdown(msg.data);
             break;
+        case 'queue_progress':
+            renderQueue(msg.data);
+            break;
         case 'capture_countdown':
             updateCaptureCountdown(msg.data);
             break;
@@ -467,20 +825,29 @@ function handleWSMessage(msg) {
 }
 
 // ===== State Management =====
-function updateCraftState(state) {
+function updateCraftState(state, error) {
     const el = document.getElementById('craft-state');
     el.className = 'value state-' + state;
 
     const btnStart = document.getElementById('btn-start');
     const btnStop = document.getElementById('btn-stop');
+    document.getElementById('btn-resume-session').disabled = state !== 'idle' && state !== 'error';
 
     switch (state) {
         case 'idle':
-        case 'stopped':
+        case 'error':
             el.textContent = t('state.' + state);
+            el.title = error || '';
             btnStart.disabled = false;
             btnStop.disabled = true;
+            loadCheckpoint();
             if (durationTimer) { clearInterval(durationTimer); durationTimer = null; }
+            if (state === 'error' && error) showToast(error, 'error');
+            break;
+        case 'stopping':
+            el.textContent = t('state.stopping');
+            btnStart.disabled = true;
+            btnStop.disabled = true;
             break;
         case 'countdown':
             btnStart.disabled = true;
@@ -526,10 +893,33 @@ function updateRollInfo(data) {
     document.getElementById('craft-speed').textContent = `${data.rollsPerMin.toFixed(1)}/min`;
 }
 
+function updateRollVerified(data) {
+    document.getElementById('craft-unchanged').textContent = `${data.unchangedRolls} / ${data.timedOutRolls}`;
+}
+
+function updateRollScored(data) {
+    document.getElementById('craft-score').textContent = `${data.score.toFixed(1)} / ${data.threshold}`;
+}
+
+function updateBestRoll(data) {
+    document.getElementById('craft-best').textContent = `${data.modName} = ${data.score} (#${data.attemptNum})`;
+}
+
 function updateItemStarted(data) {
     document.getElementById('craft-item').textContent = `#${data.itemNumber}`;
 }
 
+function updateItemInspected(data) {
+    const item = data.item || {};
+    const el = document.getElementById('craft-item');
+    el.textContent = `#${data.itemNumber}: ${item.base || item.name || '?'} (ilvl ${item.itemLevel || '?'})`;
+    el.title = [item.name, item.base, item.class, item.rarity, ...(item.flags || [])].filter(Boolean).join('\n');
+}
+
+function handleItemSkipped(data) {
+    showToast(`${t('toast.itemSkipped')} #${data.itemNumber}: ${data.reason}`, 'warning');
+}
+
 function updateItemCompleted(data) {
     const history = document.getElementById('round-history');
     if (history.querySelector('.empty-msg')) {
@@ -537,8 +927,14 @@ function updateItemCompleted(data) {
     }
 
     const badge = document.createElement('span');
-    badge.className = data.success ? 'round-badge round-success' : 'round-badge round-fail';
-    badge.textContent = `#${data.itemNumber}: ${data.success ? t('round.success') : t('round.noMatch')}`;
+    if (data.error && !data.success) {
+        badge.className = 'round-badge round-skipped';
+        badge.textContent = `#${data.itemNumber}: ${t('round.skipped')}`;
+        badge.title = data.error;
+    } else {
+        badge.className = data.success ? 'round-badge round-success' : 'round-badge round-fail';
+        badge.textContent = `#${data.itemNumber}: ${data.success ? t('round.success') : t('round.noMatch')}`;
+    }
     history.appendChild(badge);
     history.scrollTop = history.scrollHeight;
 }
@@ -553,6 +949,13 @@ function updateModsTracked(data) {
         updateModStatsTable(data.modStats, data.totalRolls);
     }
 
+    const prefixes = data.prefixes || [];
+    const suffixes = data.suffixes || [];
+    const unknown = data.unknown || [];
+    const affixEl = document.getElementById('craft-affixes');
+    affixEl.textContent = `${prefixes.length}/3 · ${suffixes.length}/3` + (unknown.length ? ` · ${unknown.length}?` : '');
+    affixEl.title = [...prefixes.map(n => 'P: ' + n), ...suffixes.map(n => 'S: ' + n), ...unknown.map(n => '?: ' + n)].join('\n');
+
     refreshTooltipImage();
 }
 
@@ -565,7 +968,8 @@ function updateModStatsTable(modStats, totalRolls) {
         min: stat.MinValue,
         max: stat.MaxValue,
         avg: stat.AvgValue,
-        prob: totalRolls > 0 ? (stat.Count / totalRolls * 100) : 0
+        prob: totalRolls > 0 ? (stat.Count / totalRolls * 100) : 0,
+        values: Object.entries(stat.Values || {}).sort(([x], [y]) => x.localeCompare(y))
     }));
 
     entries.sort((a, b) => b.count - a.count);
@@ -584,6 +988,15 @@ function updateModStatsTable(modStats, totalRolls) {
             <td>${e.avg.toFixed(1)}</td>
             <td>${e.prob.toFixed(1)}%</td>
         </tr>
+        ${e.values.map(([name, v]) => `
+        <tr class="value-row">
+            <td>└ ${name}</td>
+            <td>${v.Count}</td>
+            <td>${v.Min}</td>
+            <td>${v.Max}</td>
+            <td>${v.Avg.toFixed(1)}</td>
+            <td></td>
+        </tr>`).join('')}
     `).join('');
 }
 
@@ -602,15 +1015,10 @@ function handleSessionEnded(data) {
 async function startCrafting() {
     try {
         craftStartTime = Date.now();
-        document.getElementById('craft-total').textContent = '0';
-        document.getElementById('craft-roll').textContent = '0/0';
-        document.getElementById('craft-speed').textContent = '0/min';
-        document.getElementById('craft-item').textContent = '#0';
-        document.getElementById('round-history').innerHTML = `<span class="empty-msg">${t('empty.noRounds')}</span>`;
-        document.getElementById('mod-stats-body').innerHTML = `<tr><td colspan="6" class="empty-msg">${t('empty.noData')}</td></tr>`;
+        resetDashboard();
         document.getElementById('ocr-text').textContent = t('state.starting');
 
-        const resp = await fetch('/api/craft/start', { method: 'POST' });
+        const resp = await fetch(engineURL('/api/craft/start'), { method: 'POST' });
         const data = await resp.json();
         if (data.error) {
             showToast(data.error, 'error');
@@ -620,6 +1028,45 @@ async function startCrafting() {
     }
 }
 
+// Clears the live session fields, before a new session or when switching engines
+function resetDashboard() {
+    document.getElementById('craft-total').textContent = '0';
+    document.getElementById('craft-unchanged').textContent = '0 / 0';
+    document.getElementById('craft-best').textContent = '-';
+    document.getElementById('craft-score').textContent = '-';
+    document.getElementById('craft-affixes').textContent = '-';
+    document.getElementById('craft-roll').textContent = '0/0';
+    document.getElementById('craft-speed').textContent = '0/min';
+    document.getElementById('craft-item').textContent = '#0';
+    document.getElementById('round-history').innerHTML = `<span class="empty-msg">${t('empty.noRounds')}</span>`;
+    document.getElementById('mod-stats-body').innerHTML = `<tr><td colspan="6" class="empty-msg">${t('empty.noData')}</td></tr>`;
+}
+
+// Shows the Resume Session button while an interrupted session is saved
+async function loadCheckpoint() {
+    try {
+        const resp = await fetch(engineURL('/api/craft/resume'));
+        const data = await resp.json();
+        const btn = document.getElementById('btn-resume-session');
+        btn.classList.toggle('hidden', !data.available);
+        btn.title = data.summary || '';
+    } catch (e) {
+        console.error('Checkpoint load error:', e);
+    }
+}
+
+async function resumeSession() {
+    try {
+        const resp = await fetch(engineURL('/api/craft/resume'), { method: 'POST' });
+        const data = await resp.json();
+        if (data.error) {
+            showToast(data.error, 'error');
+        }
+    } catch (e) {
+        showToast(t('toast.resumeFailed'), 'error');
+    }
+}
+
 async function pauseCrafting() {
     // Pause removed — no-op kept for safety
     return;
@@ -627,7 +1074,7 @@ async function pauseCrafting() {
 
 async function stopCrafting() {
     try {
-        const resp = await fetch('/api/craft/stop', { method: 'POST' });
+        const resp = await fetch(engineURL('/api/craft/stop'), { method: 'POST' });
         const data = await resp.json();
         if (data.error) {
             showToast(data.error, 'error');
@@ -637,15 +1084,248 @@ async function stopCrafting() {
     }
 }
 
+// ===== Job Queue =====
+let queueJobs = [];
+let queuePaused = false;
+
+async function loadQueue() {
+    try {
+        const [queue, profiles] = await Promise.all([
+            fetch(engineURL('/api/queue')).then(r => r.json()),
+            fetch('/api/profiles').then(r => r.json()),
+        ]);
+        renderQueue(queue);
+        renderProfileOptions(profiles);
+    } catch (e) {
+        console.error('Queue load error:', e);
+    }
+}
+
+function renderProfileOptions(profiles) {
+    const select = document.getElementById('queue-profile');
+    const current = select.value;
+    select.innerHTML = '';
+    if (!profiles || profiles.length === 0) {
+        select.innerHTML = `<option value="">${t('queue.noProfiles')}</option>`;
+        return;
+    }
+    for (const name of profiles) {
+        const opt = document.createElement('option');
+        opt.value = name;
+        opt.textContent = name;
+        select.appendChild(opt);
+    }
+    if (profiles.includes(current)) {
+        select.value = current;
+    }
+}
+
+function renderQueue(data) {
+    queueJobs = data.jobs || [];
+    queuePaused = !!data.paused;
+
+    const pauseBtn = document.getElementById('btn-queue-pause');
+    pauseBtn.textContent = queuePaused ? t('btn.resumeQueue') : t('btn.pauseQueue');
+    pauseBtn.setAttribute('data-i18n', queuePaused ? 'btn.resumeQueue' : 'btn.pauseQueue');
+
+    const tbody = document.getElementById('queue-body');
+    if (queueJobs.length === 0) {
+        tbody.innerHTML = `<tr><td colspan="7" class="empty-msg">${t('empty.noJobs')}</td></tr>`;
+        return;
+    }
+
+    tbody.innerHTML = '';
+    queueJobs.forEach((job, i) => {
+        const limits = [];
+        if (job.maxItems) limits.push(t('queue.items', { n: job.maxItems }));
+        if (job.orbBudget) limits.push(t('queue.orbs', { n: job.orbBudget }));
+
+        const row = document.createElement('tr');
+        const cells = [
+            job.id,
+            job.profile,
+            limits.join(', ') || t('queue.noLimit'),
+            job.startAt ? new Date(job.startAt).toLocaleString() : t('queue.afterPrevious'),
+            t('job.' + job.status),
+            job.startedAt ? t('queue.progressText', { items: job.items, rolls: job.rolls }) : '-',
+        ];
+        for (const text of cells) {
+            const td = document.createElement('td');
+            td.textContent = text;
+            row.appendChild(td);
+        }
+        row.cells[4].className = 'job-' + job.status;
+        row.cells[4].title = job.error || '';
+
+        const actions = document.createElement('td');
+        actions.className = 'queue-actions';
+        if (job.status === 'queued') {
+            actions.appendChild(queueButton('▲', t('btn.moveUp'), () => moveJob(i, -1), i === 0));
+            actions.appendChild(queueButton('▼', t('btn.moveDown'), () => moveJob(i, 1), i === queueJobs.length - 1));
+        }
+        const active = job.status === 'queued' || job.status === 'running';
+        actions.appendChild(queueButton('✕', active ? t('btn.cancelJob') : t('btn.removeJob'), () => cancelJob(job.id), false));
+        row.appendChild(actions);
+        tbody.appendChild(row);
+    });
+}
+
+function queueButton(label, title, onClick, disabled) {
+    const btn = document.createElement('button');
+    btn.className = 'btn btn-small';
+    btn.textContent = label;
+    btn.title = title;
+    btn.disabled = disabled;
+    btn.onclick = onClick;
+    return btn;
+}
+
+async function queueRequest(url, body) {
+    try {
+        const resp = await fetch(engineURL(url), {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body),
+        });
+        const data = await resp.json();
+        if (data.error) {
+            showToast(data.error, 'error');
+            return null;
+        }
+        return data;
+    } catch (e) {
+        showToast(t('toast.queueFailed'), 'error');
+        return null;
+    }
+}
+
+async function enqueueJob() {
+    const profile = document.getElementById('queue-profile').value;
+    const startAt = document.getElementById('queue-start-at').value;
+    const job = {
+        profile,
+        maxItems: parseInt(document.getElementById('queue-max-items').value) || 0,
+        orbBudget: parseInt(document.getElementById('queue-orb-budget').value) || 0,
+    };
+    if (startAt) {
+        job.startAt = new Date(startAt).toISOString();
+    }
+    if (await queueRequest('/api/queue', job)) {
+        showToast(t('toast.jobQueued'), 'success');
+    }
+}
+
+async function saveProfile() {
+    const input = document.getElementById('queue-profile-name');
+    const name = input.value.trim();
+    if (await queueRequest('/api/profiles', { name })) {
+        showToast(t('toast.profileSaved'), 'success');
+        input.value = '';
+        const profiles = await fetch('/api/profiles').then(r => r.json());
+        renderProfileOptions(profiles);
+        document.getElementById('queue-profile').value = name;
+    }
+}
+
+function moveJob(index, delta) {
+    const ids = queueJobs.map(job => job.id);
+    const target = index + delta;
+    [ids[index], ids[target]] = [ids[target], ids[index]];
+    queueRequest('/api/queue/reorder', { ids });
+}
+
+function cancelJob(id) {
+    queueRequest('/api/queue/cancel', { id });
+}
+
+async function toggleQueuePause() {
+    const pausing = !queuePaused;
+    if (await queueRequest('/api/queue/pause', { paused: pausing }) && pausing) {
+        showToast(t('queue.paused'), 'info');
+    }
+}
+
+// ===== Needs Attention =====
+let attentionId = 0;
+
+function showAttention(req) {
+    attentionId = req.id;
+    document.getElementById('attention-reason').textContent = req.reason;
+    const img = document.getElementById('attention-snapshot');
+    if (req.snapshot) {
+        img.src = engineURL('/api/snapshot/attention?t=' + Date.now());
+        img.classList.remove('hidden');
+    } else {
+        img.classList.add('hidden');
+    }
+    const time = new Date(req.deadline).toLocaleTimeString();
+    document.getElementById('attention-timeout').textContent =
+        t('attention.timeout', { time, answer: t('opt.' + req.default) });
+    document.getElementById('attention-panel').classList.remove('hidden');
+    showToast(t('toast.attention'), 'warning');
+}
+
+function hideAttention(id) {
+    if (id && id !== attentionId) return;
+    attentionId = 0;
+    document.getElementById('attention-panel').classList.add('hidden');
+}
+
+async function loadPendingAttention() {
+    try {
+        const resp = await fetch(engineURL('/api/craft/attention'));
+        const data = await resp.json();
+        if (data.pending) showAttention(data.pending);
+        else hideAttention();
+    } catch (e) {
+        // Not critical, the next needs_attention event shows the panel
+    }
+}
+
+async function answerAttention(answer) {
+    try {
+        const resp = await fetch(engineURL('/api/craft/attention'), {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ id: attentionId, answer })
+        });
+        const data = await resp.json();
+        if (data.error) {
+            showToast(data.error, 'error');
+            loadPendingAttention();
+        }
+    } catch (e) {
+        showToast(e.message, 'error');
+    }
+}
+
 // ===== Snapshot Refresh =====
 function refreshSnapshot() {
     const img = document.getElementById('live-snapshot');
-    img.src = `/api/snapshot/screen?t=${Date.now()}`;
+    img.src = engineURL(`/api/snapshot/screen?t=${Date.now()}`);
 }
 
 function refreshTooltipImage() {
     const img = document.getElementById('tooltip-img');
-    img.src = `/api/snapshot/current-tooltip?t=${Date.now()}`;
+    img.src = engineURL(`/api/snapshot/current-tooltip?t=${Date.now()}`);
+}
+
+async function promoteTooltip() {
+    try {
+        const resp = await fetch(engineURL('/api/corpus/promote'), {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ gameLanguage: gameLang })
+        });
+        const data = await resp.json();
+        if (!resp.ok) {
+            showToast(data.error || t('toast.corpusFailed'), 'error');
+            return;
+        }
+        showToast(t('toast.corpusSaved', { path: data.path }), 'success');
+    } catch (e) {
+        showToast(t('toast.corpusFailed'), 'error');
+    }
 }
 
 // ===== Tab Navigation =====
@@ -728,6 +1408,13 @@ function formatConfigHTML(cfg) {
 
     let itemContent = '';
     itemContent += row(t('cfg.itemSize'), `${cfg.ItemWidth || 1} x ${cfg.ItemHeight || 1} ${t('cells')}`);
+    itemContent += row(t('cfg.expectedBase'), cfg.ExpectedBase || t('cfg.any'));
+    itemContent += row(t('cfg.expectedClass'), cfg.ExpectedClass || t('cfg.any'));
+    itemContent += row(t('cfg.itemLevel'), `${cfg.MinItemLevel || t('cfg.any')} - ${cfg.MaxItemLevel || t('cfg.any')}`);
+    itemContent += row(t('cfg.onMismatch'), cfg.OnItemMismatch === 'abort' ? t('opt.mismatchAbort') : t('opt.mismatchSkip'));
+    itemContent += row(t('cfg.nonRare'), cfg.NonRareAction === 'upgrade'
+        ? `${t('opt.nonRareUpgrade')} (${upgradeCurrencies(cfg).join(', ')})`
+        : t('opt.nonRareSkip'));
 
     let batchContent = '';
     const wbPos = cfg.WorkbenchTopLeft;
@@ -751,12 +1438,33 @@ function formatConfigHTML(cfg) {
     } else {
         modsContent += `<span class="empty-msg">${t('empty.noTargetMods')}</span>`;
     }
+    if (cfg.ScoreFormula) {
+        modsContent += row(t('cfg.scoreFormula'), `<code>${cfg.ScoreFormula}</code>`);
+        modsContent += row(t('cfg.scoreThreshold'), cfg.ScoreThreshold || 0);
+    }
+    if (cfg.AffixConditions && cfg.AffixConditions.length > 0) {
+        modsContent += row(t('cfg.affixConditions'), cfg.AffixConditions.join(', '));
+    }
 
     let optionsContent = '';
     optionsContent += row(t('cfg.chaosPerRound'), cfg.ChaosPerRound || 10);
-    optionsContent += row(t('cfg.gameLanguage'), cfg.GameLanguage === 'zh-CN' ? '简体中文' : 'English');
+    optionsContent += row(t('cfg.gameLanguage'), gameLanguageName(cfg.GameLanguage));
     optionsContent += row(t('cfg.ocrDebug'), cfg.Debug ? t('cfg.enabled') : t('cfg.disabled'));
     optionsContent += row(t('cfg.saveSnapshots'), cfg.SaveAllSnapshots ? t('cfg.enabled') : t('cfg.disabled'));
+    optionsContent += row(t('cfg.verifyTimeout'), `${cfg.VerifyTimeoutMs || 1500} ms`);
+    optionsContent += row(t('cfg.maxUnapplied'), cfg.MaxUnappliedClicks || 5);
+    optionsContent += row(t('cfg.reclickOnNoChange'), cfg.ReclickOnNoChange ? t('cfg.enabled') : t('cfg.disabled'));
+    optionsContent += row(t('cfg.mode'), cfg.Mode === 'maximise'
+        ? `${t('cfg.modeMaximise')}, ${Math.round((cfg.MaximiseStopChance || 0.2) * 100)}%`
+        : t('cfg.modeTarget'));
+    optionsContent += row(t('cfg.attentionTimeout'),
+        `${cfg.AttentionTimeoutSec || 300} s → ${t('opt.' + (cfg.OnAttentionTimeout || 'abort'))}`);
+    optionsContent += row(t('cfg.hotkeys'), HOTKEY_ACTIONS.map(a => `${t('hotkey.' + a)}: ${hotkeyFor(cfg, a)}`).join(', '));
+    const pre = cfg.Preprocess || {};
+    optionsContent += row(t('cfg.preprocess'),
+        `${(pre.ColorMasks && pre.ColorMasks.length) ? pre.ColorMasks.join('+') : t('cfg.allText')}, ` +
+        `${pre.Threshold || 'otsu'}, ${pre.Resample || 'catmullrom'} x${pre.Scale || 3}` +
+        `${pre.Deskew ? ', ' + t('cfg.deskew') : ''}`);
 
     return [
         section('positions', t('cfg.positions'), posContent),
@@ -806,15 +1514,14 @@ let sectionCfg = null;
 async function initWizardModTemplates() {
     if (modTemplates.length > 0) return;
     try {
-        const resp = await fetch('/api/mod-templates');
+        const resp = await fetch(`/api/mod-templates?lang=${encodeURIComponent(gameLang)}`);
         modTemplates = await resp.json();
         const select = document.getElementById('wiz-mod-template');
         select.innerHTML = `<option value="">${t('wiz.quickTemplate')}</option>`;
         modTemplates.forEach(tmpl => {
             const opt = document.createElement('option');
             opt.value = tmpl.key;
-            const name = (gameLang === 'zh-CN' && tmpl.name_zh) ? tmpl.name_zh : tmpl.name;
-            opt.textContent = `${name} (${tmpl.example})`;
+            opt.textContent = `${tmpl.name} (${tmpl.example})`;
             select.appendChild(opt);
         });
     } catch (e) {
@@ -991,7 +1698,7 @@ function gatherWizardStepData(step) {
 async function wizardCapture(field) {
     captureContext = 'wizard';
     try {
-        const resp = await fetch('/api/wizard/capture', {
+        const resp = await fetch(engineURL('/api/wizard/capture'), {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ field: field })
@@ -1054,6 +1761,13 @@ function handleCaptureResult(data) {
                 if (el) el.textContent = `(${data.x}, ${data.y})`;
             }
         };
+        if (data.field.startsWith('sec-currency-')) {
+            sectionFieldMap[data.field] = () => {
+                if (sectionCfg) sectionCfg.CurrencyPos = { ...(sectionCfg.CurrencyPos || {}), [data.field.slice(13)]: { X: data.x, Y: data.y } };
+                const el = document.getElementById(data.field);
+                if (el) el.textContent = `(${data.x}, ${data.y})`;
+            };
+        }
         if (sectionFieldMap[data.field]) {
             sectionFieldMap[data.field]();
             showToast(`${t('btn.capture')}: (${data.x}, ${data.y})`, 'success');
@@ -1103,7 +1817,7 @@ async function wizardValidateTooltip() {
 
     try {
         document.getElementById('btn-validate-tooltip').disabled = true;
-        const resp = await fetch('/api/wizard/validate-tooltip', {
+        const resp = await fetch(engineURL('/api/wizard/validate-tooltip'), {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ x1: tl.X, y1: tl.Y, x2: br.X, y2: br.Y, gameLanguage: gameLang })
@@ -1133,19 +1847,20 @@ async function wizardValidateTooltip() {
 function wizardAddModFromTemplate() {
     const select = document.getElementById('wiz-mod-template');
     const valueInput = document.getElementById('wiz-mod-value');
+    const op = document.getElementById('wiz-mod-op').value;
     const key = select.value;
-    const value = parseInt(valueInput.value);
+    const value = parseFloat(valueInput.value);
 
     if (!key) {
         showToast(t('toast.selectMod'), 'error');
         return;
     }
-    if (!value || value < 1) {
+    if (!value || value <= 0) {
         showToast(t('toast.enterMin'), 'error');
         return;
     }
 
-    const input = `${key} ${value}`;
+    const input = `${key} ${op}${value}`;
     addModToWizard(input);
     select.value = '';
     valueInput.value = '';
@@ -1303,7 +2018,8 @@ async function wizardSave() {
         if (resp.ok) {
             showToast(t('toast.configSaved'), 'success');
         } else {
-            showToast(t('toast.saveFailed'), 'error');
+            const err = await saveResp.json().catch(() => ({}));
+            showToast(t('toast.saveFailed') + (err.error ? ': ' + err.error : ''), 'error');
         }
     } catch (e) {
         showToast(t('toast.saveError') + ': ' + e.message, 'error');
@@ -1378,6 +2094,13 @@ async function saveSection(name) {
             case 'item':
                 merged.ItemWidth = sectionCfg.ItemWidth;
                 merged.ItemHeight = sectionCfg.ItemHeight;
+                merged.ExpectedBase = sectionCfg.ExpectedBase;
+                merged.ExpectedClass = sectionCfg.ExpectedClass;
+                merged.MinItemLevel = sectionCfg.MinItemLevel;
+                merged.MaxItemLevel = sectionCfg.MaxItemLevel;
+                merged.OnItemMismatch = sectionCfg.OnItemMismatch;
+                merged.NonRareAction = sectionCfg.NonRareAction;
+                merged.CurrencyPos = sectionCfg.CurrencyPos;
                 break;
             case 'batch':
                 merged.WorkbenchTopLeft = sectionCfg.WorkbenchTopLeft;
@@ -1404,11 +2127,23 @@ async function saveSection(name) {
                 break;
             case 'mods':
                 merged.TargetMods = sectionCfg.TargetMods;
+                merged.ScoreFormula = sectionCfg.ScoreFormula;
+                merged.ScoreThreshold = sectionCfg.ScoreThreshold;
+                merged.AffixConditions = sectionCfg.AffixConditions;
                 break;
             case 'options':
                 merged.ChaosPerRound = sectionCfg.ChaosPerRound;
                 merged.Debug = sectionCfg.Debug;
                 merged.SaveAllSnapshots = sectionCfg.SaveAllSnapshots;
+                merged.VerifyTimeoutMs = sectionCfg.VerifyTimeoutMs;
+                merged.MaxUnappliedClicks = sectionCfg.MaxUnappliedClicks;
+                merged.ReclickOnNoChange = sectionCfg.ReclickOnNoChange;
+                merged.Mode = sectionCfg.Mode;
+                merged.MaximiseStopChance = sectionCfg.MaximiseStopChance;
+                merged.Preprocess = sectionCfg.Preprocess;
+                merged.Hotkeys = sectionCfg.Hotkeys;
+                merged.AttentionTimeoutSec = sectionCfg.AttentionTimeoutSec;
+                merged.OnAttentionTimeout = sectionCfg.OnAttentionTimeout;
                 break;
         }
 
@@ -1435,6 +2170,12 @@ function applySectionEditorData(name) {
         case 'item': {
             sectionCfg.ItemWidth = parseInt(document.getElementById('sec-item-width').value) || 1;
             sectionCfg.ItemHeight = parseInt(document.getElementById('sec-item-height').value) || 1;
+            sectionCfg.ExpectedBase = document.getElementById('sec-expected-base').value.trim();
+            sectionCfg.ExpectedClass = document.getElementById('sec-expected-class').value.trim();
+            sectionCfg.MinItemLevel = parseInt(document.getElementById('sec-min-ilvl').value) || 0;
+            sectionCfg.MaxItemLevel = parseInt(document.getElementById('sec-max-ilvl').value) || 0;
+            sectionCfg.OnItemMismatch = document.getElementById('sec-on-mismatch').value;
+            sectionCfg.NonRareAction = document.getElementById('sec-non-rare').value;
             break;
         }
         case 'batch': {
@@ -1463,10 +2204,36 @@ function applySectionEditorData(name) {
             sectionCfg.ResultAreaHeight = parseInt(document.getElementById('sec-res-h').value) || 5;
             break;
         }
+        case 'mods': {
+            sectionCfg.ScoreFormula = document.getElementById('sec-score-formula').value.trim();
+            sectionCfg.ScoreThreshold = parseFloat(document.getElementById('sec-score-threshold').value) || 0;
+            sectionCfg.AffixConditions = document.getElementById('sec-affix-conditions').value
+                .split(',').map(s => s.trim()).filter(s => s);
+            break;
+        }
         case 'options': {
             sectionCfg.ChaosPerRound = parseInt(document.getElementById('sec-chaos-per-round').value) || 10;
             sectionCfg.Debug = document.getElementById('sec-debug').checked;
             sectionCfg.SaveAllSnapshots = document.getElementById('sec-snapshots').checked;
+            sectionCfg.VerifyTimeoutMs = parseInt(document.getElementById('sec-verify-timeout').value) || 1500;
+            sectionCfg.MaxUnappliedClicks = parseInt(document.getElementById('sec-max-unapplied').value) || 5;
+            sectionCfg.ReclickOnNoChange = document.getElementById('sec-reclick').checked;
+            sectionCfg.Mode = document.getElementById('sec-mode').value;
+            sectionCfg.MaximiseStopChance = (parseFloat(document.getElementById('sec-stop-chance').value) || 20) / 100;
+            sectionCfg.AttentionTimeoutSec = parseInt(document.getElementById('sec-attention-timeout').value) || 300;
+            sectionCfg.OnAttentionTimeout = document.getElementById('sec-attention-answer').value;
+            sectionCfg.Hotkeys = {};
+            for (const a of HOTKEY_ACTIONS) {
+                const key = document.getElementById('sec-hotkey-' + a).value.trim();
+                if (key) sectionCfg.Hotkeys[a] = key;
+            }
+            sectionCfg.Preprocess = {
+                ...(sectionCfg.Preprocess || {}),
+                ColorMasks: Array.from(document.querySelectorAll('.sec-color-mask:checked')).map(el => el.value),
+                Threshold: document.getElementById('sec-threshold').value,
+                Resample: document.getElementById('sec-resample').value,
+                Deskew: document.getElementById('sec-deskew').checked,
+            };
             break;
         }
         // positions and tooltip are updated live via captures; mods via secAddMod
@@ -1485,6 +2252,20 @@ function buildSectionEditor(name, cfg) {
     }
 }
 
+// Hotkey actions and their default bindings (see internal/hotkey)
+const HOTKEY_ACTIONS = ['pause', 'stop', 'skip', 'mark'];
+const DEFAULT_HOTKEYS = { pause: 'F12', stop: 'Ctrl+F12', skip: 'F11', mark: 'F10' };
+
+function hotkeyFor(cfg, action) {
+    return cfg.Hotkeys?.[action] || DEFAULT_HOTKEYS[action];
+}
+
+// upgradeCurrencies lists the orbs used by the upgrade paths (defaults: alchemy for normal, regal for magic)
+function upgradeCurrencies(cfg) {
+    const paths = { normal: ['alchemy'], magic: ['regal'], ...(cfg.UpgradePaths || {}) };
+    return [...new Set(Object.values(paths).flat())];
+}
+
 function buildPositionEditor(cfg) {
     const gridTl = cfg.BackpackTopLeft?.X ? `(${cfg.BackpackTopLeft.X}, ${cfg.BackpackTopLeft.Y})` : t('wiz.notSet');
     const gridBr = cfg.BackpackBottomRight?.X ? `(${cfg.BackpackBottomRight.X}, ${cfg.BackpackBottomRight.Y})` : t('wiz.notSet');
@@ -1516,6 +2297,15 @@ function buildPositionEditor(cfg) {
 
 function buildItemEditor(cfg) {
     const w = cfg.ItemWidth || 1, h = cfg.ItemHeight || 1;
+    const currencyRows = upgradeCurrencies(cfg).map(id => {
+        const pos = cfg.CurrencyPos?.[id];
+        return `
+            <div class="capture-item">
+                <label>${id}:</label>
+                <span id="sec-currency-${id}" class="capture-value">${pos?.X ? `(${pos.X}, ${pos.Y})` : t('wiz.notSet')}</span>
+                <button class="btn btn-small" onclick="sectionCapture('sec-currency-${id}')">${t('btn.capture')}</button>
+            </div>`;
+    }).join('');
     const wOpts = [1,2,3,4].map(n => `<option value="${n}"${n===w?' selected':''}>${n}</option>`).join('');
     const hOpts = [1,2,3,4,5].map(n => `<option value="${n}"${n===h?' selected':''}>${n}</option>`).join('');
     return `
@@ -1527,6 +2317,37 @@ function buildItemEditor(cfg) {
             <label>${t('wiz.height')}</label>
             <select id="sec-item-height">${hOpts}</select>
         </div>
+        <div class="form-group">
+            <label>${t('cfg.expectedBase')}</label>
+            <input type="text" id="sec-expected-base" value="${cfg.ExpectedBase || ''}" placeholder="Sapphire Ring">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.expectedClass')}</label>
+            <input type="text" id="sec-expected-class" value="${cfg.ExpectedClass || ''}" placeholder="ring">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.itemLevel')}</label>
+            <input type="number" id="sec-min-ilvl" min="0" value="${cfg.MinItemLevel || 0}">
+            <input type="number" id="sec-max-ilvl" min="0" value="${cfg.MaxItemLevel || 0}">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.onMismatch')}</label>
+            <select id="sec-on-mismatch">
+                <option value="skip"${cfg.OnItemMismatch !== 'abort' ? ' selected' : ''}>${t('opt.mismatchSkip')}</option>
+                <option value="abort"${cfg.OnItemMismatch === 'abort' ? ' selected' : ''}>${t('opt.mismatchAbort')}</option>
+            </select>
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.nonRare')}</label>
+            <select id="sec-non-rare">
+                <option value="skip"${cfg.NonRareAction !== 'upgrade' ? ' selected' : ''}>${t('opt.nonRareSkip')}</option>
+                <option value="upgrade"${cfg.NonRareAction === 'upgrade' ? ' selected' : ''}>${t('opt.nonRareUpgrade')}</option>
+            </select>
+        </div>
+        <div class="capture-group">
+            <label>${t('cfg.currencyPos')}</label>
+            ${currencyRows}
+        </div>
         <div class="section-editor-actions">
             <button class="btn btn-primary" onclick="saveSection('item')">${t('wiz.saveConfig')}</button>
             <button class="btn" onclick="cancelSection('item')">${t('btn.cancel')}</button>
@@ -1618,7 +2439,8 @@ function buildModsEditor(cfg) {
     return `
         <div class="mod-templates">
             <select id="sec-mod-template"><option value="">${t('wiz.quickTemplate')}</option></select>
-            <input type="number" id="sec-mod-value" placeholder="${t('wiz.minValue')}" min="1">
+            <select id="sec-mod-op" class="mod-op"><option value="">≥</option><option value="<=">≤</option><option value="==">=</option></select>
+            <input type="number" id="sec-mod-value" placeholder="${t('wiz.minValue')}" min="0" step="any">
             <button class="btn btn-small" onclick="secAddModFromTemplate()">${t('btn.add')}</button>
         </div>
         <div class="mod-custom">
@@ -1626,6 +2448,18 @@ function buildModsEditor(cfg) {
             <button class="btn btn-small" onclick="secAddModCustom()">${t('wiz.addCustom')}</button>
         </div>
         <div id="sec-mod-list" class="mod-list"></div>
+        <div class="form-group">
+            <label>${t('cfg.scoreFormula')}</label>
+            <input type="text" id="sec-score-formula" placeholder="${t('cfg.scoreHint')}" value="${cfg.ScoreFormula || ''}">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.scoreThreshold')}</label>
+            <input type="number" id="sec-score-threshold" step="any" value="${cfg.ScoreThreshold || 0}">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.affixConditions')}</label>
+            <input type="text" id="sec-affix-conditions" placeholder="${t('cfg.affixHint')}" value="${(cfg.AffixConditions || []).join(', ')}">
+        </div>
         <div class="section-editor-actions">
             <button class="btn btn-primary" onclick="saveSection('mods')">${t('wiz.saveConfig')}</button>
             <button class="btn" onclick="cancelSection('mods')">${t('btn.cancel')}</button>
@@ -1634,6 +2468,13 @@ function buildModsEditor(cfg) {
 
 function buildOptionsEditor(cfg) {
     const cpr = cfg.ChaosPerRound || 10;
+    const pre = cfg.Preprocess || {};
+    const masks = pre.ColorMasks || [];
+    const maskBoxes = ['white', 'magic', 'rare', 'grey', 'unique'].map(m =>
+        `<label><input type="checkbox" class="sec-color-mask" value="${m}"${masks.includes(m)?' checked':''}> <span>${m}</span></label>`
+    ).join(' ');
+    const opt = (values, current) => values.map(v =>
+        `<option value="${v}"${v === current ? ' selected' : ''}>${v}</option>`).join('');
     return `
         <div class="form-group">
             <label>${t('wiz.chaosPerRound')}</label>
@@ -1645,6 +2486,58 @@ function buildOptionsEditor(cfg) {
         <div class="form-group checkbox-group">
             <label><input type="checkbox" id="sec-snapshots"${cfg.SaveAllSnapshots?' checked':''}> <span>${t('wiz.saveSnapshots')}</span></label>
         </div>
+        <div class="form-group">
+            <label>${t('cfg.verifyTimeout')}</label>
+            <input type="number" id="sec-verify-timeout" min="200" max="10000" step="100" value="${cfg.VerifyTimeoutMs || 1500}">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.maxUnapplied')}</label>
+            <input type="number" id="sec-max-unapplied" min="1" max="50" value="${cfg.MaxUnappliedClicks || 5}">
+        </div>
+        <div class="form-group checkbox-group">
+            <label><input type="checkbox" id="sec-reclick"${cfg.ReclickOnNoChange?' checked':''}> <span>${t('cfg.reclickOnNoChange')}</span></label>
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.mode')}</label>
+            <select id="sec-mode">
+                <option value="target"${cfg.Mode !== 'maximise' ? ' selected' : ''}>${t('cfg.modeTarget')}</option>
+                <option value="maximise"${cfg.Mode === 'maximise' ? ' selected' : ''}>${t('cfg.modeMaximise')}</option>
+            </select>
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.stopChance')}</label>
+            <input type="number" id="sec-stop-chance" min="1" max="99" value="${Math.round((cfg.MaximiseStopChance || 0.2) * 100)}">
+        </div>
+        <div class="form-group checkbox-group">
+            <label>${t('cfg.colorMasks')}</label>
+            ${maskBoxes}
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.threshold')}</label>
+            <select id="sec-threshold">${opt(['otsu', 'sauvola', 'fixed', 'none'], pre.Threshold || 'otsu')}</select>
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.resample')}</label>
+            <select id="sec-resample">${opt(['catmullrom', 'bilinear', 'nearest'], pre.Resample || 'catmullrom')}</select>
+        </div>
+        <div class="form-group checkbox-group">
+            <label><input type="checkbox" id="sec-deskew"${pre.Deskew?' checked':''}> <span>${t('cfg.deskew')}</span></label>
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.attentionTimeout')} (s)</label>
+            <input type="number" id="sec-attention-timeout" min="10" value="${cfg.AttentionTimeoutSec || 300}">
+        </div>
+        <div class="form-group">
+            <label>${t('cfg.onAttentionTimeout')}</label>
+            <select id="sec-attention-answer">
+                ${['abort', 'skip', 'retry'].map(a => `<option value="${a}"${(cfg.OnAttentionTimeout || 'abort') === a ? ' selected' : ''}>${t('opt.' + a)}</option>`).join('')}
+            </select>
+        </div>
+        ${HOTKEY_ACTIONS.map(a => `
+        <div class="form-group">
+            <label>${t('hotkey.' + a)}</label>
+            <input type="text" id="sec-hotkey-${a}" value="${hotkeyFor(cfg, a)}" placeholder="F12, Ctrl+F12">
+        </div>`).join('')}
         <div class="section-editor-actions">
             <button class="btn btn-primary" onclick="saveSection('options')">${t('wiz.saveConfig')}</button>
             <button class="btn" onclick="cancelSection('options')">${t('btn.cancel')}</button>
@@ -1654,7 +2547,7 @@ function buildOptionsEditor(cfg) {
 async function sectionCapture(field) {
     captureContext = 'section';
     try {
-        const resp = await fetch('/api/wizard/capture', {
+        const resp = await fetch(engineURL('/api/wizard/capture'), {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ field })
@@ -1676,7 +2569,7 @@ async function secValidateTooltip() {
     try {
         const btn = document.getElementById('sec-btn-validate');
         if (btn) btn.disabled = true;
-        const resp = await fetch('/api/wizard/validate-tooltip', {
+        const resp = await fetch(engineURL('/api/wizard/validate-tooltip'), {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ x1: tl.X, y1: tl.Y, x2: br.X, y2: br.Y, gameLanguage: gameLang })
@@ -1703,7 +2596,7 @@ async function initSecModTemplates() {
     updateSecModList();
     if (modTemplates.length === 0) {
         try {
-            const resp = await fetch('/api/mod-templates');
+            const resp = await fetch(`/api/mod-templates?lang=${encodeURIComponent(gameLang)}`);
             modTemplates = await resp.json();
         } catch (e) { console.error('Failed to load mod templates:', e); return; }
     }
@@ -1713,7 +2606,7 @@ async function initSecModTemplates() {
     modTemplates.forEach(tmpl => {
         const opt = document.createElement('option');
         opt.value = tmpl.key;
-        opt.textContent = `${(gameLang === 'zh-CN' && tmpl.name_zh) ? tmpl.name_zh : tmpl.name} (${tmpl.example})`;
+        opt.textContent = `${tmpl.name} (${tmpl.example})`;
         select.appendChild(opt);
     });
 }
@@ -1721,11 +2614,12 @@ async function initSecModTemplates() {
 function secAddModFromTemplate() {
     const select = document.getElementById('sec-mod-template');
     const valueInput = document.getElementById('sec-mod-value');
+    const op = document.getElementById('sec-mod-op').value;
     const key = select.value;
-    const value = parseInt(valueInput.value);
+    const value = parseFloat(valueInput.value);
     if (!key) { showToast(t('toast.selectMod'), 'error'); return; }
-    if (!value || value < 1) { showToast(t('toast.enterMin'), 'error'); return; }
-    secAddMod(`${key} ${value}`);
+    if (!value || value <= 0) { showToast(t('toast.enterMin'), 'error'); return; }
+    secAddMod(`${key} ${op}${value}`);
     select.value = '';
     valueInput.value = '';
 }
@@ -1787,4 +2681,4 @@ function showToast(message, type = 'info') {
 document.getElementById('lang-select').value = currentLang;
 document.getElementById('game-lang-select').value = gameLang;
 applyTranslations();
-connectWebSocket();
+checkAuth().then(loadEngines).then(connectWebSocket);